@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	"guitar-specs/internal/smoketest"
+)
+
+func main() {
+	url := flag.String("url", "", "Base URL of a running instance (e.g. https://localhost:8443); empty runs in-process")
+	timeout := flag.Duration("timeout", 10*time.Second, "Overall timeout for all checks")
+	insecure := flag.Bool("insecure", false, "Skip TLS certificate verification (self-signed development certificates)")
+	flag.Parse()
+
+	os.Exit(run(*url, *timeout, *insecure, os.Stdout))
+}
+
+// run performs the smoke checks and returns the process exit code.
+func run(url string, timeout time.Duration, insecure bool, out io.Writer) int {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	client := &http.Client{}
+	withDB := true
+	if url == "" {
+		// In-process: the real router behind httptest, without a database
+		handler, err := smoketest.InProcessHandler(slog.New(slog.NewTextHandler(io.Discard, nil)))
+		if err != nil {
+			fmt.Fprintf(out, "FAIL in-process setup: %v\n", err)
+			return 1
+		}
+		srv := httptest.NewServer(handler)
+		defer srv.Close()
+		url = srv.URL
+		withDB = false
+	} else if insecure {
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+
+	results := smoketest.Run(ctx, client, url, smoketest.DefaultChecks, withDB)
+	for _, r := range results {
+		fmt.Fprintln(out, r)
+	}
+	if !smoketest.Passed(results) {
+		fmt.Fprintln(out, "smoke test failed")
+		return 1
+	}
+	fmt.Fprintln(out, "smoke test passed")
+	return 0
+}
@@ -15,6 +15,7 @@ import (
 	"guitar-specs/internal/assets"
 	"guitar-specs/internal/config"
 	"guitar-specs/internal/db"
+	"guitar-specs/internal/logging"
 	"guitar-specs/internal/render"
 	"guitar-specs/web"
 )
@@ -27,25 +28,19 @@ func init() {
 	_ = mime.AddExtensionType(".mjs", "text/javascript")
 }
 
-// setupLogger creates a logger with the specified level for runtime operations
-func setupLogger(level string) *slog.Logger {
-	var logLevel slog.Level
-	switch level {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "info":
-		logLevel = slog.LevelInfo
-	case "warn":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
-	default:
-		logLevel = slog.LevelInfo
+// setupLogger creates the runtime logger: text on stdout at the configured level,
+// plus JSON to LOG_FILE when set. The returned function closes the log file.
+func setupLogger(cfg *config.AppConfig, startupLogger *slog.Logger) (*slog.Logger, func() error) {
+	logger, closeLog, err := logging.New(logging.Options{
+		Level:     logging.ParseLevel(cfg.LogLevel),
+		FilePath:  cfg.LogFile,
+		FileLevel: logging.ParseLevel(cfg.LogFileLevel),
+	})
+	if err != nil {
+		// Not fatal: stdout logging still works
+		startupLogger.Warn("log file unavailable", "path", cfg.LogFile, "error", err)
 	}
-
-	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
-	}))
+	return logger, closeLog
 }
 
 func main() {
@@ -61,12 +56,20 @@ func main() {
 	cfg := configProvider.Get()
 
 	if err := configProvider.Validate(); err != nil {
-		startupLogger.Error("configuration validation failed", "error", err)
+		// Report every invalid setting, not just the first
+		fieldErrs := config.ValidationErrors(err)
+		for _, fieldErr := range fieldErrs {
+			startupLogger.Error("invalid configuration", "field", fieldErr.Field, "reason", fieldErr.Reason)
+		}
+		startupLogger.Error("configuration validation failed", "invalid_fields", len(fieldErrs))
 		os.Exit(1)
 	}
+	config.LogWarnings(startupLogger, configProvider.Warnings())
 
 	// Create runtime logger with configurable level from environment
-	runtimeLogger := setupLogger(cfg.LogLevel)
+	runtimeLogger, closeLog := setupLogger(cfg, startupLogger)
+	defer closeLog()
+	config.LogSources(runtimeLogger, configProvider.Sources())
 
 	// 2. Validate HTTPS configuration
 	if err := cfg.ValidateHTTPS(); err != nil {
@@ -85,29 +88,36 @@ func main() {
 		Password: cfg.DBPassword,
 		Database: cfg.DBName,
 		SSLMode:  cfg.DBSSLMode,
-	}
 
-	database := db.New(dbConfig)
+		ConnectTimeout:   cfg.DBConnectTimeout,
+		StatementTimeout: cfg.DBStatementTimeout,
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := database.Connect(ctx); err != nil {
-		startupLogger.Error("database connection failed", "error", err)
-		os.Exit(1)
+		MinConns: int32(max(cfg.DBMinConns, 0)),
+		WarmUp:   cfg.DBPoolWarmUp,
 	}
 
-	if err := database.Ping(ctx); err != nil {
-		startupLogger.Error("database ping failed", "error", err)
+	database := db.New(dbConfig)
+
+	// Each attempt gets 5s; retries (off by default) cover a database that starts after the app
+	if err := db.ConnectWithRetry(context.Background(), database, db.RetryConfig{
+		Retries:        cfg.DBStartupRetries,
+		Delay:          cfg.DBStartupRetryDelay,
+		AttemptTimeout: 5 * time.Second,
+	}, startupLogger); err != nil {
+		startupLogger.Error("database connection failed", "error", err, "retries", cfg.DBStartupRetries)
 		os.Exit(1)
 	}
 
 	startupLogger.Info("database connected successfully")
-	defer database.Close()
 
 	// 4. Initialize asset manager
 	startupLogger.Info("initializing asset manager")
-	assetManager, err := assets.New(web.StaticFS, runtimeLogger)
+	assetManager, err := assets.NewWithManifestPath(web.StaticFS, cfg.AssetManifestPath, runtimeLogger)
+	if err != nil && cfg.Env == "development" {
+		// No manifest without a frontend build; version assets by content hash instead
+		startupLogger.Warn("asset manifest unavailable, using content-hash asset versions", "error", err)
+		assetManager, err = assets.NewHashAssetProvider(web.StaticFS, cfg.FingerprintExtensions, runtimeLogger)
+	}
 	if err != nil {
 		startupLogger.Error("asset manager initialization failed", "error", err)
 		os.Exit(1)
@@ -125,8 +135,8 @@ func main() {
 
 	// 6. Create application with all dependencies
 	startupLogger.Info("creating application instance")
-	a := app.New(cfg, runtimeLogger, database, templateRenderer)
-	defer a.Close()
+	a := app.New(cfg, runtimeLogger, database, templateRenderer, assetManager)
+	// The pool is closed by a.Shutdown, bounded so a stuck connection cannot hang exit
 
 	startupLogger.Info("application instance created successfully")
 
@@ -170,12 +180,14 @@ func main() {
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	// Graceful shutdown with timeout
-	if err := srv.Shutdown(shutdownCtx); err != nil {
-		startupLogger.Error("server shutdown error", "error", err)
+	// Graceful shutdown with timeout: drain requests, then close the DB pool
+	report := a.Shutdown(shutdownCtx, srv)
+	if report.DrainErr != nil {
+		startupLogger.Error("server shutdown error", "error", report.DrainErr)
 	} else {
 		startupLogger.Info("server shutdown completed successfully")
 	}
+	app.LogShutdownSummary(startupLogger, report)
 
 	// Force close if shutdown timeout reached
 	select {
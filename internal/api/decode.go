@@ -0,0 +1,104 @@
+// Package api holds helpers shared by JSON endpoints: request decoding and
+// error responses in the same {"error", "status"} shape the middleware uses.
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxJSONBytes caps request bodies decoded by DecodeJSON.
+const DefaultMaxJSONBytes = 1 << 20 // 1MB
+
+// Validator is implemented by request types that check their own fields.
+// DecodeJSON calls Validate after decoding and reports its error as a 400.
+type Validator interface {
+	Validate() error
+}
+
+// DecodeJSON decodes the request body as a single JSON value of type T. The body
+// is capped at maxBytes (a non-positive value means DefaultMaxJSONBytes) and
+// unknown fields are rejected. On failure it writes a JSON error response
+// (413 for an oversized body, 400 otherwise) and returns false; the handler
+// should then return without writing anything else.
+func DecodeJSON[T any](w http.ResponseWriter, r *http.Request, maxBytes int64) (T, bool) {
+	var v T
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxJSONBytes
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBytes))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&v); err != nil {
+		status, msg := describeDecodeError(err)
+		WriteError(w, status, msg)
+		return v, false
+	}
+	// A second value (or trailing garbage) means the body was not one JSON document
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			WriteError(w, http.StatusRequestEntityTooLarge, tooLargeMessage(maxErr.Limit))
+			return v, false
+		}
+		WriteError(w, http.StatusBadRequest, "request body must contain a single JSON value")
+		return v, false
+	}
+
+	if validator, ok := any(&v).(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			WriteError(w, http.StatusBadRequest, err.Error())
+			return v, false
+		}
+	}
+	return v, true
+}
+
+// describeDecodeError maps a json.Decoder error to a status and a message that
+// tells the client which of "too large", "malformed JSON" or "unknown field" applies.
+func describeDecodeError(err error) (int, string) {
+	var maxErr *http.MaxBytesError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &maxErr):
+		return http.StatusRequestEntityTooLarge, tooLargeMessage(maxErr.Limit)
+	case errors.Is(err, io.EOF):
+		return http.StatusBadRequest, "request body must not be empty"
+	case errors.As(err, &syntaxErr):
+		return http.StatusBadRequest, fmt.Sprintf("malformed JSON at byte offset %d", syntaxErr.Offset)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return http.StatusBadRequest, "malformed JSON: unexpected end of body"
+	case errors.As(err, &typeErr):
+		if typeErr.Field != "" {
+			return http.StatusBadRequest, fmt.Sprintf("invalid value for field %q: expected %s", typeErr.Field, typeErr.Type)
+		}
+		return http.StatusBadRequest, fmt.Sprintf("invalid JSON value: expected %s", typeErr.Type)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no typed error for DisallowUnknownFields
+		return http.StatusBadRequest, "unknown field " + strings.TrimPrefix(err.Error(), "json: unknown field ")
+	default:
+		return http.StatusBadRequest, "malformed JSON"
+	}
+}
+
+func tooLargeMessage(limit int64) string {
+	return fmt.Sprintf("request body too large (max %d bytes)", limit)
+}
+
+// WriteError writes {"error": msg, "status": status} with the given status.
+func WriteError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(struct {
+		Error  string `json:"error"`
+		Status int    `json:"status"`
+	}{msg, status})
+}
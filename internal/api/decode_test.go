@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type guitarRequest struct {
+	Model string `json:"model"`
+	Frets int    `json:"frets"`
+}
+
+func (g guitarRequest) Validate() error {
+	if g.Model == "" {
+		return errors.New("model is required")
+	}
+	return nil
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		maxBytes   int64
+		wantOK     bool
+		wantStatus int
+		wantError  string
+	}{
+		{name: "valid body", body: `{"model":"Stratocaster","frets":22}`, wantOK: true},
+		{name: "too large", body: `{"model":"` + strings.Repeat("x", 100) + `"}`, maxBytes: 32, wantStatus: http.StatusRequestEntityTooLarge, wantError: "request body too large (max 32 bytes)"},
+		{name: "malformed JSON", body: `{"model":}`, wantStatus: http.StatusBadRequest, wantError: "malformed JSON at byte offset 10"},
+		{name: "truncated JSON", body: `{"model":"Strat`, wantStatus: http.StatusBadRequest, wantError: "malformed JSON: unexpected end of body"},
+		{name: "unknown field", body: `{"model":"Strat","color":"red"}`, wantStatus: http.StatusBadRequest, wantError: `unknown field "color"`},
+		{name: "wrong type", body: `{"model":"Strat","frets":"many"}`, wantStatus: http.StatusBadRequest, wantError: `invalid value for field "frets": expected int`},
+		{name: "empty body", body: ``, wantStatus: http.StatusBadRequest, wantError: "request body must not be empty"},
+		{name: "trailing data", body: `{"model":"Strat"} {"model":"Tele"}`, wantStatus: http.StatusBadRequest, wantError: "request body must contain a single JSON value"},
+		{name: "fails validation", body: `{"frets":22}`, wantStatus: http.StatusBadRequest, wantError: "model is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/guitars", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			got, ok := DecodeJSON[guitarRequest](w, req, tt.maxBytes)
+
+			if ok != tt.wantOK {
+				t.Fatalf("Expected ok=%v, got %v (body %s)", tt.wantOK, ok, w.Body.String())
+			}
+			if tt.wantOK {
+				if got.Model != "Stratocaster" || got.Frets != 22 {
+					t.Errorf("Expected decoded value, got %+v", got)
+				}
+				if w.Body.Len() != 0 {
+					t.Errorf("Expected nothing written on success, got '%s'", w.Body.String())
+				}
+				return
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+				t.Errorf("Expected JSON error, got Content-Type '%s'", ct)
+			}
+			var body struct {
+				Error  string `json:"error"`
+				Status int    `json:"status"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Expected JSON body, got '%s'", w.Body.String())
+			}
+			if body.Error != tt.wantError || body.Status != tt.wantStatus {
+				t.Errorf("Expected error '%s' (%d), got '%s' (%d)", tt.wantError, tt.wantStatus, body.Error, body.Status)
+			}
+		})
+	}
+}
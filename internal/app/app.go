@@ -1,18 +1,23 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
 	"log/slog"
 	"net/http"
+	"os"
+	"sync"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"guitar-specs/internal/assets"
 	"guitar-specs/internal/config"
 	"guitar-specs/internal/db"
 	h "guitar-specs/internal/http/handlers"
 	mw "guitar-specs/internal/http/middleware"
 	"guitar-specs/internal/models"
+	"guitar-specs/internal/permissionspolicy"
 	"guitar-specs/internal/render"
 	"guitar-specs/web"
 )
@@ -24,75 +29,243 @@ type App struct {
 	Logger *slog.Logger      // Structured logger for application events
 	Router http.Handler      // HTTP router with all middleware and routes configured
 	DB     *pgxpool.Pool     // PostgreSQL connection pool
+
+	routes        *routeTable         // Route table behind Router, for Handle and /debug/routes
+	stopPoolStats func()              // Stops the pool stats logger; nil when disabled
+	inFlight      *mw.InFlightCounter // Requests currently being served, reported on shutdown
+	closeOnce     sync.Once           // Guards Close, which Shutdown may call
 }
 
 // New creates a new application instance with pre-initialized dependencies.
 // This function allows for better dependency injection and testing.
-func New(cfg *config.AppConfig, logger *slog.Logger, database db.DatabaseProvider, renderer render.Renderer) *App {
+func New(cfg *config.AppConfig, logger *slog.Logger, database db.DatabaseProvider, renderer render.Renderer, assetProvider assets.AssetProvider) *App {
 	// Initialize standard Go 1.22 router with pattern matching
 	mux := http.NewServeMux()
+	rt := newRouteTable(mux)
 
 	// Prepare static file system for serving
 	sub, _ := fs.Sub(web.StaticFS, "static")
 
 	// Create model store and page handlers
-	store := models.NewStore(database.GetPool())
+	store := models.NewStore(database.GetPool(), models.QueryTimeouts{
+		List:     cfg.DBListTimeout,
+		Get:      cfg.DBGetTimeout,
+		Features: cfg.DBFeaturesTimeout,
+	})
+	if cfg.StaleOnError {
+		store.EnableStaleFallback(logger)
+	}
 	pages := h.New(renderer, web.RobotsFS, store)
 
-	// Static file serving with aggressive caching
-	// These files are served with long-lived cache headers
-	staticHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	// Optionally run common queries once so the first visitor hits a warm path
+	if cfg.Warmup {
+		warmUp(context.Background(), logger, store.Guitars, cfg.WarmupTimeout)
+	}
+	if cfg.TemplateWarmup {
+		render.WarmUp(renderer, logger)
+	}
+
+	// Static file roots, each mounted at its own prefix with its own cache policy.
+	// Embedded app assets are served with long-lived cache headers; .br/.gz siblings
+	// produced by the build are preferred when the client accepts them.
+	// Browsers navigating to a missing extensionless path get the HTML 404 page
+	staticMounts := []staticMount{{
+		prefix: "/static/",
+		fsys:   sub,
 		// Long-lived, immutable cache is safe because URLs change when content changes
-		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
-		http.FileServer(http.FS(sub)).ServeHTTP(w, r)
-	})
+		contentAddressed: true,
+		precompressed:    true,
+	}}
+	if cfg.UploadsDir != "" {
+		// Uploads live on disk and can be replaced in place, so they are cached
+		// briefly and revalidated once stale
+		staticMounts = append(staticMounts, staticMount{
+			prefix: "/uploads/",
+			fsys:   os.DirFS(cfg.UploadsDir),
+		})
+	}
 
-	// Create page handlers (no compression)
-	homeHandler := http.HandlerFunc(pages.Home)
-	aboutHandler := http.HandlerFunc(pages.About)
-	contactHandler := http.HandlerFunc(pages.Contact)
+	// Page handlers are compressed (br or gzip) only when enabled;
+	// by default compression is left to the CDN in front of the app
+	var compress func(http.Handler) http.Handler
+	if cfg.Compress {
+		compress = mw.Compress(logger, cfg.CompressLevel)
+	}
+	// CDNs may serve slightly stale pages while refreshing or when the app errors
+	htmlCacheControl := mw.WithStaleDirectives(cfg.HTMLCacheControl, cfg.HTMLStaleWhileRevalidate, cfg.HTMLStaleIfError)
+	// Rendered pages share one route middleware chain, outermost first
+	var pageMiddleware []func(http.Handler) http.Handler
+	// Pages embed a per-request CSP nonce, which the micro-cache can only swap
+	// in uncompressed bytes, so compression wraps the cache
+	if compress != nil {
+		pageMiddleware = append(pageMiddleware, compress)
+	}
+	if cfg.MicroCacheTTL > 0 {
+		pageMiddleware = append(pageMiddleware, mw.MicroCache(cfg.MicroCacheTTL))
+	}
+	// ETag hashes the uncompressed body, so it sits inside Compress
+	if cfg.ETags {
+		pageMiddleware = append(pageMiddleware, mw.ETag)
+	}
+	// One cache policy for every rendered page, unless the handler sets its own
+	if htmlCacheControl != "" {
+		pageMiddleware = append(pageMiddleware, mw.DefaultCacheControl(htmlCacheControl))
+	}
+	page := func(pattern string, fn http.HandlerFunc) {
+		rt.handle(pattern, fn, pageMiddleware...)
+	}
 
 	// Register routes with Go 1.22+ pattern matching
 	// This provides automatic 405 Method Not Allowed and Allow headers
 	// Order matters: more specific patterns first, then general ones
-	mux.Handle("/static/", http.StripPrefix("/static/", staticHandler))
-	mux.Handle("GET /about", aboutHandler)
-	mux.Handle("GET /contact", contactHandler)
-	mux.Handle("GET /robots.txt", http.HandlerFunc(pages.RobotsTxt))
-	mux.Handle("GET /guitars", http.HandlerFunc(pages.Guitars))
-	mux.Handle("GET /guitar/", http.HandlerFunc(pages.GuitarDetail))
-	mux.Handle("GET /healthz", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	// Static responses let the configured origins read their Resource Timing details
+	registerStaticMounts(rt, staticMounts, http.HandlerFunc(pages.NotFound), mw.TimingAllowOrigin(cfg.TimingAllowOrigins))
+	page("GET /about", pages.About)
+	page("GET /contact", pages.Contact)
+	rt.handle("GET /robots.txt", http.HandlerFunc(pages.RobotsTxt))
+	rt.handle("GET /favicon.ico", h.RootAsset(assetProvider, "/static/favicon.ico", http.StatusNoContent))
+	rt.handle("GET /site.webmanifest", h.RootAsset(assetProvider, "/static/site.webmanifest", http.StatusNotFound))
+	rt.handle("GET /assets/manifest", h.AssetManifest(assetProvider))
+	if len(cfg.SecurityTxtContact) > 0 {
+		rt.handle("GET /.well-known/security.txt", h.SecurityTxt(cfg.SecurityTxtContact, cfg.SecurityTxtExpires, cfg.SecurityTxtPolicy))
+	}
+	// Recent 5xx responses are only kept while the debug endpoints are routed
+	var errorLog *mw.ErrorLog
+	if cfg.EnableDebug {
+		errorLog = mw.NewErrorLog(mw.DefaultErrorLogSize)
+		rt.handle("GET /debug/asset", h.DebugAsset(assetProvider), mw.NoStore)
+		rt.handle("GET /debug/errors", h.DebugErrors(errorLog), mw.NoStore)
+		rt.handle("GET /debug/routes", h.DebugRoutes(rt), mw.NoStore)
+	}
+	page("GET /guitars", pages.Guitars)
+	// Streamed, so kept out of page(): caching, compression and ETags would buffer it
+	rt.handle("GET /api/guitars/export", h.GuitarsExport(store.Guitars))
+	// More specific than "GET /guitar/", so it wins over a slug named "random"
+	rt.handle("GET /guitar/random", http.HandlerFunc(pages.RandomGuitar))
+	page("GET /guitar/", pages.GuitarDetail)
+	page("GET /brand/{slug}", pages.BrandDetail)
+	page("GET /shape/{slug}", pages.ShapeDetail)
+	page("GET /features", pages.Features)
+	rt.handle("GET /healthz", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
 	}))
 	// Root path without pattern matching to avoid conflicts with /static/
-	mux.Handle("/", homeHandler)
-
-	// Apply middleware stack to all routes
-	// Order is critical: RequestID → RealIP → Recoverer → Logging → Timeout → Security
-	handler := mw.RequestID(
-		mw.RealIP(cfg.TrustedProxies)(
-			mw.Recoverer(logger)(
-				mw.SlogLogger(logger)(
-					mw.TimeoutWithCause(mw.DefaultTimeout, fmt.Errorf("request timeout after %v", mw.DefaultTimeout))(
-						mw.SecurityHeaders(mux),
-					),
-				),
-			),
-		),
+	page("/", pages.Home)
+
+	// Optionally allow static inline template scripts by hash rather than relying on the nonce
+	scriptSrc := cfg.CSPScriptSrc
+	if cfg.CSPInlineScriptHashes {
+		hashes, err := render.InlineScriptHashes(web.TemplatesFS)
+		if err != nil {
+			logger.Warn("failed to hash inline scripts", "error", err)
+		} else {
+			scriptSrc = append(append([]string{}, cfg.CSPScriptSrc...), hashes...)
+			logger.Info("inline script hashes added to CSP", "count", len(hashes))
+		}
+	}
+
+	// Security headers with configurable CSP allowlists for third-party sources.
+	// config.Validate rejects a bad policy at startup; should one get here anyway,
+	// the default is sent rather than a header browsers would ignore.
+	permissionsPolicy, err := permissionspolicy.Header(cfg.PermissionsPolicy)
+	if err != nil {
+		logger.Error("invalid PERMISSIONS_POLICY, using the default", "error", err)
+	}
+	security := mw.SecurityHeadersWithConfig(mw.SecurityConfig{
+		ScriptSrc:         scriptSrc,
+		StyleSrc:          cfg.CSPStyleSrc,
+		ImgSrc:            cfg.CSPImgSrc,
+		FontSrc:           cfg.CSPFontSrc,
+		ConnectSrc:        cfg.CSPConnectSrc,
+		PermissionsPolicy: permissionsPolicy,
+	})
+
+	// OPTIONS is answered from the registered routes, with an accurate Allow header
+	routes := mw.AutoOptions(mux)
+
+	// Client IPs come only from trusted proxies, with oversized forwarding chains ignored
+	realIP := mw.RealIPWithConfig(mw.RealIPConfig{
+		TrustedProxies:      cfg.TrustedProxies,
+		Headers:             cfg.RealIPHeaders,
+		MaxForwardedEntries: cfg.RealIPMaxEntries,
+	})
+
+	// Count every request, including rejected ones, for the shutdown summary
+	inFlight := &mw.InFlightCounter{}
+
+	// Apply middleware stack to all routes, outermost first. The request ID and
+	// client IP are set before anything logs or limits per IP, ErrorLog sits
+	// outside Recoverer to record its 500s, and requests are rejected by the
+	// limits before the timeout starts or Security issues a CSP nonce.
+	chain := []func(http.Handler) http.Handler{
+		inFlight.Middleware,
+		// Keep every non-production deployment out of search results, rejections included
+		mw.NoIndex(cfg.Env != "production"),
+		// HEAD runs the GET path for identical headers; the body is counted and dropped
+		mw.HeadNoBody,
+		mw.RequestID,
+		mw.StripHopByHop,
+		realIP,
+		errorLog.Middleware,
+		mw.Recoverer(logger),
+		mw.SlogLoggerWithOptions(logger, mw.LoggerOptions{ExcludePaths: cfg.LogExcludePaths}),
+		mw.CanonicalHost(cfg.CanonicalHost),
+		mw.RequestLimits(cfg.MaxHeaders, cfg.MaxURLLength),
+		mw.PerIPConcurrencyLimit(cfg.MaxConcurrentPerIP),
+		mw.ConcurrencyLimit(cfg.MaxConcurrentRequests, cfg.ConcurrencyQueueTimeout),
+		mw.TimeoutWithCause(logger, mw.DefaultTimeout, fmt.Errorf("request timeout after %v", mw.DefaultTimeout)),
+		security,
+		// Sampled allocation profiling is opt-in; ReadMemStats is too costly to run per request
+		mw.ProfileRequests(logger, cfg.ProfileRequests, mw.ProfileConfig{
+			SampleRate:    cfg.ProfileSampleRate,
+			MinAllocBytes: uint64(max(cfg.ProfileMinAllocBytes, 0)),
+			MinDuration:   cfg.ProfileMinDuration,
+		}),
+	}
+	// Phase timings are opt-in since they reveal server internals
+	if cfg.ServerTiming {
+		chain = append(chain, mw.ServerTimingHeader)
+	}
+	chain = append(chain,
+		// Expose the public base URL to handlers and templates for absolute links
+		mw.PublicBaseURL(cfg.PublicBaseURL),
+		// Read-only mode guarantees no writes reach the database during maintenance
+		mw.ReadOnly(cfg.ReadOnly),
+		// Handlers (and body logging) read plaintext even when clients compress bodies
+		mw.DecompressRequest(mw.DefaultMaxDecompressedBytes),
 	)
+	// Body logging is opt-in per path; without an allowlist the router is used as-is
+	if len(cfg.DebugBodyPaths) > 0 {
+		chain = append(chain, mw.DebugBodyLogger(logger, cfg.DebugBodyPaths, cfg.DebugBodyMaxBytes))
+	}
+
+	a := &App{
+		Config:   cfg,
+		Logger:   logger,
+		Router:   rt.chain(routes, chain...),
+		DB:       database.GetPool(),
+		routes:   rt,
+		inFlight: inFlight,
+	}
 
-	return &App{
-		Config: cfg,
-		Logger: logger,
-		Router: handler,
-		DB:     database.GetPool(),
+	// Periodic pool stats are opt-in; they are only emitted at debug level
+	if cfg.PoolStatsInterval > 0 && a.DB != nil {
+		a.stopPoolStats = startPoolStatsLogger(logger, a.DB, cfg.PoolStatsInterval)
 	}
+
+	return a
 }
 
-// Close releases application resources.
+// Close releases application resources. Only the first call does any work;
+// concurrent callers wait for it to finish.
 func (a *App) Close() {
-	if a.DB != nil {
-		a.DB.Close()
-	}
+	a.closeOnce.Do(func() {
+		if a.stopPoolStats != nil {
+			a.stopPoolStats()
+		}
+		if a.DB != nil {
+			a.DB.Close()
+		}
+	})
 }
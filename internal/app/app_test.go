@@ -0,0 +1,464 @@
+package app
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"guitar-specs/internal/assets"
+	"guitar-specs/internal/config"
+	"guitar-specs/internal/db"
+	mw "guitar-specs/internal/http/middleware"
+	"guitar-specs/internal/render"
+	"guitar-specs/web"
+)
+
+// mockDatabase implements db.DatabaseProvider without a PostgreSQL server.
+// GetPool returns nil, so store queries fail fast with a "nil DB" error.
+type mockDatabase struct {
+	getPoolCalls int
+}
+
+func (m *mockDatabase) Connect(ctx context.Context) error { return nil }
+func (m *mockDatabase) Close()                            {}
+func (m *mockDatabase) Ping(ctx context.Context) error    { return nil }
+func (m *mockDatabase) IsConnected() bool                 { return true }
+
+func (m *mockDatabase) GetPool() *pgxpool.Pool {
+	m.getPoolCalls++
+	return nil
+}
+
+func (m *mockDatabase) GetConnectionInfo() db.ConnectionInfo {
+	return db.ConnectionInfo{Connected: true}
+}
+
+var _ db.DatabaseProvider = (*mockDatabase)(nil)
+
+// newTestApp builds the app with a mock database and the real templates.
+func newTestApp(t *testing.T, cfg *config.AppConfig, database db.DatabaseProvider) *App {
+	t.Helper()
+
+	assetProvider, err := assets.NewHashAssetProvider(web.StaticFS, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create asset provider: %v", err)
+	}
+	renderer, err := render.New(web.TemplatesFS, assetProvider, "test", nil)
+	if err != nil {
+		t.Fatalf("Failed to create renderer: %v", err)
+	}
+
+	return New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), database, renderer, assetProvider)
+}
+
+func TestNew_WithMockDatabase(t *testing.T) {
+	database := &mockDatabase{}
+	a := newTestApp(t, &config.AppConfig{}, database)
+
+	if database.getPoolCalls == 0 {
+		t.Error("Expected the app to take its pool from the injected provider")
+	}
+	if a.DB != nil {
+		t.Errorf("Expected the provider's pool to be used, got %v", a.DB)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "health check", method: "GET", target: "/healthz", wantStatus: http.StatusOK, wantBody: "ok"},
+		{name: "static page", method: "GET", target: "/about", wantStatus: http.StatusOK, wantBody: "About"},
+		{name: "robots.txt", method: "GET", target: "/robots.txt", wantStatus: http.StatusOK},
+		{name: "static asset", method: "GET", target: "/static/css/main.css", wantStatus: http.StatusOK},
+		{name: "asset manifest", method: "GET", target: "/assets/manifest", wantStatus: http.StatusOK, wantBody: "/static/css/main.css"},
+		{name: "database failure", method: "GET", target: "/guitars", wantStatus: http.StatusInternalServerError},
+		{name: "brand database failure", method: "GET", target: "/brand/fender", wantStatus: http.StatusInternalServerError},
+		{name: "shape database failure", method: "GET", target: "/shape/stratocaster", wantStatus: http.StatusInternalServerError},
+		{name: "features database failure", method: "GET", target: "/features", wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			w := httptest.NewRecorder()
+			a.Router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("Expected body to contain '%s', got '%s'", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestNew_UsesInjectedDependencies(t *testing.T) {
+	// Stand-ins that can only come from the injected renderer and asset provider
+	assetProvider, err := assets.NewHashAssetProvider(fstest.MapFS{
+		"static/favicon.ico": &fstest.MapFile{Data: []byte("icon")},
+	}, []string{".ico"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create asset provider: %v", err)
+	}
+	renderer, err := render.New(fstest.MapFS{
+		"templates/pages/about.tmpl.html": &fstest.MapFile{Data: []byte(`injected {{.Page.Title}}`)},
+	}, assetProvider, "test", nil)
+	if err != nil {
+		t.Fatalf("Failed to create renderer: %v", err)
+	}
+
+	a := New(&config.AppConfig{}, slog.New(slog.NewTextHandler(io.Discard, nil)), &mockDatabase{}, renderer, assetProvider)
+
+	t.Run("pages render through the injected renderer", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		a.Router.ServeHTTP(w, httptest.NewRequest("GET", "/about", nil))
+
+		if w.Code != http.StatusOK || w.Body.String() != "injected About Us" {
+			t.Errorf("Expected injected template output, got %d '%s'", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("root assets resolve through the injected provider", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		a.Router.ServeHTTP(w, httptest.NewRequest("GET", "/favicon.ico", nil))
+
+		if w.Code != http.StatusFound {
+			t.Fatalf("Expected status 302, got %d", w.Code)
+		}
+		if loc := w.Header().Get("Location"); loc != assetProvider.AssetURL("/static/favicon.ico") {
+			t.Errorf("Expected redirect to injected asset URL, got '%s'", loc)
+		}
+	})
+}
+
+func TestNew_StaticNotFound(t *testing.T) {
+	a := newTestApp(t, &config.AppConfig{}, &mockDatabase{})
+
+	req := httptest.NewRequest("GET", "/static/page", nil)
+	req.Header.Set("Accept", "text/html")
+	w := httptest.NewRecorder()
+	a.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("Expected HTML 404 page, got Content-Type '%s'", ct)
+	}
+	if !strings.Contains(w.Body.String(), "Page not found") {
+		t.Errorf("Expected rendered 404 page, got '%s'", w.Body.String())
+	}
+	if cc := w.Header().Get("Cache-Control"); strings.Contains(cc, "immutable") {
+		t.Errorf("Expected no asset caching on the 404 page, got '%s'", cc)
+	}
+}
+
+func TestNew_Options(t *testing.T) {
+	a := newTestApp(t, &config.AppConfig{}, &mockDatabase{})
+
+	req := httptest.NewRequest("OPTIONS", "/guitars", nil)
+	w := httptest.NewRecorder()
+	a.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	if allow := w.Header().Get("Allow"); allow != "GET, HEAD, OPTIONS" {
+		t.Errorf("Expected Allow 'GET, HEAD, OPTIONS', got '%s'", allow)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("Expected an empty body, got '%s'", w.Body.String())
+	}
+}
+
+func TestNew_UploadsMount(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "photo.txt"), []byte("upload"), 0o644); err != nil {
+		t.Fatalf("Failed to write upload: %v", err)
+	}
+	a := newTestApp(t, &config.AppConfig{UploadsDir: dir}, &mockDatabase{})
+
+	tests := []struct {
+		target    string
+		wantCache string
+	}{
+		// Uploads can be replaced in place, so they are never immutable
+		{target: "/uploads/photo.txt", wantCache: "public, max-age=300, must-revalidate"},
+		{target: "/static/css/main.css", wantCache: "public, max-age=31536000, immutable"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		a.Router.ServeHTTP(w, httptest.NewRequest("GET", tt.target, nil))
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200 for %s, got %d", tt.target, w.Code)
+		}
+		if cc := w.Header().Get("Cache-Control"); cc != tt.wantCache {
+			t.Errorf("Expected Cache-Control '%s' for %s, got '%s'", tt.wantCache, tt.target, cc)
+		}
+	}
+}
+
+func TestNew_GuitarsExport(t *testing.T) {
+	// The mock database has no pool, so the export fails before streaming
+	a := newTestApp(t, &config.AppConfig{}, &mockDatabase{})
+
+	w := httptest.NewRecorder()
+	a.Router.ServeHTTP(w, httptest.NewRequest("GET", "/api/guitars/export", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500 without a database, got %d", w.Code)
+	}
+}
+
+func TestNew_HTMLCacheControl(t *testing.T) {
+	a := newTestApp(t, &config.AppConfig{
+		HTMLCacheControl:         "public, max-age=60",
+		HTMLStaleWhileRevalidate: 300 * time.Second,
+	}, &mockDatabase{})
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{target: "/about", want: "public, max-age=60, stale-while-revalidate=300"},
+		// The mock database fails the catalogue, and errors are never given a policy
+		{target: "/guitars", want: ""},
+		{target: "/static/css/main.css", want: "public, max-age=31536000, immutable"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		a.Router.ServeHTTP(w, httptest.NewRequest("GET", tt.target, nil))
+
+		if got := w.Header().Get("Cache-Control"); got != tt.want {
+			t.Errorf("Expected Cache-Control '%s' for %s, got '%s'", tt.want, tt.target, got)
+		}
+	}
+}
+
+func TestNew_PermissionsPolicy(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: mw.DefaultPermissionsPolicy},
+		{value: "geolocation=;camera=self", want: "camera=(self), geolocation=()"},
+		// config.Validate rejects this; New falls back instead of failing
+		{value: "Camera=", want: mw.DefaultPermissionsPolicy},
+	}
+
+	for _, tt := range tests {
+		a := newTestApp(t, &config.AppConfig{PermissionsPolicy: tt.value}, &mockDatabase{})
+
+		w := httptest.NewRecorder()
+		a.Router.ServeHTTP(w, httptest.NewRequest("GET", "/about", nil))
+
+		if got := w.Header().Get("Permissions-Policy"); got != tt.want {
+			t.Errorf("Expected Permissions-Policy '%s' for %q, got '%s'", tt.want, tt.value, got)
+		}
+	}
+}
+
+func TestNew_MicroCacheNonce(t *testing.T) {
+	a := newTestApp(t, &config.AppConfig{MicroCacheTTL: time.Minute, Compress: true, CompressLevel: 5}, &mockDatabase{})
+
+	seen := map[string]bool{}
+	for i, want := range []string{"MISS", "HIT"} {
+		req := httptest.NewRequest("GET", "/about", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		a.Router.ServeHTTP(w, req)
+
+		if got := w.Header().Get("X-Cache"); got != want {
+			t.Errorf("Expected X-Cache %s on request %d, got '%s'", want, i+1, got)
+		}
+		zr, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatalf("Expected a gzip body, got %v", err)
+		}
+		body, _ := io.ReadAll(zr)
+
+		_, nonce, _ := strings.Cut(w.Header().Get("Content-Security-Policy"), "'nonce-")
+		nonce, _, _ = strings.Cut(nonce, "'")
+		if nonce == "" || !strings.Contains(string(body), `nonce="`+nonce+`"`) {
+			t.Errorf("Expected the page to carry its CSP nonce '%s'", nonce)
+		}
+		seen[nonce] = true
+	}
+	if len(seen) != 2 {
+		t.Errorf("Expected a different nonce per response, got %v", seen)
+	}
+}
+
+func TestNew_ETagRevalidatesPages(t *testing.T) {
+	a := newTestApp(t, &config.AppConfig{ETags: true, Compress: true, CompressLevel: 5}, &mockDatabase{})
+
+	serve := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/about", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		a.Router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := serve("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("Expected a tagged 200, got %d with ETag '%s'", first.Code, etag)
+	}
+
+	w := serve(etag)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("Expected status 304 for a rendered page, got %d", w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("Expected empty body, got %d bytes", w.Body.Len())
+	}
+}
+
+func TestNew_TimingAllowOrigin(t *testing.T) {
+	a := newTestApp(t, &config.AppConfig{TimingAllowOrigins: []string{"https://guitar-specs.example.com"}}, &mockDatabase{})
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{target: "/static/css/main.css", want: "https://guitar-specs.example.com"},
+		{target: "/about", want: ""},
+		{target: "/healthz", want: ""},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		a.Router.ServeHTTP(w, httptest.NewRequest("GET", tt.target, nil))
+
+		if got := w.Header().Get("Timing-Allow-Origin"); got != tt.want {
+			t.Errorf("Expected Timing-Allow-Origin '%s' for %s, got '%s'", tt.want, tt.target, got)
+		}
+	}
+}
+
+func TestNew_NoIndex(t *testing.T) {
+	tests := []struct {
+		env  string
+		want string
+	}{
+		{env: "development", want: "noindex, nofollow"},
+		{env: "staging", want: "noindex, nofollow"},
+		{env: "production", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			a := newTestApp(t, &config.AppConfig{Env: tt.env}, &mockDatabase{})
+
+			w := httptest.NewRecorder()
+			a.Router.ServeHTTP(w, httptest.NewRequest("GET", "/about", nil))
+
+			if got := w.Header().Get("X-Robots-Tag"); got != tt.want {
+				t.Errorf("Expected X-Robots-Tag '%s', got '%s'", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNew_DebugErrors(t *testing.T) {
+	t.Run("not routed without ENABLE_DEBUG", func(t *testing.T) {
+		a := newTestApp(t, &config.AppConfig{}, &mockDatabase{})
+
+		w := httptest.NewRecorder()
+		a.Router.ServeHTTP(w, httptest.NewRequest("GET", "/debug/errors", nil))
+		if strings.Contains(w.Header().Get("Content-Type"), "application/json") {
+			t.Errorf("Expected no debug endpoint, got %d '%s'", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("lists server errors when enabled", func(t *testing.T) {
+		a := newTestApp(t, &config.AppConfig{EnableDebug: true}, &mockDatabase{})
+
+		// The mock database makes the guitar list fail with a 500
+		a.Router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/guitars", nil))
+
+		w := httptest.NewRecorder()
+		a.Router.ServeHTTP(w, httptest.NewRequest("GET", "/debug/errors", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if !strings.Contains(w.Body.String(), `"path":"/guitars"`) || !strings.Contains(w.Body.String(), `"status":500`) {
+			t.Errorf("Expected the failed request to be listed, got '%s'", w.Body.String())
+		}
+	})
+}
+
+func TestNew_HeadMatchesGet(t *testing.T) {
+	a := newTestApp(t, &config.AppConfig{ETags: true, Compress: true, CompressLevel: 5}, &mockDatabase{})
+
+	tests := []struct {
+		target string
+		// Pages embed a fresh CSP nonce on every request, so their compressed
+		// size differs between any two responses
+		perRequest bool
+	}{
+		{target: "/about", perRequest: true},
+		{target: "/static/css/main.css"},
+	}
+
+	for _, tt := range tests {
+		target := tt.target
+		t.Run(target, func(t *testing.T) {
+			serve := func(method string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(method, target, nil)
+				req.Header.Set("Accept-Encoding", "gzip")
+				w := httptest.NewRecorder()
+				a.Router.ServeHTTP(w, req)
+				return w
+			}
+			get, head := serve("GET"), serve("HEAD")
+
+			if head.Code != get.Code {
+				t.Errorf("Expected HEAD status %d, got %d", get.Code, head.Code)
+			}
+			for _, name := range []string{"Content-Type", "Content-Encoding", "Vary"} {
+				if got, want := head.Header().Get(name), get.Header().Get(name); got != want {
+					t.Errorf("Expected HEAD %s '%s', got '%s'", name, want, got)
+				}
+			}
+			if got, want := head.Header().Get("ETag"), get.Header().Get("ETag"); got == "" || got != want {
+				t.Errorf("Expected HEAD ETag '%s', got '%s'", want, got)
+			}
+			if tt.perRequest {
+				if head.Header().Get("Content-Length") == "" {
+					t.Error("Expected HEAD Content-Length")
+				}
+			} else {
+				if cl := head.Header().Get("Content-Length"); cl != strconv.Itoa(get.Body.Len()) {
+					t.Errorf("Expected HEAD Content-Length %d, got '%s'", get.Body.Len(), cl)
+				}
+			}
+			if head.Body.Len() != 0 {
+				t.Errorf("Expected an empty HEAD body, got %d bytes", head.Body.Len())
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// poolStater is the subset of the connection pool read by the stats logger.
+type poolStater interface {
+	Stat() *pgxpool.Stat
+}
+
+// startPoolStatsLogger logs pool statistics at debug level every interval so
+// acquire waits and connection churn are visible. The returned function stops
+// the goroutine and waits for it to exit; it is safe to call more than once,
+// including concurrently.
+func startPoolStatsLogger(logger *slog.Logger, pool poolStater, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	exited := make(chan struct{})
+
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				logPoolStats(logger, pool.Stat())
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-exited
+		})
+	}
+}
+
+// logPoolStats writes a single snapshot of the pool counters.
+func logPoolStats(logger *slog.Logger, s *pgxpool.Stat) {
+	logger.Debug("db pool stats",
+		"total_conns", s.TotalConns(),
+		"idle_conns", s.IdleConns(),
+		"acquired_conns", s.AcquiredConns(),
+		"constructing_conns", s.ConstructingConns(),
+		"max_conns", s.MaxConns(),
+		"acquire_count", s.AcquireCount(),
+		"acquire_duration_ms", s.AcquireDuration().Milliseconds(),
+		"empty_acquire_count", s.EmptyAcquireCount(),
+		"canceled_acquire_count", s.CanceledAcquireCount(),
+		"new_conns_count", s.NewConnsCount(),
+		"max_lifetime_destroy_count", s.MaxLifetimeDestroyCount(),
+		"max_idle_destroy_count", s.MaxIdleDestroyCount(),
+	)
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// syncBuffer guards a bytes.Buffer written by the logger goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestPoolStatsLogger(t *testing.T) {
+	// pgxpool connects lazily, so an unreachable DSN still yields usable stats
+	pool, err := pgxpool.New(context.Background(), "postgres://user@127.0.0.1:1/none")
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	var logOutput syncBuffer
+	logger := slog.New(slog.NewTextHandler(&logOutput, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	stop := startPoolStatsLogger(logger, pool, 5*time.Millisecond)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(logOutput.String(), "db pool stats") {
+		if time.Now().After(deadline) {
+			stop()
+			t.Fatal("Expected pool stats to be logged at least once")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Repeated and concurrent calls must neither panic nor race
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stop()
+		}()
+	}
+	wg.Wait()
+	stop()
+
+	if !strings.Contains(logOutput.String(), "total_conns=0") || !strings.Contains(logOutput.String(), "acquire_duration_ms=") {
+		t.Errorf("Expected pool counters in log, got: %s", logOutput.String())
+	}
+
+	// Nothing further is logged once stopped
+	after := logOutput.String()
+	time.Sleep(30 * time.Millisecond)
+	if logOutput.String() != after {
+		t.Error("Expected no logging after stop")
+	}
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+
+	h "guitar-specs/internal/http/handlers"
+)
+
+// Handle registers handler for pattern on the app's router, wrapped in
+// route-specific middleware such as a rate limiter for one expensive endpoint
+// or basic auth for /admin. The first middleware listed is the outermost. Route
+// middleware runs inside the global chain, so the route still gets request IDs,
+// logging, timeouts and security headers. Patterns follow http.ServeMux, and
+// registering a conflicting pattern panics as it does there.
+func (a *App) Handle(pattern string, handler http.Handler, mws ...func(http.Handler) http.Handler) {
+	a.routes.handle(pattern, handler, mws...)
+}
+
+// withMiddleware wraps handler in mws, the first listed being the outermost.
+func withMiddleware(handler http.Handler, mws ...func(http.Handler) http.Handler) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		handler = mws[i](handler)
+	}
+	return handler
+}
+
+// routeTable registers routes on a mux and remembers them, with the names of
+// their middleware, for /debug/routes. It implements handlers.RouteLister.
+type routeTable struct {
+	mux *http.ServeMux
+
+	mu         sync.RWMutex
+	routes     []h.RouteInfo
+	middleware []string // Global chain, outermost first
+}
+
+// newRouteTable returns an empty route table registering on mux.
+func newRouteTable(mux *http.ServeMux) *routeTable {
+	return &routeTable{mux: mux}
+}
+
+// handle registers handler for pattern wrapped in mws, the first listed being
+// the outermost, and records the route.
+func (t *routeTable) handle(pattern string, handler http.Handler, mws ...func(http.Handler) http.Handler) {
+	t.mux.Handle(pattern, withMiddleware(handler, mws...))
+
+	method, path, found := strings.Cut(pattern, " ")
+	if !found {
+		method, path = "", pattern
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.routes = append(t.routes, h.RouteInfo{
+		Pattern:    pattern,
+		Method:     method,
+		Path:       strings.TrimSpace(path),
+		Middleware: middlewareNames(mws),
+	})
+}
+
+// chain wraps handler in the global middleware mws, the first listed being the
+// outermost, and records the names of those that are enabled.
+func (t *routeTable) chain(handler http.Handler, mws ...func(http.Handler) http.Handler) http.Handler {
+	t.mu.Lock()
+	t.middleware = middlewareNames(mws)
+	t.mu.Unlock()
+	return withMiddleware(handler, mws...)
+}
+
+// Routes returns the registered routes in registration order.
+func (t *routeTable) Routes() []h.RouteInfo {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return append([]h.RouteInfo{}, t.routes...)
+}
+
+// Middleware returns the names of the global middleware chain, outermost first.
+func (t *routeTable) Middleware() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return append([]string{}, t.middleware...)
+}
+
+// middlewareNames names each middleware by the function that built it.
+// Middleware disabled by its configuration, which returns the next handler
+// unwrapped, is left out.
+func middlewareNames(mws []func(http.Handler) http.Handler) []string {
+	names := make([]string, 0, len(mws))
+	for _, fn := range mws {
+		probe := &probeHandler{}
+		if fn(probe) == http.Handler(probe) {
+			continue
+		}
+		names = append(names, middlewareName(fn))
+	}
+	return names
+}
+
+// probeHandler is wrapped by middleware to find out whether it is disabled.
+type probeHandler struct{}
+
+func (*probeHandler) ServeHTTP(http.ResponseWriter, *http.Request) {}
+
+// middlewareName names fn after the function that built it, e.g.
+// "middleware.Compress" for the closure mw.Compress returns, or
+// "middleware.ErrorLog.Middleware" for a method value.
+func middlewareName(fn func(http.Handler) http.Handler) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return "unknown"
+	}
+	name := f.Name()
+	// Drop the import path, keeping the package name
+	name = name[strings.LastIndex(name, "/")+1:]
+	name = strings.TrimSuffix(name, "-fm")
+	name = strings.NewReplacer("(*", "", ")", "").Replace(name)
+	// Closures are named after their enclosing function: "Compress.func1", "func1.2"
+	parts := strings.Split(name, ".")
+	for len(parts) > 2 && isClosureSuffix(parts[len(parts)-1]) {
+		parts = parts[:len(parts)-1]
+	}
+	return strings.Join(parts, ".")
+}
+
+// isClosureSuffix reports whether s is a compiler-generated closure name part.
+func isClosureSuffix(s string) bool {
+	s = strings.TrimPrefix(s, "func")
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"guitar-specs/internal/config"
+	h "guitar-specs/internal/http/handlers"
+	mw "guitar-specs/internal/http/middleware"
+)
+
+func TestApp_Handle(t *testing.T) {
+	a := newTestApp(t, &config.AppConfig{}, &mockDatabase{})
+
+	// tag records its name in X-Route-Middleware, so the order is visible
+	tag := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Route-Middleware", name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	a.Handle("GET /api/ping", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("pong"))
+	}), tag("outer"), tag("inner"))
+
+	t.Run("runs route middleware in order", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		a.Router.ServeHTTP(w, httptest.NewRequest("GET", "/api/ping", nil))
+
+		if w.Code != http.StatusOK || w.Body.String() != "pong" {
+			t.Errorf("Expected 200 'pong', got %d '%s'", w.Code, w.Body.String())
+		}
+		if got := strings.Join(w.Header().Values("X-Route-Middleware"), ","); got != "outer,inner" {
+			t.Errorf("Expected route middleware 'outer,inner', got '%s'", got)
+		}
+		// The global chain still applies
+		if w.Header().Get("X-Request-ID") == "" || w.Header().Get("Content-Security-Policy") == "" {
+			t.Error("Expected global middleware headers on the route")
+		}
+	})
+
+	t.Run("skips other routes", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		a.Router.ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+
+		if got := w.Header().Get("X-Route-Middleware"); got != "" {
+			t.Errorf("Expected no route middleware on /healthz, got '%s'", got)
+		}
+	})
+}
+
+func TestNew_DebugRoutes(t *testing.T) {
+	t.Run("lists routes and middleware", func(t *testing.T) {
+		a := newTestApp(t, &config.AppConfig{EnableDebug: true, ETags: true}, &mockDatabase{})
+
+		w := httptest.NewRecorder()
+		a.Router.ServeHTTP(w, httptest.NewRequest("GET", "/debug/routes", nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		var body struct {
+			Middleware []string      `json:"middleware"`
+			Routes     []h.RouteInfo `json:"routes"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Expected valid JSON, got %v", err)
+		}
+
+		routes := make(map[string]h.RouteInfo, len(body.Routes))
+		for _, r := range body.Routes {
+			routes[r.Pattern] = r
+		}
+		for _, pattern := range []string{"GET /about", "GET /guitars", "GET /healthz", "GET /debug/routes", "/static/", "/"} {
+			if _, ok := routes[pattern]; !ok {
+				t.Errorf("Expected route %q to be listed", pattern)
+			}
+		}
+		if r := routes["GET /about"]; r.Method != "GET" || r.Path != "/about" || !slices.Contains(r.Middleware, "middleware.ETag") {
+			t.Errorf("Expected GET /about with the page middleware, got %+v", r)
+		}
+		// Disabled middleware is left out
+		if r := routes["GET /about"]; slices.Contains(r.Middleware, "middleware.MicroCache") {
+			t.Errorf("Expected no micro-cache without a TTL, got %v", r.Middleware)
+		}
+		if r := routes["GET /debug/routes"]; !slices.Equal(r.Middleware, []string{"middleware.NoStore"}) {
+			t.Errorf("Expected [middleware.NoStore] on /debug/routes, got %v", r.Middleware)
+		}
+
+		for _, name := range []string{"middleware.RequestID", "middleware.Recoverer", "middleware.TimeoutWithCause"} {
+			if !slices.Contains(body.Middleware, name) {
+				t.Errorf("Expected global middleware %q, got %v", name, body.Middleware)
+			}
+		}
+		if slices.Contains(body.Middleware, "middleware.ReadOnly") {
+			t.Errorf("Expected no read-only middleware when disabled, got %v", body.Middleware)
+		}
+	})
+
+	t.Run("includes routes added with Handle", func(t *testing.T) {
+		a := newTestApp(t, &config.AppConfig{EnableDebug: true}, &mockDatabase{})
+		a.Handle("GET /api/ping", http.NotFoundHandler(), mw.NoStore)
+
+		w := httptest.NewRecorder()
+		a.Router.ServeHTTP(w, httptest.NewRequest("GET", "/debug/routes", nil))
+
+		if !strings.Contains(w.Body.String(), `"pattern":"GET /api/ping"`) {
+			t.Errorf("Expected GET /api/ping to be listed, got %s", w.Body.String())
+		}
+	})
+
+	t.Run("not routed without ENABLE_DEBUG", func(t *testing.T) {
+		a := newTestApp(t, &config.AppConfig{}, &mockDatabase{})
+
+		w := httptest.NewRecorder()
+		a.Router.ServeHTTP(w, httptest.NewRequest("GET", "/debug/routes", nil))
+
+		if strings.Contains(w.Header().Get("Content-Type"), "application/json") {
+			t.Errorf("Expected no debug endpoint, got %d '%s'", w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestMiddlewareName(t *testing.T) {
+	errorLog := mw.NewErrorLog(1)
+	tests := []struct {
+		name string
+		fn   func(http.Handler) http.Handler
+		want string
+	}{
+		{name: "function", fn: mw.ETag, want: "middleware.ETag"},
+		{name: "closure", fn: mw.MicroCache(time.Second), want: "middleware.MicroCache"},
+		{name: "method value", fn: errorLog.Middleware, want: "middleware.ErrorLog.Middleware"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := middlewareName(tt.fn); got != tt.want {
+				t.Errorf("Expected name '%s', got '%s'", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// poolCloseTimeout bounds how long Shutdown waits for the pool to close. Close
+// blocks until acquired connections are returned, which a stuck request may never do.
+const poolCloseTimeout = 5 * time.Second
+
+// shutdowner is the subset of *http.Server used by Shutdown.
+type shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// ShutdownReport summarises a graceful shutdown for the post-shutdown log line.
+type ShutdownReport struct {
+	InFlight   int64         // Requests in flight when shutdown began
+	Remaining  int64         // Requests still in flight once draining ended
+	Drain      time.Duration // Time spent in server Shutdown
+	DrainErr   error         // Error from server Shutdown (e.g. context deadline exceeded)
+	PoolClosed bool          // Whether the DB pool closed within poolCloseTimeout
+}
+
+// Shutdown drains srv, then releases application resources, and reports what
+// it found. The server stops accepting connections and waits for in-flight
+// requests until ctx is done; the pool is closed afterwards so draining
+// requests can still query the database.
+func (a *App) Shutdown(ctx context.Context, srv shutdowner) ShutdownReport {
+	report := ShutdownReport{InFlight: a.InFlight()}
+
+	start := time.Now()
+	report.DrainErr = srv.Shutdown(ctx)
+	report.Drain = time.Since(start)
+	report.Remaining = a.InFlight()
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		a.Close()
+	}()
+
+	timer := time.NewTimer(poolCloseTimeout)
+	defer timer.Stop()
+	select {
+	case <-closed:
+		report.PoolClosed = true
+	case <-timer.C:
+	}
+
+	return report
+}
+
+// InFlight returns the number of requests currently being served.
+func (a *App) InFlight() int64 {
+	if a.inFlight == nil {
+		return 0
+	}
+	return a.inFlight.Count()
+}
+
+// LogShutdownSummary writes the report as a single structured line.
+func LogShutdownSummary(logger *slog.Logger, r ShutdownReport) {
+	attrs := []any{
+		"in_flight", r.InFlight,
+		"remaining", r.Remaining,
+		"drain_ms", r.Drain.Milliseconds(),
+		"pool_closed", r.PoolClosed,
+	}
+	if r.DrainErr != nil {
+		attrs = append(attrs, "drain_error", r.DrainErr.Error())
+	}
+	logger.Info("shutdown_summary", attrs...)
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mw "guitar-specs/internal/http/middleware"
+)
+
+// fakeServer stands in for *http.Server; its Shutdown runs drain.
+type fakeServer struct {
+	drain func() error
+}
+
+func (s fakeServer) Shutdown(ctx context.Context) error { return s.drain() }
+
+func TestApp_Shutdown(t *testing.T) {
+	t.Run("reports requests in flight and drained", func(t *testing.T) {
+		a := &App{inFlight: &mw.InFlightCounter{}}
+		release := make(chan struct{})
+		started := make(chan struct{}, 2)
+		handler := a.inFlight.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-release
+		}))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+			}()
+		}
+		<-started
+		<-started
+
+		report := a.Shutdown(context.Background(), fakeServer{drain: func() error {
+			time.Sleep(10 * time.Millisecond)
+			close(release)
+			wg.Wait()
+			return nil
+		}})
+
+		if report.InFlight != 2 {
+			t.Errorf("Expected 2 requests in flight, got %d", report.InFlight)
+		}
+		if report.Remaining != 0 {
+			t.Errorf("Expected 0 requests remaining, got %d", report.Remaining)
+		}
+		if report.Drain < 10*time.Millisecond {
+			t.Errorf("Expected drain time of at least 10ms, got %v", report.Drain)
+		}
+		if report.DrainErr != nil || !report.PoolClosed {
+			t.Errorf("Expected clean shutdown, got %+v", report)
+		}
+	})
+
+	t.Run("reports requests left after a drain timeout", func(t *testing.T) {
+		a := &App{inFlight: &mw.InFlightCounter{}}
+		release := make(chan struct{})
+		defer close(release)
+		started := make(chan struct{})
+		go a.inFlight.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+		})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+		<-started
+
+		report := a.Shutdown(context.Background(), fakeServer{drain: func() error {
+			return context.DeadlineExceeded
+		}})
+
+		if report.Remaining != 1 {
+			t.Errorf("Expected 1 request remaining, got %d", report.Remaining)
+		}
+		if !errors.Is(report.DrainErr, context.DeadlineExceeded) {
+			t.Errorf("Expected drain error, got %v", report.DrainErr)
+		}
+	})
+
+	t.Run("a later Close does nothing", func(t *testing.T) {
+		var stops atomic.Int32
+		a := &App{inFlight: &mw.InFlightCounter{}, stopPoolStats: func() { stops.Add(1) }}
+
+		a.Shutdown(context.Background(), fakeServer{drain: func() error { return nil }})
+		a.Close()
+
+		if got := stops.Load(); got != 1 {
+			t.Errorf("Expected resources to be released once, got %d", got)
+		}
+	})
+}
+
+func TestLogShutdownSummary(t *testing.T) {
+	var logOutput bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logOutput, nil))
+
+	LogShutdownSummary(logger, ShutdownReport{
+		InFlight:   3,
+		Remaining:  1,
+		Drain:      1500 * time.Millisecond,
+		DrainErr:   context.DeadlineExceeded,
+		PoolClosed: true,
+	})
+
+	for _, want := range []string{"msg=shutdown_summary", "in_flight=3", "remaining=1", "drain_ms=1500", "pool_closed=true", `drain_error="context deadline exceeded"`} {
+		if !strings.Contains(logOutput.String(), want) {
+			t.Errorf("Expected log to contain '%s', got: %s", want, logOutput.String())
+		}
+	}
+	if strings.Count(logOutput.String(), "\n") != 1 {
+		t.Errorf("Expected a single log line, got: %s", logOutput.String())
+	}
+}
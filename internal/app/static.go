@@ -0,0 +1,65 @@
+package app
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"time"
+
+	h "guitar-specs/internal/http/handlers"
+)
+
+// immutableCacheControl is sent for content-addressed mounts, whose URLs change
+// whenever their content does.
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// defaultStaticMaxAge is how long files from mounts that are not content-addressed
+// may be cached before the browser must revalidate them.
+const defaultStaticMaxAge = 5 * time.Minute
+
+// staticMount serves one file tree under a URL prefix, with its own cache policy.
+type staticMount struct {
+	prefix           string        // URL prefix with a trailing slash, e.g. "/static/"
+	fsys             fs.FS         // Files served relative to the prefix
+	contentAddressed bool          // File names carry a content hash, so responses can be cached as immutable
+	maxAge           time.Duration // Cache lifetime when not content-addressed (default: defaultStaticMaxAge)
+	precompressed    bool          // Prefer .br/.gz siblings and hash ETags; only for files that never change
+}
+
+// cacheControl returns the Cache-Control sent with every response from the mount.
+// Files that can change in place under the same URL must be revalidated once
+// stale, so they are never marked immutable.
+func (m staticMount) cacheControl() string {
+	if m.contentAddressed {
+		return immutableCacheControl
+	}
+	maxAge := m.maxAge
+	if maxAge <= 0 {
+		maxAge = defaultStaticMaxAge
+	}
+	return fmt.Sprintf("public, max-age=%d, must-revalidate", int(maxAge.Seconds()))
+}
+
+// handler serves the mount's files. Precompressed mounts hand browser misses to
+// notFound; plain mounts use http.FileServer's 404.
+func (m staticMount) handler(notFound http.Handler) http.Handler {
+	var files http.Handler
+	if m.precompressed {
+		files = h.PrecompressedFileServerWithNotFound(m.fsys, notFound)
+	} else {
+		files = http.FileServerFS(m.fsys)
+	}
+
+	cacheControl := m.cacheControl()
+	return http.StripPrefix(m.prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", cacheControl)
+		files.ServeHTTP(w, r)
+	}))
+}
+
+// registerStaticMounts routes each mount's prefix on rt, wrapped in mws.
+func registerStaticMounts(rt *routeTable, mounts []staticMount, notFound http.Handler, mws ...func(http.Handler) http.Handler) {
+	for _, m := range mounts {
+		rt.handle(m.prefix, m.handler(notFound), mws...)
+	}
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestRegisterStaticMounts(t *testing.T) {
+	mux := http.NewServeMux()
+	registerStaticMounts(newRouteTable(mux), []staticMount{
+		{
+			prefix:           "/static/",
+			fsys:             fstest.MapFS{"css/main.css": &fstest.MapFile{Data: []byte("body{}")}},
+			contentAddressed: true,
+			precompressed:    true,
+		},
+		{
+			prefix: "/uploads/",
+			fsys:   fstest.MapFS{"photo.txt": &fstest.MapFile{Data: []byte("upload")}},
+		},
+	}, nil)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantBody   string
+		wantCache  string
+		wantETag   bool
+	}{
+		{name: "embedded root", target: "/static/css/main.css", wantStatus: http.StatusOK, wantBody: "body{}", wantCache: "public, max-age=31536000, immutable", wantETag: true},
+		{name: "uploads root", target: "/uploads/photo.txt", wantStatus: http.StatusOK, wantBody: "upload", wantCache: "public, max-age=300, must-revalidate"},
+		{name: "roots are separate", target: "/uploads/css/main.css", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", tt.target, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("Expected body '%s', got '%s'", tt.wantBody, w.Body.String())
+			}
+			if cc := w.Header().Get("Cache-Control"); cc != tt.wantCache {
+				t.Errorf("Expected Cache-Control '%s', got '%s'", tt.wantCache, cc)
+			}
+			if etag := w.Header().Get("ETag"); (etag != "") != tt.wantETag {
+				t.Errorf("Expected ETag present %v, got '%s'", tt.wantETag, etag)
+			}
+		})
+	}
+}
+
+func TestStaticMount_CacheControl(t *testing.T) {
+	tests := []struct {
+		name  string
+		mount staticMount
+		want  string
+	}{
+		{name: "content-addressed", mount: staticMount{contentAddressed: true}, want: "public, max-age=31536000, immutable"},
+		{name: "content-addressed ignores max age", mount: staticMount{contentAddressed: true, maxAge: time.Minute}, want: "public, max-age=31536000, immutable"},
+		{name: "default revalidates", mount: staticMount{}, want: "public, max-age=300, must-revalidate"},
+		{name: "custom max age", mount: staticMount{maxAge: time.Hour}, want: "public, max-age=3600, must-revalidate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.mount.cacheControl(); got != tt.want {
+				t.Errorf("Expected Cache-Control '%s', got '%s'", tt.want, got)
+			}
+		})
+	}
+}
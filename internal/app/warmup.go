@@ -0,0 +1,31 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"guitar-specs/internal/models"
+)
+
+// guitarLister is the subset of the guitar store exercised by the startup warm-up.
+type guitarLister interface {
+	List(ctx context.Context) ([]models.Guitar, error)
+}
+
+// warmUp runs the common catalogue query once so the first real request finds
+// warm pool connections and cached query plans. Failures are logged and ignored;
+// a cold start is slower, not broken.
+func warmUp(ctx context.Context, logger *slog.Logger, guitars guitarLister, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	list, err := guitars.List(ctx)
+	if err != nil {
+		logger.Warn("warm-up query failed", "query", "guitars.list", "error", err, "duration_ms", time.Since(start).Milliseconds())
+		return
+	}
+
+	logger.Info("warm-up completed", "query", "guitars.list", "rows", len(list), "duration_ms", time.Since(start).Milliseconds())
+}
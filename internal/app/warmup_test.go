@@ -0,0 +1,60 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"guitar-specs/internal/models"
+)
+
+// stubLister records List calls and returns canned results.
+type stubLister struct {
+	calls   int
+	guitars []models.Guitar
+	err     error
+}
+
+func (s *stubLister) List(ctx context.Context) ([]models.Guitar, error) {
+	s.calls++
+	if _, ok := ctx.Deadline(); !ok {
+		return nil, errors.New("warm-up context has no deadline")
+	}
+	return s.guitars, s.err
+}
+
+func TestWarmUp(t *testing.T) {
+	t.Run("runs list query once", func(t *testing.T) {
+		var logOutput bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&logOutput, &slog.HandlerOptions{}))
+		stub := &stubLister{guitars: []models.Guitar{{Slug: "a"}, {Slug: "b"}}}
+
+		warmUp(context.Background(), logger, stub, time.Second)
+
+		if stub.calls != 1 {
+			t.Errorf("Expected List to be called once, got %d", stub.calls)
+		}
+		if !strings.Contains(logOutput.String(), "warm-up completed") || !strings.Contains(logOutput.String(), "rows=2") {
+			t.Errorf("Expected completion log with row count, got: %s", logOutput.String())
+		}
+	})
+
+	t.Run("is non-fatal on error", func(t *testing.T) {
+		var logOutput bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&logOutput, &slog.HandlerOptions{}))
+		stub := &stubLister{err: errors.New("connection refused")}
+
+		warmUp(context.Background(), logger, stub, time.Second)
+
+		if stub.calls != 1 {
+			t.Errorf("Expected List to be called once, got %d", stub.calls)
+		}
+		if !strings.Contains(logOutput.String(), "warm-up query failed") || !strings.Contains(logOutput.String(), "connection refused") {
+			t.Errorf("Expected failure to be logged, got: %s", logOutput.String())
+		}
+	})
+}
@@ -7,6 +7,10 @@ type AssetProvider interface {
 	// Example: "/static/css/main.css" -> "/static/css/main.abc123.css"
 	AssetURL(path string) string
 
+	// AssetQueryURL returns the unrenamed path versioned with a query string
+	// Example: "/static/css/main.css" -> "/static/css/main.css?v=abc123"
+	AssetQueryURL(path string) string
+
 	// AssetSRI returns the Subresource Integrity hash for an asset
 	// Example: "sha384-abc123def456..."
 	AssetSRI(path string) string
@@ -35,6 +39,9 @@ type AssetInfo struct {
 	// Subresource Integrity hash
 	SRI string `json:"sri"`
 
+	// Content hash used in the versioned filename
+	Hash string `json:"hash"`
+
 	// File size in bytes
 	Size int64 `json:"size"`
 
@@ -2,12 +2,22 @@ package assets
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"log/slog"
 	"strings"
 )
 
+// MaxManifestSize caps how much of the manifest file is read. The manifest
+// only maps logical names to hashed paths, so anything larger points at a
+// broken build rather than a real asset set.
+const MaxManifestSize = 4 << 20 // 4MB
+
+// ErrManifestTooLarge is returned when the manifest exceeds MaxManifestSize.
+var ErrManifestTooLarge = errors.New("manifest too large")
+
 // AssetManager manages static assets with versioning and SRI support.
 // It implements the AssetProvider interface.
 type AssetManager struct {
@@ -24,18 +34,25 @@ type AssetManifestWrapper struct {
 // New creates a new asset manager instance.
 // It loads the manifest from the filesystem and validates assets.
 func New(staticFS fs.FS, logger *slog.Logger) (AssetProvider, error) {
-	if logger != nil {
-		logger.Debug("AssetManager.New called", "staticFS_type", fmt.Sprintf("%T", staticFS))
-	}
+	return NewWithManifestPath(staticFS, "", logger)
+}
 
-	// Debug: list available files in the filesystem
+// NewWithManifestPath creates an asset manager that tries manifestPath (relative
+// to staticFS) before the built-in candidate locations. An empty path uses the
+// candidates only.
+func NewWithManifestPath(staticFS fs.FS, manifestPath string, logger *slog.Logger) (AssetProvider, error) {
 	if logger != nil {
-		logger.Debug("AssetManager.New listing filesystem contents")
-		listFilesystemContents(staticFS, "", logger)
+		logger.Debug("AssetManager.New called", "staticFS_type", fmt.Sprintf("%T", staticFS))
 	}
 
-	manifest, err := loadManifest(staticFS)
+	manifest, err := loadManifest(staticFS, manifestPath)
 	if err != nil {
+		// Walking a large asset tree is slow and noisy, so the listing is only
+		// produced as a diagnostic when the manifest cannot be found
+		if logger != nil {
+			logger.Debug("AssetManager.New listing filesystem contents")
+			listFilesystemContents(staticFS, ".", logger)
+		}
 		return nil, fmt.Errorf("failed to load asset manifest: %w", err)
 	}
 
@@ -50,6 +67,7 @@ func New(staticFS fs.FS, logger *slog.Logger) (AssetProvider, error) {
 }
 
 // listFilesystemContents recursively lists files in the filesystem for debugging
+// a missing manifest
 func listFilesystemContents(fsys fs.FS, path string, logger *slog.Logger) {
 	entries, err := fs.ReadDir(fsys, path)
 	if err != nil {
@@ -59,7 +77,7 @@ func listFilesystemContents(fsys fs.FS, path string, logger *slog.Logger) {
 
 	for _, entry := range entries {
 		fullPath := path
-		if fullPath != "" {
+		if fullPath != "" && fullPath != "." {
 			fullPath = fullPath + "/" + entry.Name()
 		} else {
 			fullPath = entry.Name()
@@ -99,6 +117,20 @@ func (am *AssetManager) AssetURL(path string) string {
 	return path
 }
 
+// AssetQueryURL returns the original path with a ?v=hash query string, for
+// pipelines and CDNs that version by query rather than filename. It returns the
+// original path if the asset or its hash is not in the manifest.
+func (am *AssetManager) AssetQueryURL(path string) string {
+	if info, ok := am.GetAssetInfo(path); ok && info.Hash != "" {
+		return versionQuery(path, info.Hash)
+	}
+
+	if am.logger != nil {
+		am.logger.Warn("asset hash not found in manifest", "path", path)
+	}
+	return path
+}
+
 // AssetSRI returns the Subresource Integrity hash for an asset.
 // It returns an empty string if the asset is not found in the manifest.
 func (am *AssetManager) AssetSRI(path string) string {
@@ -154,26 +186,55 @@ func (am *AssetManager) GetAssetInfo(path string) (AssetInfo, bool) {
 	return AssetInfo{}, false
 }
 
+// ResolveAll looks up a page's assets in one call, keyed by the paths as given.
+// Missing paths are left out of the map, so callers check with the comma-ok form,
+// and are reported in a single warning rather than one per asset. The manifest
+// is read-only after New, so a batch needs no locking.
+func (am *AssetManager) ResolveAll(paths []string) map[string]AssetInfo {
+	resolved := make(map[string]AssetInfo, len(paths))
+	var missing []string
+	for _, path := range paths {
+		if info, ok := am.GetAssetInfo(path); ok {
+			resolved[path] = info
+		} else {
+			missing = append(missing, path)
+		}
+	}
+
+	if len(missing) > 0 && am.logger != nil {
+		am.logger.Warn("assets not found in manifest", "paths", missing)
+	}
+	return resolved
+}
+
 // loadManifest loads the asset manifest from the filesystem.
-// It expects the manifest to be located at "static/dist/js/manifest.json".
-func loadManifest(staticFS fs.FS) (AssetManifest, error) {
+// It expects the manifest to be located at "static/dist/js/manifest.json",
+// unless a configured path is given, which is tried first.
+func loadManifest(staticFS fs.FS, manifestPath string) (AssetManifest, error) {
 	// Try different possible paths for the manifest
 	possiblePaths := []string{
 		"static/dist/js/manifest.json",
 		"web/static/dist/js/manifest.json",
 		"dist/js/manifest.json",
 	}
+	if manifestPath != "" {
+		// fs.FS paths are unrooted
+		possiblePaths = append([]string{strings.TrimPrefix(manifestPath, "/")}, possiblePaths...)
+	}
 
 	var manifestBytes []byte
 	var err error
 	var usedPath string
 
 	for _, path := range possiblePaths {
-		manifestBytes, err = fs.ReadFile(staticFS, path)
+		manifestBytes, err = readManifest(staticFS, path)
 		if err == nil {
 			usedPath = path
 			break
 		}
+		if errors.Is(err, ErrManifestTooLarge) {
+			return nil, err
+		}
 	}
 
 	if err != nil {
@@ -193,6 +254,30 @@ func loadManifest(staticFS fs.FS) (AssetManifest, error) {
 	return wrapper.Files, nil
 }
 
+// readManifest reads the manifest at path, refusing files larger than
+// MaxManifestSize. The size is checked up front via Stat and enforced again
+// while reading, in case the reported size is wrong.
+func readManifest(fsys fs.FS, path string) ([]byte, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if info, err := f.Stat(); err == nil && info.Size() > MaxManifestSize {
+		return nil, fmt.Errorf("%w: %s is %d bytes, limit is %d", ErrManifestTooLarge, path, info.Size(), MaxManifestSize)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(f, MaxManifestSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxManifestSize {
+		return nil, fmt.Errorf("%w: %s exceeds %d bytes", ErrManifestTooLarge, path, MaxManifestSize)
+	}
+	return data, nil
+}
+
 // getManifestKeys returns all available manifest keys for debugging
 func getManifestKeys(manifest AssetManifest) []string {
 	keys := make([]string, 0, len(manifest))
@@ -1,8 +1,12 @@
 package assets
 
 import (
+	"bytes"
+	"errors"
+	"io/fs"
 	"log/slog"
 	"os"
+	"strings"
 	"testing"
 	"testing/fstest"
 )
@@ -158,6 +162,27 @@ func TestNewWithInvalidJSON(t *testing.T) {
 	}
 }
 
+func TestNewWithOversizedManifest(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+
+	// Pad a valid manifest past the limit so only the size check can fail it
+	data := append([]byte(`{"files":{"app.js":"/static/dist/js/app.abc.js"}}`), bytes.Repeat([]byte(" "), MaxManifestSize)...)
+	oversizedFS := fstest.MapFS{
+		"custom/manifest.json": &fstest.MapFile{Data: data},
+		"static/dist/js/manifest.json": &fstest.MapFile{
+			Data: []byte(`{"files":{"app.js":"/static/dist/js/app.abc.js"}}`),
+		},
+	}
+
+	_, err := NewWithManifestPath(oversizedFS, "custom/manifest.json", logger)
+	if !errors.Is(err, ErrManifestTooLarge) {
+		t.Fatalf("Expected ErrManifestTooLarge, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "custom/manifest.json") {
+		t.Errorf("Expected error to name the manifest path, got %q", err.Error())
+	}
+}
+
 func TestNewWithEmptyManifest(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
 
@@ -236,6 +261,45 @@ func TestAssetManager_AssetURL(t *testing.T) {
 	}
 }
 
+func TestAssetManager_AssetQueryURL(t *testing.T) {
+	am := &AssetManager{
+		manifest: AssetManifest{
+			"static/css/main.css": AssetInfo{
+				Path: "/static/css/main.abc123.css",
+				Hash: "abc123",
+			},
+			"static/js/legacy.js": AssetInfo{
+				Path: "/static/js/legacy.def456.js",
+			},
+		},
+		logger: slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{})),
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "with leading slash", input: "/static/css/main.css", expected: "/static/css/main.css?v=abc123"},
+		{name: "without leading slash", input: "static/css/main.css", expected: "/static/css/main.css?v=abc123"},
+		{name: "manifest entry without hash", input: "/static/js/legacy.js", expected: "/static/js/legacy.js"},
+		{name: "non-existent file", input: "/static/css/notfound.css", expected: "/static/css/notfound.css"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := am.AssetQueryURL(tt.input)
+			if result != tt.expected {
+				t.Errorf("AssetQueryURL(%s) = %s, want %s", tt.input, result, tt.expected)
+			}
+		})
+	}
+
+	if am.AssetQueryURL("/static/css/main.css") == am.AssetURL("/static/css/main.css") {
+		t.Error("Expected the query-string URL to differ from the renamed asset URL")
+	}
+}
+
 func TestAssetManager_AssetSRI(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
 
@@ -353,6 +417,43 @@ func TestAssetManager_GetAssetInfo(t *testing.T) {
 	}
 }
 
+func TestAssetManager_ResolveAll(t *testing.T) {
+	var logOutput bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logOutput, &slog.HandlerOptions{}))
+
+	css := AssetInfo{Path: "/static/css/main.abc123.css", SRI: "sha384-abc123"}
+	js := AssetInfo{Path: "/static/js/app.def456.js", SRI: "sha384-def456"}
+	am := &AssetManager{
+		manifest: AssetManifest{
+			"static/css/main.css": css,
+			"static/js/app.js":    js,
+		},
+		logger: logger,
+	}
+
+	resolved := am.ResolveAll([]string{"/static/css/main.css", "static/js/app.js", "/static/js/missing.js", "/static/img/missing.png"})
+
+	if len(resolved) != 2 {
+		t.Fatalf("Expected 2 resolved assets, got %d: %+v", len(resolved), resolved)
+	}
+	if resolved["/static/css/main.css"] != css {
+		t.Errorf("Expected %+v for the slash-prefixed path, got %+v", css, resolved["/static/css/main.css"])
+	}
+	if resolved["static/js/app.js"] != js {
+		t.Errorf("Expected %+v for the bare path, got %+v", js, resolved["static/js/app.js"])
+	}
+	if _, ok := resolved["/static/js/missing.js"]; ok {
+		t.Error("Expected missing asset to be absent from the result")
+	}
+
+	if n := strings.Count(logOutput.String(), "assets not found in manifest"); n != 1 {
+		t.Errorf("Expected a single warning for the misses, got %d: %s", n, logOutput.String())
+	}
+	if !strings.Contains(logOutput.String(), "/static/img/missing.png") {
+		t.Errorf("Expected missing paths in the warning, got: %s", logOutput.String())
+	}
+}
+
 func TestAssetManager_GetManifest(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
 
@@ -373,3 +474,102 @@ func TestAssetManager_GetManifest(t *testing.T) {
 		t.Errorf("GetManifest() returned %d items, want %d", len(manifest), len(expectedManifest))
 	}
 }
+
+func TestNewWithManifestPath(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+
+	manifest := func(path string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(`{"files": {"static/css/main.css": {"path": "` + path + `"}}}`)}
+	}
+
+	t.Run("custom path outside the default list", func(t *testing.T) {
+		customFS := fstest.MapFS{
+			"build/assets/manifest.json": manifest("/static/css/main.custom.css"),
+		}
+
+		assetManager, err := NewWithManifestPath(customFS, "build/assets/manifest.json", logger)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if url := assetManager.AssetURL("/static/css/main.css"); url != "/static/css/main.custom.css" {
+			t.Errorf("Expected URL from custom manifest, got %s", url)
+		}
+	})
+
+	t.Run("custom path wins over defaults", func(t *testing.T) {
+		bothFS := fstest.MapFS{
+			"build/assets/manifest.json":   manifest("/static/css/main.custom.css"),
+			"static/dist/js/manifest.json": manifest("/static/css/main.default.css"),
+		}
+
+		assetManager, err := NewWithManifestPath(bothFS, "/build/assets/manifest.json", logger)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if url := assetManager.AssetURL("/static/css/main.css"); url != "/static/css/main.custom.css" {
+			t.Errorf("Expected URL from custom manifest, got %s", url)
+		}
+	})
+
+	t.Run("falls back to defaults when custom path is missing", func(t *testing.T) {
+		defaultFS := fstest.MapFS{
+			"static/dist/js/manifest.json": manifest("/static/css/main.default.css"),
+		}
+
+		assetManager, err := NewWithManifestPath(defaultFS, "build/assets/manifest.json", logger)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if url := assetManager.AssetURL("/static/css/main.css"); url != "/static/css/main.default.css" {
+			t.Errorf("Expected URL from default manifest, got %s", url)
+		}
+	})
+}
+
+// readDirCountingFS counts directory listings made against the wrapped filesystem.
+type readDirCountingFS struct {
+	fstest.MapFS
+	readDirs int
+}
+
+func (f *readDirCountingFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	f.readDirs++
+	return f.MapFS.ReadDir(name)
+}
+
+func TestNew_FilesystemListing(t *testing.T) {
+	var logOutput bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logOutput, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	t.Run("not listed when the manifest loads", func(t *testing.T) {
+		logOutput.Reset()
+		countingFS := &readDirCountingFS{MapFS: fstest.MapFS{
+			"static/dist/js/manifest.json": &fstest.MapFile{Data: []byte(`{"files": {"static/css/main.css": {"path": "/static/css/main.abc123.css"}}}`)},
+			"static/css/main.css":          &fstest.MapFile{Data: []byte("body {}")},
+		}}
+
+		if _, err := New(countingFS, logger); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if countingFS.readDirs != 0 {
+			t.Errorf("Expected no directory listing, got %d ReadDir calls", countingFS.readDirs)
+		}
+		if strings.Contains(logOutput.String(), "file found") {
+			t.Errorf("Expected no filesystem listing in log, got: %s", logOutput.String())
+		}
+	})
+
+	t.Run("listed as a diagnostic when the manifest is missing", func(t *testing.T) {
+		logOutput.Reset()
+		countingFS := &readDirCountingFS{MapFS: fstest.MapFS{
+			"static/css/main.css": &fstest.MapFile{Data: []byte("body {}")},
+		}}
+
+		if _, err := New(countingFS, logger); err == nil {
+			t.Fatal("Expected error when manifest doesn't exist, got nil")
+		}
+		if !strings.Contains(logOutput.String(), "path=static/css/main.css") {
+			t.Errorf("Expected filesystem listing in log, got: %s", logOutput.String())
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"path"
+	"strings"
+)
+
+// MaxFileSize caps the size of files hashed by BuildAssetVersions.
+// Larger files are skipped rather than read, keeping startup bounded.
+const MaxFileSize = 10 << 20 // 10MB
+
+// DefaultFingerprintExtensions lists the file types BuildAssetVersions hashes.
+var DefaultFingerprintExtensions = []string{
+	".css", ".js", ".mjs", ".map",
+	".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp", ".ico",
+	".woff", ".woff2", ".ttf",
+}
+
+// BuildAssetVersions walks root in fsys and returns a map from URL path
+// ("/static/css/main.css") to an 8-character content hash. Files are streamed
+// through SHA-256, files over MaxFileSize are skipped, and only files with one of
+// the given extensions are considered (nil means DefaultFingerprintExtensions).
+func BuildAssetVersions(fsys fs.FS, root string, extensions []string) (map[string]string, error) {
+	versions := make(map[string]string)
+	allowed := extensionSet(extensions)
+
+	err := fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !allowed[strings.ToLower(path.Ext(p))] {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() > MaxFileSize {
+			return nil
+		}
+
+		hash, err := hashFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		versions["/"+p] = hash
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build asset versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// extensionSet normalises extensions to lower case with a leading dot, so that
+// "webp" and ".WEBP" both match exactly ".webp" rather than any name suffix.
+func extensionSet(extensions []string) map[string]bool {
+	if extensions == nil {
+		extensions = DefaultFingerprintExtensions
+	}
+	set := make(map[string]bool, len(extensions))
+	for _, ext := range extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		set[ext] = true
+	}
+	return set
+}
+
+// hashFile streams a file through SHA-256 and returns the first 8 hex characters.
+func hashFile(fsys fs.FS, p string) (string, error) {
+	f, err := fsys.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil))[:8], nil
+}
+
+// HashAssetProvider serves asset URLs versioned with a content-hash query string.
+// It is meant for development, where there is no build step producing a manifest:
+// "/static/css/main.css" becomes "/static/css/main.css?v=1a2b3c4d". It has no SRI.
+// It implements the AssetProvider interface.
+type HashAssetProvider struct {
+	versions map[string]string
+	logger   *slog.Logger
+}
+
+// NewHashAssetProvider hashes the files under "static" in staticFS that have one
+// of the given extensions (nil means DefaultFingerprintExtensions).
+func NewHashAssetProvider(staticFS fs.FS, extensions []string, logger *slog.Logger) (AssetProvider, error) {
+	versions, err := BuildAssetVersions(staticFS, "static", extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	if logger != nil {
+		logger.Debug("HashAssetProvider built asset versions", "count", len(versions))
+	}
+
+	return &HashAssetProvider{versions: versions, logger: logger}, nil
+}
+
+// AssetURL returns the path with a ?v=hash query, or the path unchanged if unknown.
+func (p *HashAssetProvider) AssetURL(assetPath string) string {
+	if hash, ok := p.lookup(assetPath); ok {
+		return versionQuery(assetPath, hash)
+	}
+
+	if p.logger != nil {
+		p.logger.Warn("asset not found in static filesystem", "path", assetPath)
+	}
+	return assetPath
+}
+
+// AssetQueryURL is the same as AssetURL, which already versions by query string.
+func (p *HashAssetProvider) AssetQueryURL(assetPath string) string {
+	return p.AssetURL(assetPath)
+}
+
+// AssetSRI always returns an empty string; hashed dev assets carry no integrity value.
+func (p *HashAssetProvider) AssetSRI(assetPath string) string {
+	return ""
+}
+
+// GetManifest returns a manifest view of the hashed assets.
+func (p *HashAssetProvider) GetManifest() AssetManifest {
+	manifest := make(AssetManifest, len(p.versions))
+	for key := range p.versions {
+		info, _ := p.GetAssetInfo(key)
+		manifest[key] = info
+	}
+	return manifest
+}
+
+// HasAsset returns true if the asset was hashed.
+func (p *HashAssetProvider) HasAsset(assetPath string) bool {
+	_, ok := p.lookup(assetPath)
+	return ok
+}
+
+// GetAssetInfo returns the versioned URL and content type for a hashed asset.
+func (p *HashAssetProvider) GetAssetInfo(assetPath string) (AssetInfo, bool) {
+	hash, ok := p.lookup(assetPath)
+	if !ok {
+		return AssetInfo{}, false
+	}
+	return AssetInfo{
+		Path:        p.AssetURL(assetPath),
+		Filename:    strings.TrimPrefix(assetPath, "/"),
+		Hash:        hash,
+		ContentType: mime.TypeByExtension(path.Ext(assetPath)),
+	}, true
+}
+
+// ResolveAll returns info for each hashed path, keyed by the paths as given.
+// Missing paths are left out of the map.
+func (p *HashAssetProvider) ResolveAll(paths []string) map[string]AssetInfo {
+	resolved := make(map[string]AssetInfo, len(paths))
+	for _, assetPath := range paths {
+		if info, ok := p.GetAssetInfo(assetPath); ok {
+			resolved[assetPath] = info
+		}
+	}
+	return resolved
+}
+
+// versionQuery returns assetPath, rooted, with a ?v=hash query string.
+func versionQuery(assetPath, hash string) string {
+	return "/" + strings.TrimPrefix(assetPath, "/") + "?v=" + hash
+}
+
+// lookup finds the hash for a path given with or without a leading slash.
+func (p *HashAssetProvider) lookup(assetPath string) (string, bool) {
+	hash, ok := p.versions["/"+strings.TrimPrefix(assetPath, "/")]
+	return hash, ok
+}
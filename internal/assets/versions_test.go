@@ -0,0 +1,190 @@
+package assets
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestBuildAssetVersions(t *testing.T) {
+	mockFS := fstest.MapFS{
+		"static/css/main.css": &fstest.MapFile{Data: []byte("body{}")},
+		"static/js/app.js":    &fstest.MapFile{Data: []byte("console.log(1)")},
+		"static/build.js":     &fstest.MapFile{Data: []byte("console.log(1)")}, // Same bytes as app.js
+		"static/README.txt":   &fstest.MapFile{Data: []byte("not an asset")},
+	}
+
+	versions, err := BuildAssetVersions(mockFS, "static", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	hash, ok := versions["/static/css/main.css"]
+	if !ok {
+		t.Fatal("Expected /static/css/main.css to be versioned")
+	}
+	if len(hash) != 8 {
+		t.Errorf("Expected 8-character hash, got %q", hash)
+	}
+
+	if _, ok := versions["/static/README.txt"]; ok {
+		t.Error("Expected .txt file to be skipped")
+	}
+
+	// Identical content must hash identically; different content must not
+	if versions["/static/js/app.js"] != versions["/static/build.js"] {
+		t.Errorf("Expected identical content to produce the same hash, got %q and %q", versions["/static/js/app.js"], versions["/static/build.js"])
+	}
+	if versions["/static/js/app.js"] == versions["/static/css/main.css"] {
+		t.Error("Expected different content to produce different hashes")
+	}
+}
+
+func TestHashAssetProvider_AssetURL(t *testing.T) {
+	mockFS := fstest.MapFS{
+		"static/css/main.css": &fstest.MapFile{Data: []byte("body{}")},
+	}
+
+	provider, err := NewHashAssetProvider(mockFS, nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var _ AssetProvider = provider
+
+	url := provider.AssetURL("/static/css/main.css")
+	if !strings.HasPrefix(url, "/static/css/main.css?v=") {
+		t.Errorf("Expected ?v= query string, got %s", url)
+	}
+	if provider.AssetURL("static/css/main.css") != url {
+		t.Error("Expected same URL with and without leading slash")
+	}
+
+	if got := provider.AssetURL("/static/css/missing.css"); got != "/static/css/missing.css" {
+		t.Errorf("Expected unknown asset to pass through, got %s", got)
+	}
+
+	if got := provider.AssetQueryURL("/static/css/main.css"); got != url {
+		t.Errorf("Expected AssetQueryURL to match the ?v= URL %s, got %s", url, got)
+	}
+
+	if sri := provider.AssetSRI("/static/css/main.css"); sri != "" {
+		t.Errorf("Expected empty SRI, got %s", sri)
+	}
+}
+
+func TestBuildAssetVersions_SkipsLargeFiles(t *testing.T) {
+	mockFS := fstest.MapFS{
+		"static/css/main.css": &fstest.MapFile{Data: []byte("body{}")},
+		"static/img/huge.png": &fstest.MapFile{Data: make([]byte, MaxFileSize+1)},
+	}
+
+	versions, err := BuildAssetVersions(mockFS, "static", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, ok := versions["/static/img/huge.png"]; ok {
+		t.Error("Expected file over MaxFileSize to be skipped")
+	}
+	if _, ok := versions["/static/css/main.css"]; !ok {
+		t.Error("Expected small file to be versioned")
+	}
+}
+
+func TestHashAssetProvider_GetAssetInfo(t *testing.T) {
+	mockFS := fstest.MapFS{
+		"static/css/main.css": &fstest.MapFile{Data: []byte("body{}")},
+	}
+
+	provider, err := NewHashAssetProvider(mockFS, nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	info, ok := provider.GetAssetInfo("/static/css/main.css")
+	if !ok {
+		t.Fatal("Expected asset info to be found")
+	}
+	if !strings.Contains(info.Path, "?v=") {
+		t.Errorf("Expected versioned path, got %s", info.Path)
+	}
+	if len(info.Hash) != 8 || !strings.HasSuffix(info.Path, info.Hash) {
+		t.Errorf("Expected the 8-character hash from the URL, got %s", info.Hash)
+	}
+	if !strings.HasPrefix(info.ContentType, "text/css") {
+		t.Errorf("Expected text/css content type, got %s", info.ContentType)
+	}
+
+	if !provider.HasAsset("static/css/main.css") {
+		t.Error("Expected HasAsset to be true")
+	}
+	if len(provider.GetManifest()) != 1 {
+		t.Errorf("Expected 1 manifest entry, got %d", len(provider.GetManifest()))
+	}
+}
+
+func TestHashAssetProvider_ResolveAll(t *testing.T) {
+	mockFS := fstest.MapFS{
+		"static/css/main.css": &fstest.MapFile{Data: []byte("body{}")},
+	}
+	provider, err := NewHashAssetProvider(mockFS, nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	resolved := provider.(*HashAssetProvider).ResolveAll([]string{"/static/css/main.css", "/static/js/missing.js"})
+
+	if len(resolved) != 1 {
+		t.Fatalf("Expected 1 resolved asset, got %d", len(resolved))
+	}
+	if info := resolved["/static/css/main.css"]; !strings.Contains(info.Path, "?v=") {
+		t.Errorf("Expected versioned path, got %s", info.Path)
+	}
+}
+
+func TestBuildAssetVersions_Extensions(t *testing.T) {
+	mockFS := fstest.MapFS{
+		"static/img/photo.webp":    &fstest.MapFile{Data: []byte("webp")},
+		"static/fonts/inter.woff":  &fstest.MapFile{Data: []byte("woff")},
+		"static/fonts/inter.woff2": &fstest.MapFile{Data: []byte("woff2")},
+		"static/img/notwebp":       &fstest.MapFile{Data: []byte("no extension")},
+		"static/data/guitars.json": &fstest.MapFile{Data: []byte("{}")},
+		"static/css/main.css":      &fstest.MapFile{Data: []byte("body{}")},
+	}
+
+	t.Run("default list matches .webp and .woff exactly", func(t *testing.T) {
+		versions, err := BuildAssetVersions(mockFS, "static", nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		for _, p := range []string{"/static/img/photo.webp", "/static/fonts/inter.woff", "/static/fonts/inter.woff2"} {
+			if _, ok := versions[p]; !ok {
+				t.Errorf("Expected %s to be fingerprinted", p)
+			}
+		}
+		for _, p := range []string{"/static/img/notwebp", "/static/data/guitars.json"} {
+			if _, ok := versions[p]; ok {
+				t.Errorf("Expected %s to be skipped", p)
+			}
+		}
+	})
+
+	t.Run("custom list replaces the default and tolerates missing dots", func(t *testing.T) {
+		versions, err := BuildAssetVersions(mockFS, "static", []string{"json", ".WEBP"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(versions) != 2 {
+			t.Errorf("Expected 2 fingerprinted files, got %d: %v", len(versions), versions)
+		}
+		if _, ok := versions["/static/data/guitars.json"]; !ok {
+			t.Error("Expected .json file to be fingerprinted")
+		}
+		if _, ok := versions["/static/css/main.css"]; ok {
+			t.Error("Expected .css file to be skipped with a custom list")
+		}
+	})
+}
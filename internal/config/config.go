@@ -2,11 +2,17 @@ package config
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"net/netip"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"time"
+
+	"guitar-specs/internal/logging"
+	"guitar-specs/internal/permissionspolicy"
 )
 
 // AppConfig holds the application configuration settings.
@@ -29,39 +35,357 @@ type AppConfig struct {
 	DBName     string // PostgreSQL database name
 	DBSSLMode  string // sslmode (disable, require, verify-ca, verify-full)
 
+	// Server-side limits passed in the DSN (0 leaves the PostgreSQL default)
+	DBConnectTimeout   time.Duration // connect_timeout, whole seconds (default: 5s)
+	DBStatementTimeout time.Duration // statement_timeout (default: 30s)
+
+	// Connection pool sizing
+	DBMinConns   int  // Connections the pool keeps open (default: 0, the pgxpool default)
+	DBPoolWarmUp bool // Open DBMinConns connections at startup rather than lazily (default: false)
+
+	// Startup connection retries for a database that is not up yet
+	DBStartupRetries    int           // Retries after the first failed attempt (default: 0)
+	DBStartupRetryDelay time.Duration // Delay before the first retry, doubled each time (default: 1s)
+
+	// Per-query-type safety timeouts (capped by the remaining request deadline)
+	DBListTimeout     time.Duration // Listing queries (default: 5s)
+	DBGetTimeout      time.Duration // Single-row lookups (default: 5s)
+	DBFeaturesTimeout time.Duration // Feature resolution queries (default: 5s)
+
+	// Startup warm-up of common queries and templates
+	Warmup         bool          // Run common queries once at startup (default: false)
+	WarmupTimeout  time.Duration // Time budget for the warm-up (default: 3s)
+	TemplateWarmup bool          // Execute each template once at startup (default: false)
+
+	// Connection pool visibility
+	PoolStatsInterval time.Duration // How often pool stats are logged at debug level (default: 0, disabled)
+
+	// Degraded reads
+	StaleOnError bool // Serve the last good guitar list when the DB query fails (default: false)
+
+	// Maintenance
+	ReadOnly bool // Reject POST/PUT/PATCH/DELETE with 503 while reads continue (default: false)
+
 	// Advanced configuration options
 	ReadTimeout       time.Duration // Request read timeout (default: 10s)
 	WriteTimeout      time.Duration // Response write timeout (default: 30s)
 	IdleTimeout       time.Duration // Connection idle timeout (default: 60s)
 	ReadHeaderTimeout time.Duration // Header read timeout (default: 5s)
 	MaxHeaderBytes    int           // Maximum header size in bytes (1MB)
+	MaxHeaders        int           // Maximum number of request header fields (default: 100)
+	MaxURLLength      int           // Maximum request URI length in bytes (default: 8192)
+
+	// Concurrency limiting (bounds in-flight requests, not request rate)
+	MaxConcurrentRequests   int           // Maximum requests processed at once (default: 0, disabled)
+	ConcurrencyQueueTimeout time.Duration // How long to wait for a free slot (default: 0, reject immediately)
+	MaxConcurrentPerIP      int           // Maximum in-flight requests per client IP (default: 0, disabled)
+
+	// Response compression and validators for page handlers
+	Compress      bool // Compress page responses with br or gzip (default: false, left to the CDN)
+	CompressLevel int  // br quality 0-11, gzip level 1-9; clamped when out of range (default: 5)
+	ETags         bool // Tag page responses with body-hash ETags for 304s (default: false)
+
+	// Serve identical GET page responses from memory for this long (default: 0, disabled)
+	MicroCacheTTL time.Duration
+
+	// Cache-Control for rendered pages that set none themselves; empty sends none
+	// (default: "public, max-age=60" in production, "no-cache" elsewhere)
+	HTMLCacheControl string
+
+	// RFC 5861 extensions appended to HTMLCacheControl, in whole seconds (default: 0, omitted)
+	HTMLStaleWhileRevalidate time.Duration // Caches may serve a stale page while refreshing it
+	HTMLStaleIfError         time.Duration // Caches may serve a stale page when the origin errors
+
+	// Server-Timing header with db/render/total durations (exposes internals; default: false)
+	ServerTiming bool
+
+	// Static file extensions content-hashed for ?v= URLs when no manifest exists
+	FingerprintExtensions []string // Default: the assets package default list
+
+	// Asset manifest location within the embedded web filesystem, tried before
+	// the built-in candidates (e.g. static/build/manifest.json)
+	AssetManifestPath string
+
+	// Public absolute URL of the site (e.g. https://guitar-specs.example.com), used for
+	// absolute links such as the robots.txt sitemap; the bind address is not public
+	PublicBaseURL string
+
+	// Canonical host name (e.g. guitar-specs.example.com); requests for any other
+	// host are redirected there with a 301 over HTTPS
+	CanonicalHost string
+
+	// Origins allowed to read detailed Resource Timing for static assets, e.g. the
+	// site origin when assets come from a CDN; "*" allows any (default: none)
+	TimingAllowOrigins []string
 
 	// Security options
-	TrustedProxies []string // List of trusted proxy IPs for RealIP middleware
+	TrustedProxies   []string // List of trusted proxy IPs for RealIP middleware
+	RealIPHeaders    []string // Ordered client IP headers trusted from those proxies (default: middleware list)
+	RealIPMaxEntries int      // X-Forwarded-For style lists longer than this are ignored (default: 16)
+
+	// Content Security Policy allowlists (merged with 'self' and the nonce)
+	CSPScriptSrc  []string // Extra script-src sources
+	CSPStyleSrc   []string // Extra style-src sources
+	CSPImgSrc     []string // Extra img-src sources
+	CSPFontSrc    []string // Extra font-src sources
+	CSPConnectSrc []string // Extra connect-src sources
+
+	CSPInlineScriptHashes bool // Add hashes of static inline template scripts to script-src (default: false)
+
+	// Permissions-Policy as "feature=source source;feature=", e.g.
+	// "camera=self https://scanner.example.com;geolocation=" (default: "", middleware default)
+	PermissionsPolicy string
+
+	// security.txt (RFC 9116), served at /.well-known/security.txt when a contact is set
+	SecurityTxtContact []string // Contact URIs (mailto:, https:)
+	SecurityTxtExpires string   // Expiry date in RFC3339 format (required with a contact)
+	SecurityTxtPolicy  string   // Optional URL of the disclosure policy
 
 	// Logging configuration
-	LogLevel string // Log level for runtime (default: info)
+	LogLevel     string // Log level for runtime (default: info)
+	LogFile      string // Also write JSON logs to this file (default: "", stdout only)
+	LogFileLevel string // Log level for LogFile (default: debug)
+
+	// Path prefixes logged at debug instead of info, e.g. health probes
+	// (default: /healthz, /readyz, /metrics)
+	LogExcludePaths []string
+
+	// Debug body logging (request/response bodies for allowlisted paths only)
+	DebugBodyPaths    []string // Exact paths whose bodies are logged at debug level
+	DebugBodyMaxBytes int      // Maximum bytes logged per body (default: 4096)
+
+	// Debug endpoints under /debug/ (never enable on a public deployment)
+	EnableDebug bool // Route diagnostic endpoints such as /debug/asset and /debug/routes (default: false)
+
+	// User uploads served from disk under /uploads/
+	UploadsDir string // Directory served at /uploads/ (default: "", disabled)
+
+	// Sampled per-request allocation profiling (ReadMemStats stops the world briefly)
+	ProfileRequests      bool          // Log heavy sampled requests (default: false)
+	ProfileSampleRate    int           // Measure one request in N (default: 100)
+	ProfileMinAllocBytes int           // Report requests allocating at least this many bytes (default: 1MB)
+	ProfileMinDuration   time.Duration // Report requests taking at least this long (default: 500ms)
 }
 
 // ValidateHTTPS ensures HTTPS configuration is valid.
 // This function checks that certificate and key files exist, are readable, and are in PEM format.
 func (c *AppConfig) ValidateHTTPS() error {
 	if c.CertFile == "" {
-		return fmt.Errorf("SSL_CERT_FILE not specified")
+		return fieldError("SSL_CERT_FILE", "not specified")
 	}
 
 	if c.KeyFile == "" {
-		return fmt.Errorf("SSL_KEY_FILE not specified")
+		return fieldError("SSL_KEY_FILE", "not specified")
 	}
 
 	// Check if certificate file exists and is readable
 	if _, err := os.Stat(c.CertFile); os.IsNotExist(err) {
-		return fmt.Errorf("SSL certificate file not found: %s", c.CertFile)
+		return fieldError("SSL_CERT_FILE", "certificate file not found: %s", c.CertFile)
 	}
 
 	// Check if private key file exists and is readable
 	if _, err := os.Stat(c.KeyFile); os.IsNotExist(err) {
-		return fmt.Errorf("SSL private key file not found: %s", c.KeyFile)
+		return fieldError("SSL_KEY_FILE", "private key file not found: %s", c.KeyFile)
+	}
+
+	return nil
+}
+
+// ValidatePermissionsPolicy ensures the Permissions-Policy setting parses and
+// renders as a valid header.
+func (c *AppConfig) ValidatePermissionsPolicy() error {
+	if _, err := permissionspolicy.Header(c.PermissionsPolicy); err != nil {
+		return fieldError("PERMISSIONS_POLICY", "%v", err)
+	}
+	return nil
+}
+
+// ValidateCSP ensures every configured CSP source looks like a valid source expression.
+// Accepted forms are quoted keywords ('none', 'sha256-...'), schemes (https:) and hosts
+// with an optional scheme, wildcard subdomain, port and path. Every invalid source is
+// reported, joined into one error.
+func (c *AppConfig) ValidateCSP() error {
+	lists := []struct {
+		key     string
+		sources []string
+	}{
+		{"CSP_SCRIPT_SRC", c.CSPScriptSrc},
+		{"CSP_STYLE_SRC", c.CSPStyleSrc},
+		{"CSP_IMG_SRC", c.CSPImgSrc},
+		{"CSP_FONT_SRC", c.CSPFontSrc},
+		{"CSP_CONNECT_SRC", c.CSPConnectSrc},
+	}
+
+	var errs []error
+	for _, l := range lists {
+		for _, src := range l.sources {
+			if !isValidCSPSource(src) {
+				errs = append(errs, fieldError(l.key, "invalid source: %q", src))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidatePublicBaseURL ensures the public base URL, when set, is an absolute
+// http(s) URL with a host and without a query or fragment.
+func (c *AppConfig) ValidatePublicBaseURL() error {
+	if c.PublicBaseURL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(c.PublicBaseURL)
+	if err != nil {
+		return fieldError("PUBLIC_BASE_URL", "not a valid URL: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fieldError("PUBLIC_BASE_URL", "must use http or https: %q", c.PublicBaseURL)
+	}
+	if u.Host == "" {
+		return fieldError("PUBLIC_BASE_URL", "must include a host: %q", c.PublicBaseURL)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fieldError("PUBLIC_BASE_URL", "must not include a query or fragment: %q", c.PublicBaseURL)
+	}
+
+	return nil
+}
+
+// ValidateTimingAllowOrigins ensures each Timing-Allow-Origin entry is "*" or a
+// bare http(s) origin: scheme and host only, as browsers compare them exactly.
+func (c *AppConfig) ValidateTimingAllowOrigins() error {
+	var errs []error
+	for _, origin := range c.TimingAllowOrigins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+			u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+			errs = append(errs, fieldError("TIMING_ALLOW_ORIGIN", "must be * or an origin like https://example.com: %q", origin))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateTrustedProxies rejects, in production, trusted-proxy entries that
+// cover every address (0.0.0.0/0 or ::/0): RealIP would then believe forwarding
+// headers from any client, letting them spoof their IP.
+func (c *AppConfig) ValidateTrustedProxies() error {
+	if c.Env != "production" {
+		return nil
+	}
+	var errs []error
+	for _, proxy := range c.TrustedProxies {
+		if trustsEveryAddress(proxy) {
+			errs = append(errs, fieldError("TRUSTED_PROXIES", "trusts every address: %q; list the proxies in front of the app", proxy))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// TrustedProxyWarnings reports trusted-proxy settings that are allowed but
+// worth a second look: a catch-all entry outside production, where
+// ValidateTrustedProxies lets it through, and an empty list, which means no
+// forwarding header is ever trusted and may or may not be intended.
+func (c *AppConfig) TrustedProxyWarnings() []*ConfigError {
+	if len(c.TrustedProxies) == 0 {
+		return []*ConfigError{fieldError("TRUSTED_PROXIES", "empty: forwarding headers are ignored and client IPs are the connecting address")}
+	}
+	if c.Env == "production" {
+		return nil
+	}
+	var warnings []*ConfigError
+	for _, proxy := range c.TrustedProxies {
+		if trustsEveryAddress(proxy) {
+			warnings = append(warnings, fieldError("TRUSTED_PROXIES", "trusts every address: %q; any client can spoof its IP", proxy))
+		}
+	}
+	return warnings
+}
+
+// LogLevelWarnings reports LOG_LEVEL and LOG_FILE_LEVEL values the logger does
+// not recognise. They are not fatal, since the logger falls back to info, but
+// a typo such as "debugg" would otherwise go unnoticed.
+func (c *AppConfig) LogLevelWarnings() []*ConfigError {
+	var warnings []*ConfigError
+	for _, setting := range []struct{ field, value string }{
+		{"LOG_LEVEL", c.LogLevel},
+		{"LOG_FILE_LEVEL", c.LogFileLevel},
+	} {
+		if !logging.KnownLevel(setting.value) {
+			warnings = append(warnings, fieldError(setting.field, "unknown level %q, using info; accepted: %s", setting.value, strings.Join(logging.Levels, ", ")))
+		}
+	}
+	return warnings
+}
+
+// trustsEveryAddress reports whether a trusted-proxy entry is a zero-length
+// prefix such as 0.0.0.0/0 or ::/0.
+func trustsEveryAddress(proxy string) bool {
+	prefix, err := netip.ParsePrefix(proxy)
+	return err == nil && prefix.Bits() == 0
+}
+
+// ValidateCanonicalHost ensures the canonical host, when set, is a bare host[:port].
+func (c *AppConfig) ValidateCanonicalHost() error {
+	if c.CanonicalHost == "" {
+		return nil
+	}
+
+	// A bare host[:port]; a scheme or path would produce a broken redirect loop
+	if strings.ContainsAny(c.CanonicalHost, "/?#@ ") {
+		return fieldError("CANONICAL_HOST", "must be a host name without scheme or path: %q", c.CanonicalHost)
+	}
+	return nil
+}
+
+// ValidateHTMLCache checks the stale-* extensions for rendered pages. They are
+// sent in whole seconds and only extend a freshness lifetime, so they need
+// HTML_CACHE_CONTROL to carry max-age or s-maxage and not forbid storing.
+func (c *AppConfig) ValidateHTMLCache() error {
+	var errs []error
+	stale := false
+	for _, d := range []struct {
+		field string
+		value time.Duration
+	}{
+		{"HTML_STALE_WHILE_REVALIDATE", c.HTMLStaleWhileRevalidate},
+		{"HTML_STALE_IF_ERROR", c.HTMLStaleIfError},
+	} {
+		switch {
+		case d.value < 0:
+			errs = append(errs, fieldError(d.field, "must not be negative: %s", d.value))
+		case d.value%time.Second != 0:
+			errs = append(errs, fieldError(d.field, "must be whole seconds: %s", d.value))
+		case d.value > 0:
+			stale = true
+		}
+	}
+
+	if stale {
+		policy := strings.ToLower(c.HTMLCacheControl)
+		if strings.Contains(policy, "no-store") || !(strings.Contains(policy, "max-age=") || strings.Contains(policy, "s-maxage=")) {
+			errs = append(errs, fieldError("HTML_CACHE_CONTROL", "stale-* directives need a max-age or s-maxage without no-store: %q", c.HTMLCacheControl))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateSecurityTxt ensures a configured security.txt has a valid RFC3339 expiry date.
+// Without a contact the file is not served, so nothing is checked.
+func (c *AppConfig) ValidateSecurityTxt() error {
+	if len(c.SecurityTxtContact) == 0 {
+		return nil
+	}
+
+	if c.SecurityTxtExpires == "" {
+		return fieldError("SECURITY_TXT_EXPIRES", "required when SECURITY_TXT_CONTACT is set")
+	}
+
+	if _, err := time.Parse(time.RFC3339, c.SecurityTxtExpires); err != nil {
+		return fieldError("SECURITY_TXT_EXPIRES", "not a valid RFC3339 date: %q", c.SecurityTxtExpires)
 	}
 
 	return nil
@@ -77,45 +401,152 @@ func (c *AppConfig) Addr() string {
 // It loads configuration from environment variables with sensible defaults.
 func New() ConfigProvider {
 	// Load .env file first to populate environment variables
-	loadEnvFile()
+	l := newLoader(loadEnvFile())
 
 	cfg := &AppConfig{
-		Host: getenv("HOST", "0.0.0.0"),    // Bind to all network interfaces
-		Port: getenv("PORT", "8443"),       // Default to HTTPS port
-		Env:  getenv("ENV", "development"), // Default to development mode
+		Host: l.getenv("HOST", "0.0.0.0"),    // Bind to all network interfaces
+		Port: l.getenv("PORT", "8443"),       // Default to HTTPS port
+		Env:  l.getenv("ENV", "development"), // Default to development mode
 
 		// SSL Configuration
-		CertFile: getenv("SSL_CERT_FILE", ""), // SSL certificate file path
-		KeyFile:  getenv("SSL_KEY_FILE", ""),  // SSL private key file path
+		CertFile: l.getenv("SSL_CERT_FILE", ""), // SSL certificate file path
+		KeyFile:  l.getenv("SSL_KEY_FILE", ""),  // SSL private key file path
 
 		// Database (split parameters)
-		DBHost:     getenv("DB_HOST", ""),
-		DBPort:     getenv("DB_PORT", "5432"),
-		DBUser:     getenv("DB_USER", ""),
-		DBPassword: getenv("DB_PASSWORD", ""),
-		DBName:     getenv("DB_NAME", ""),
-		DBSSLMode:  getenv("DB_SSLMODE", "disable"),
+		DBHost:     l.getenv("DB_HOST", ""),
+		DBPort:     l.getenv("DB_PORT", "5432"),
+		DBUser:     l.getenv("DB_USER", ""),
+		DBPassword: l.getenv("DB_PASSWORD", ""),
+		DBName:     l.getenv("DB_NAME", ""),
+		DBSSLMode:  l.getenv("DB_SSLMODE", "disable"),
+
+		// Server-side DSN limits
+		DBConnectTimeout:   l.getDuration("DB_CONNECT_TIMEOUT", 5*time.Second),
+		DBStatementTimeout: l.getDuration("DB_STATEMENT_TIMEOUT", 30*time.Second),
+
+		// Connection pool sizing
+		DBMinConns:   l.getInt("DB_MIN_CONNS", 0),
+		DBPoolWarmUp: l.getBool("DB_POOL_WARMUP", false),
+
+		// Startup connection retries
+		DBStartupRetries:    l.getInt("DB_STARTUP_RETRIES", 0),
+		DBStartupRetryDelay: l.getDuration("DB_STARTUP_RETRY_DELAY", time.Second),
+
+		// Per-query-type safety timeouts
+		DBListTimeout:     l.getDuration("DB_LIST_TIMEOUT", 5*time.Second),
+		DBGetTimeout:      l.getDuration("DB_GET_TIMEOUT", 5*time.Second),
+		DBFeaturesTimeout: l.getDuration("DB_FEATURES_TIMEOUT", 5*time.Second),
+
+		// Startup warm-up
+		Warmup:         l.getBool("WARMUP", false),
+		WarmupTimeout:  l.getDuration("WARMUP_TIMEOUT", 3*time.Second),
+		TemplateWarmup: l.getBool("TEMPLATE_WARMUP", false),
+
+		// Connection pool visibility
+		PoolStatsInterval: l.getDuration("POOL_STATS_INTERVAL", 0),
+
+		// Degraded reads
+		StaleOnError: l.getBool("STALE_ON_ERROR", false),
+
+		// Maintenance
+		ReadOnly: l.getBool("READ_ONLY", false),
 
 		// Advanced configuration options
-		ReadTimeout:       getDuration("READ_TIMEOUT", 10*time.Second),
-		WriteTimeout:      getDuration("WRITE_TIMEOUT", 30*time.Second),
-		IdleTimeout:       getDuration("IDLE_TIMEOUT", 60*time.Second),
-		ReadHeaderTimeout: getDuration("READ_HEADER_TIMEOUT", 5*time.Second),
-		MaxHeaderBytes:    getInt("MAX_HEADER_BYTES", 1<<20), // 1MB
+		ReadTimeout:       l.getDuration("READ_TIMEOUT", 10*time.Second),
+		WriteTimeout:      l.getDuration("WRITE_TIMEOUT", 30*time.Second),
+		IdleTimeout:       l.getDuration("IDLE_TIMEOUT", 60*time.Second),
+		ReadHeaderTimeout: l.getDuration("READ_HEADER_TIMEOUT", 5*time.Second),
+		MaxHeaderBytes:    l.getInt("MAX_HEADER_BYTES", 1<<20), // 1MB
+		MaxHeaders:        l.getInt("MAX_HEADERS", 100),
+		MaxURLLength:      l.getInt("MAX_URL_LENGTH", 8192),
+
+		// Concurrency limiting
+		MaxConcurrentRequests:   l.getInt("MAX_CONCURRENT_REQUESTS", 0),
+		ConcurrencyQueueTimeout: l.getDuration("CONCURRENCY_QUEUE_TIMEOUT", 0),
+		MaxConcurrentPerIP:      l.getInt("MAX_CONCURRENT_PER_IP", 0),
+
+		// Response compression and validators
+		Compress:      l.getBool("COMPRESS", false),
+		CompressLevel: l.getInt("COMPRESS_LEVEL", 5),
+		ETags:         l.getBool("ETAGS", false),
+		MicroCacheTTL: l.getDuration("MICROCACHE_TTL", 0),
+
+		ServerTiming: l.getBool("SERVER_TIMING", false),
+
+		FingerprintExtensions: l.getStringSlice("FINGERPRINT_EXTENSIONS", nil),
+		AssetManifestPath:     l.getenv("ASSET_MANIFEST_PATH", ""),
+
+		PublicBaseURL: l.getenv("PUBLIC_BASE_URL", ""),
+		CanonicalHost: l.getenv("CANONICAL_HOST", ""),
+
+		TimingAllowOrigins: l.getStringSlice("TIMING_ALLOW_ORIGIN", nil),
 
 		// Security options
-		TrustedProxies: getStringSlice("TRUSTED_PROXIES", []string{"127.0.0.1", "::1"}),
+		TrustedProxies:   l.getStringSlice("TRUSTED_PROXIES", []string{"127.0.0.1", "::1"}),
+		RealIPHeaders:    l.getStringSlice("REAL_IP_HEADERS", nil),
+		RealIPMaxEntries: l.getInt("REAL_IP_MAX_ENTRIES", 16),
+
+		// Content Security Policy allowlists
+		CSPScriptSrc:  l.getStringSlice("CSP_SCRIPT_SRC", nil),
+		CSPStyleSrc:   l.getStringSlice("CSP_STYLE_SRC", nil),
+		CSPImgSrc:     l.getStringSlice("CSP_IMG_SRC", nil),
+		CSPFontSrc:    l.getStringSlice("CSP_FONT_SRC", nil),
+		CSPConnectSrc: l.getStringSlice("CSP_CONNECT_SRC", nil),
+
+		CSPInlineScriptHashes: l.getBool("CSP_INLINE_SCRIPT_HASHES", false),
+
+		PermissionsPolicy: l.getenv("PERMISSIONS_POLICY", ""),
+
+		// security.txt
+		SecurityTxtContact: l.getStringSlice("SECURITY_TXT_CONTACT", nil),
+		SecurityTxtExpires: l.getenv("SECURITY_TXT_EXPIRES", ""),
+		SecurityTxtPolicy:  l.getenv("SECURITY_TXT_POLICY", ""),
 
 		// Logging configuration
-		LogLevel: getenv("LOG_LEVEL", "info"),
+		LogLevel:     l.getenv("LOG_LEVEL", "info"),
+		LogFile:      l.getenv("LOG_FILE", ""),
+		LogFileLevel: l.getenv("LOG_FILE_LEVEL", "debug"),
+
+		LogExcludePaths: l.getStringSlice("LOG_EXCLUDE_PATHS", []string{"/healthz", "/readyz", "/metrics"}),
+
+		// Debug body logging
+		DebugBodyPaths:    l.getStringSlice("DEBUG_BODY_PATHS", nil),
+		DebugBodyMaxBytes: l.getInt("DEBUG_BODY_MAX_BYTES", 4096),
+
+		// Debug endpoints
+		EnableDebug: l.getBool("ENABLE_DEBUG", false),
+
+		// User uploads
+		UploadsDir: l.getenv("UPLOADS_DIR", ""),
+
+		// Sampled request profiling
+		ProfileRequests:      l.getBool("ENABLE_PROFILE_REQUESTS", false),
+		ProfileSampleRate:    l.getInt("PROFILE_SAMPLE_RATE", 100),
+		ProfileMinAllocBytes: l.getInt("PROFILE_MIN_ALLOC_BYTES", 1<<20),
+		ProfileMinDuration:   l.getDuration("PROFILE_MIN_DURATION", 500*time.Millisecond),
 	}
 
-	return &configProvider{config: cfg}
+	// The page cache default depends on the environment read above
+	cfg.HTMLCacheControl = l.getenv("HTML_CACHE_CONTROL", defaultHTMLCacheControl(cfg.Env))
+	cfg.HTMLStaleWhileRevalidate = l.getDuration("HTML_STALE_WHILE_REVALIDATE", 0)
+	cfg.HTMLStaleIfError = l.getDuration("HTML_STALE_IF_ERROR", 0)
+
+	return &configProvider{config: cfg, sources: l.sorted()}
+}
+
+// defaultHTMLCacheControl lets production caches hold the mostly-static catalogue
+// briefly, while every other environment revalidates so edits show up at once.
+func defaultHTMLCacheControl(env string) string {
+	if env == "production" {
+		return "public, max-age=60"
+	}
+	return "no-cache"
 }
 
 // configProvider implements ConfigProvider interface
 type configProvider struct {
-	config *AppConfig
+	config  *AppConfig
+	sources []Source
 }
 
 // Get returns the configuration struct
@@ -123,9 +554,25 @@ func (c *configProvider) Get() *AppConfig {
 	return c.config
 }
 
-// Validate performs configuration validation and returns any errors
+// Validate runs every check and returns all failures joined into one error;
+// ValidationErrors splits it into the individual ConfigErrors
 func (c *configProvider) Validate() error {
-	return c.config.ValidateHTTPS()
+	return errors.Join(
+		c.config.ValidateHTTPS(),
+		c.config.ValidateCSP(),
+		c.config.ValidatePermissionsPolicy(),
+		c.config.ValidatePublicBaseURL(),
+		c.config.ValidateCanonicalHost(),
+		c.config.ValidateSecurityTxt(),
+		c.config.ValidateHTMLCache(),
+		c.config.ValidateTimingAllowOrigins(),
+		c.config.ValidateTrustedProxies(),
+	)
+}
+
+// Warnings reports settings that are valid but likely misconfigured.
+func (c *configProvider) Warnings() []*ConfigError {
+	return append(c.config.TrustedProxyWarnings(), c.config.LogLevelWarnings()...)
 }
 
 // GetString returns a string configuration value by key
@@ -153,8 +600,28 @@ func (c *configProvider) GetString(key string) string {
 		return c.config.DBName
 	case "DB_SSLMODE":
 		return c.config.DBSSLMode
+	case "HTML_CACHE_CONTROL":
+		return c.config.HTMLCacheControl
 	case "LOG_LEVEL":
 		return c.config.LogLevel
+	case "UPLOADS_DIR":
+		return c.config.UploadsDir
+	case "LOG_FILE":
+		return c.config.LogFile
+	case "PERMISSIONS_POLICY":
+		return c.config.PermissionsPolicy
+	case "LOG_FILE_LEVEL":
+		return c.config.LogFileLevel
+	case "ASSET_MANIFEST_PATH":
+		return c.config.AssetManifestPath
+	case "PUBLIC_BASE_URL":
+		return c.config.PublicBaseURL
+	case "CANONICAL_HOST":
+		return c.config.CanonicalHost
+	case "SECURITY_TXT_EXPIRES":
+		return c.config.SecurityTxtExpires
+	case "SECURITY_TXT_POLICY":
+		return c.config.SecurityTxtPolicy
 	default:
 		return ""
 	}
@@ -165,6 +632,28 @@ func (c *configProvider) GetInt(key string) int {
 	switch key {
 	case "MAX_HEADER_BYTES":
 		return c.config.MaxHeaderBytes
+	case "PROFILE_SAMPLE_RATE":
+		return c.config.ProfileSampleRate
+	case "PROFILE_MIN_ALLOC_BYTES":
+		return c.config.ProfileMinAllocBytes
+	case "DB_STARTUP_RETRIES":
+		return c.config.DBStartupRetries
+	case "DB_MIN_CONNS":
+		return c.config.DBMinConns
+	case "MAX_HEADERS":
+		return c.config.MaxHeaders
+	case "MAX_URL_LENGTH":
+		return c.config.MaxURLLength
+	case "DEBUG_BODY_MAX_BYTES":
+		return c.config.DebugBodyMaxBytes
+	case "MAX_CONCURRENT_REQUESTS":
+		return c.config.MaxConcurrentRequests
+	case "REAL_IP_MAX_ENTRIES":
+		return c.config.RealIPMaxEntries
+	case "MAX_CONCURRENT_PER_IP":
+		return c.config.MaxConcurrentPerIP
+	case "COMPRESS_LEVEL":
+		return c.config.CompressLevel
 	default:
 		return 0
 	}
@@ -181,6 +670,32 @@ func (c *configProvider) GetDuration(key string) time.Duration {
 		return c.config.IdleTimeout
 	case "READ_HEADER_TIMEOUT":
 		return c.config.ReadHeaderTimeout
+	case "CONCURRENCY_QUEUE_TIMEOUT":
+		return c.config.ConcurrencyQueueTimeout
+	case "PROFILE_MIN_DURATION":
+		return c.config.ProfileMinDuration
+	case "DB_STARTUP_RETRY_DELAY":
+		return c.config.DBStartupRetryDelay
+	case "DB_CONNECT_TIMEOUT":
+		return c.config.DBConnectTimeout
+	case "DB_STATEMENT_TIMEOUT":
+		return c.config.DBStatementTimeout
+	case "DB_LIST_TIMEOUT":
+		return c.config.DBListTimeout
+	case "DB_GET_TIMEOUT":
+		return c.config.DBGetTimeout
+	case "DB_FEATURES_TIMEOUT":
+		return c.config.DBFeaturesTimeout
+	case "WARMUP_TIMEOUT":
+		return c.config.WarmupTimeout
+	case "MICROCACHE_TTL":
+		return c.config.MicroCacheTTL
+	case "HTML_STALE_WHILE_REVALIDATE":
+		return c.config.HTMLStaleWhileRevalidate
+	case "HTML_STALE_IF_ERROR":
+		return c.config.HTMLStaleIfError
+	case "POOL_STATS_INTERVAL":
+		return c.config.PoolStatsInterval
 	default:
 		return 0
 	}
@@ -189,8 +704,30 @@ func (c *configProvider) GetDuration(key string) time.Duration {
 // GetStringSlice returns a string slice configuration value by key
 func (c *configProvider) GetStringSlice(key string) []string {
 	switch key {
+	case "TIMING_ALLOW_ORIGIN":
+		return c.config.TimingAllowOrigins
 	case "TRUSTED_PROXIES":
 		return c.config.TrustedProxies
+	case "REAL_IP_HEADERS":
+		return c.config.RealIPHeaders
+	case "CSP_SCRIPT_SRC":
+		return c.config.CSPScriptSrc
+	case "CSP_STYLE_SRC":
+		return c.config.CSPStyleSrc
+	case "LOG_EXCLUDE_PATHS":
+		return c.config.LogExcludePaths
+	case "CSP_IMG_SRC":
+		return c.config.CSPImgSrc
+	case "CSP_FONT_SRC":
+		return c.config.CSPFontSrc
+	case "CSP_CONNECT_SRC":
+		return c.config.CSPConnectSrc
+	case "SECURITY_TXT_CONTACT":
+		return c.config.SecurityTxtContact
+	case "FINGERPRINT_EXTENSIONS":
+		return c.config.FingerprintExtensions
+	case "DEBUG_BODY_PATHS":
+		return c.config.DebugBodyPaths
 	default:
 		return nil
 	}
@@ -199,22 +736,27 @@ func (c *configProvider) GetStringSlice(key string) []string {
 // Helper functions
 
 // loadEnvFile loads environment variables from a .env file.
-func loadEnvFile() {
+// It returns the keys the file set, for source attribution.
+func loadEnvFile() map[string]bool {
 	// Load from .env file if it exists
-	if err := loadEnvFileFromPath(".env"); err != nil {
+	keys, err := loadEnvFileFromPath(".env")
+	if err != nil {
 		// File doesn't exist or can't be read - this is normal
 		// Environment variables can still be set via system or command line
+		return nil
 	}
+	return keys
 }
 
 // loadEnvFileFromPath loads environment variables from a specific .env file.
-func loadEnvFileFromPath(filename string) error {
+func loadEnvFileFromPath(filename string) (map[string]bool, error) {
 	file, err := os.Open(filename)
 	if err != nil {
-		return err // File doesn't exist or can't be opened
+		return nil, err // File doesn't exist or can't be opened
 	}
 	defer file.Close()
 
+	keys := make(map[string]bool)
 	scanner := bufio.NewScanner(file)
 
 	for scanner.Scan() {
@@ -241,43 +783,114 @@ func loadEnvFileFromPath(filename string) error {
 
 		// Set environment variable
 		os.Setenv(key, value)
+		keys[key] = true
 	}
 
-	return scanner.Err()
+	return keys, scanner.Err()
 }
 
 // getenv retrieves an environment variable with a fallback default value.
-func getenv(k, def string) string {
+func (l *loader) getenv(k, def string) string {
 	if v := os.Getenv(k); v != "" {
+		l.record(k, v, true)
 		return v
 	}
+	l.record(k, def, false)
 	return def
 }
 
 // getInt retrieves an integer environment variable with a fallback default value.
-func getInt(k string, def int) int {
+func (l *loader) getInt(k string, def int) int {
 	if v := os.Getenv(k); v != "" {
 		if i, err := strconv.Atoi(v); err == nil {
+			l.record(k, v, true)
 			return i
 		}
+		l.recordInvalid(k, v)
+		return def
 	}
+	l.record(k, strconv.Itoa(def), false)
+	return def
+}
+
+// getBool retrieves a boolean environment variable with a fallback default value.
+func (l *loader) getBool(k string, def bool) bool {
+	if v := os.Getenv(k); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			l.record(k, v, true)
+			return b
+		}
+		l.recordInvalid(k, v)
+		return def
+	}
+	l.record(k, strconv.FormatBool(def), false)
 	return def
 }
 
 // getDuration retrieves a duration environment variable with a fallback default value.
-func getDuration(k string, def time.Duration) time.Duration {
+func (l *loader) getDuration(k string, def time.Duration) time.Duration {
 	if v := os.Getenv(k); v != "" {
 		if d, err := time.ParseDuration(v); err == nil {
+			l.record(k, v, true)
 			return d
 		}
+		l.recordInvalid(k, v)
+		return def
 	}
+	l.record(k, def.String(), false)
 	return def
 }
 
 // getStringSlice retrieves a string slice environment variable with a fallback default value.
-func getStringSlice(k string, def []string) []string {
+// Items are trimmed and empty entries are dropped so "a, b," yields ["a", "b"].
+func (l *loader) getStringSlice(k string, def []string) []string {
 	if v := os.Getenv(k); v != "" {
-		return strings.Split(v, ",")
+		parts := strings.Split(v, ",")
+		out := make([]string, 0, len(parts))
+		for _, p := range parts {
+			if p = strings.TrimSpace(p); p != "" {
+				out = append(out, p)
+			}
+		}
+		l.record(k, v, true)
+		return out
 	}
+	l.record(k, strings.Join(def, ","), false)
 	return def
 }
+
+// isValidCSPSource reports whether s is a plausible CSP source expression.
+func isValidCSPSource(s string) bool {
+	if s == "" || strings.ContainsAny(s, " ;,") {
+		return false
+	}
+
+	// Quoted keywords and hashes/nonces: 'self', 'none', 'sha256-...'
+	if strings.HasPrefix(s, "'") {
+		return len(s) > 2 && strings.HasSuffix(s, "'")
+	}
+
+	// Bare scheme sources: https:, data:, blob:, wss:
+	if strings.HasSuffix(s, ":") {
+		scheme := strings.TrimSuffix(s, ":")
+		return scheme != "" && !strings.ContainsAny(scheme, "/.")
+	}
+
+	// Host sources, optionally with a scheme; parse with a placeholder scheme
+	// so "cdn.example.com" and "*.example.com" are handled uniformly.
+	raw := s
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	host := raw[strings.Index(raw, "://")+3:]
+	wildcard := strings.HasPrefix(host, "*.")
+	if wildcard {
+		raw = strings.Replace(raw, "://*.", "://", 1)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+	return !strings.Contains(u.Hostname(), "*")
+}
@@ -1,7 +1,12 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"log/slog"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -106,6 +111,13 @@ func TestConfigProvider_GetDuration(t *testing.T) {
 	if writeTimeout != 30*time.Second {
 		t.Errorf("Expected WRITE_TIMEOUT 30s, got %v", writeTimeout)
 	}
+
+	if d := cfg.GetDuration("DB_CONNECT_TIMEOUT"); d != 5*time.Second {
+		t.Errorf("Expected DB_CONNECT_TIMEOUT 5s, got %v", d)
+	}
+	if d := cfg.GetDuration("DB_STATEMENT_TIMEOUT"); d != 30*time.Second {
+		t.Errorf("Expected DB_STATEMENT_TIMEOUT 30s, got %v", d)
+	}
 }
 
 func TestConfigProvider_GetInt(t *testing.T) {
@@ -132,3 +144,446 @@ func TestConfigProvider_GetStringSlice(t *testing.T) {
 		}
 	}
 }
+
+func TestAppConfig_ValidateCSP(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources []string
+		wantErr bool
+	}{
+		{name: "empty list", sources: nil, wantErr: false},
+		{name: "https host", sources: []string{"https://images.example.com"}, wantErr: false},
+		{name: "bare host", sources: []string{"cdn.example.com"}, wantErr: false},
+		{name: "wildcard subdomain", sources: []string{"https://*.example.com"}, wantErr: false},
+		{name: "host with port and path", sources: []string{"https://example.com:8443/assets/"}, wantErr: false},
+		{name: "scheme source", sources: []string{"data:", "blob:"}, wantErr: false},
+		{name: "quoted keyword", sources: []string{"'none'"}, wantErr: false},
+		{name: "contains whitespace", sources: []string{"https://a.com https://b.com"}, wantErr: true},
+		{name: "directive injection", sources: []string{"https://a.com;script-src"}, wantErr: true},
+		{name: "unterminated quote", sources: []string{"'self"}, wantErr: true},
+		{name: "scheme without host", sources: []string{"https://"}, wantErr: true},
+		{name: "misplaced wildcard", sources: []string{"https://cdn.*.com"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &AppConfig{CSPImgSrc: tt.sources}
+			err := cfg.ValidateCSP()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCSP() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigProvider_ValidateFields(t *testing.T) {
+	provider := &configProvider{config: &AppConfig{
+		CSPImgSrc:          []string{"https://a.com;script-src", "https://images.example.com", "'self"},
+		PublicBaseURL:      "ftp://example.com",
+		CanonicalHost:      "example.com",
+		SecurityTxtContact: []string{"mailto:security@example.com"},
+	}}
+
+	err := provider.Validate()
+	if err == nil {
+		t.Fatal("Expected validation to fail")
+	}
+
+	var fields []string
+	for _, fieldErr := range ValidationErrors(err) {
+		if fieldErr.Reason == "" {
+			t.Errorf("Expected a reason for %s", fieldErr.Field)
+		}
+		fields = append(fields, fieldErr.Field)
+	}
+
+	expected := []string{"SSL_CERT_FILE", "CSP_IMG_SRC", "CSP_IMG_SRC", "PUBLIC_BASE_URL", "SECURITY_TXT_EXPIRES"}
+	if !slices.Equal(fields, expected) {
+		t.Errorf("Expected invalid fields %v, got %v", expected, fields)
+	}
+
+	var ce *ConfigError
+	if !errors.As(err, &ce) || ce.Field != "SSL_CERT_FILE" {
+		t.Errorf("Expected errors.As to find the first ConfigError, got %v", ce)
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	single := fieldError("CANONICAL_HOST", "must be a host name")
+	if got := ValidationErrors(single); len(got) != 1 || got[0] != single {
+		t.Errorf("Expected the single ConfigError, got %v", got)
+	}
+	if got := ValidationErrors(nil); got != nil {
+		t.Errorf("Expected nil for no error, got %v", got)
+	}
+	if got := ValidationErrors(errors.New("plain")); got != nil {
+		t.Errorf("Expected plain errors to be skipped, got %v", got)
+	}
+	if got := single.Error(); got != "CANONICAL_HOST: must be a host name" {
+		t.Errorf("Expected 'FIELD: reason' message, got '%s'", got)
+	}
+}
+
+func TestConfigProvider_CSPSources(t *testing.T) {
+	original := os.Getenv("CSP_IMG_SRC")
+	defer func() {
+		if original != "" {
+			os.Setenv("CSP_IMG_SRC", original)
+		} else {
+			os.Unsetenv("CSP_IMG_SRC")
+		}
+	}()
+
+	os.Setenv("CSP_IMG_SRC", "https://images.example.com, https://cdn.example.com")
+	cfg := New()
+
+	sources := cfg.GetStringSlice("CSP_IMG_SRC")
+	expected := []string{"https://images.example.com", "https://cdn.example.com"}
+	if len(sources) != len(expected) {
+		t.Fatalf("Expected %d img sources, got %d (%v)", len(expected), len(sources), sources)
+	}
+	for i, src := range expected {
+		if sources[i] != src {
+			t.Errorf("Expected img source %s at index %d, got %s", src, i, sources[i])
+		}
+	}
+}
+
+func TestConfigProvider_RequestLimits(t *testing.T) {
+	cfg := New()
+
+	if maxHeaders := cfg.GetInt("MAX_HEADERS"); maxHeaders != 100 {
+		t.Errorf("Expected MAX_HEADERS 100, got %d", maxHeaders)
+	}
+
+	if maxURLLength := cfg.GetInt("MAX_URL_LENGTH"); maxURLLength != 8192 {
+		t.Errorf("Expected MAX_URL_LENGTH 8192, got %d", maxURLLength)
+	}
+}
+
+func TestNew_Compress(t *testing.T) {
+	t.Setenv("COMPRESS", "")
+	t.Setenv("COMPRESS_LEVEL", "")
+	if c := New().(*configProvider).config; c.Compress || c.CompressLevel != 5 {
+		t.Errorf("Expected compression off with level 5, got %v and %d", c.Compress, c.CompressLevel)
+	}
+
+	// Brotli quality 0 is a level like any other, not a way to switch off
+	t.Setenv("COMPRESS", "true")
+	t.Setenv("COMPRESS_LEVEL", "0")
+	if c := New().(*configProvider).config; !c.Compress || c.CompressLevel != 0 {
+		t.Errorf("Expected compression on with level 0, got %v and %d", c.Compress, c.CompressLevel)
+	}
+}
+
+func TestAppConfig_ValidateSecurityTxt(t *testing.T) {
+	tests := []struct {
+		name    string
+		contact []string
+		expires string
+		wantErr bool
+	}{
+		{name: "not configured", contact: nil, expires: "", wantErr: false},
+		{name: "valid expiry", contact: []string{"mailto:security@example.com"}, expires: "2027-01-01T00:00:00Z", wantErr: false},
+		{name: "expiry with offset", contact: []string{"mailto:security@example.com"}, expires: "2027-01-01T00:00:00+02:00", wantErr: false},
+		{name: "missing expiry", contact: []string{"mailto:security@example.com"}, expires: "", wantErr: true},
+		{name: "date only", contact: []string{"mailto:security@example.com"}, expires: "2027-01-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &AppConfig{SecurityTxtContact: tt.contact, SecurityTxtExpires: tt.expires}
+			err := cfg.ValidateSecurityTxt()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSecurityTxt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAppConfig_ValidatePublicBaseURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		wantErr bool
+	}{
+		{name: "not configured", baseURL: "", wantErr: false},
+		{name: "https host", baseURL: "https://guitar-specs.example.com", wantErr: false},
+		{name: "with port and trailing slash", baseURL: "http://localhost:8443/", wantErr: false},
+		{name: "missing scheme", baseURL: "guitar-specs.example.com", wantErr: true},
+		{name: "unsupported scheme", baseURL: "ftp://guitar-specs.example.com", wantErr: true},
+		{name: "missing host", baseURL: "https://", wantErr: true},
+		{name: "with query", baseURL: "https://guitar-specs.example.com?a=b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &AppConfig{PublicBaseURL: tt.baseURL}
+			err := cfg.ValidatePublicBaseURL()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePublicBaseURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAppConfig_ValidateCanonicalHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		wantErr bool
+	}{
+		{name: "not configured", host: "", wantErr: false},
+		{name: "bare host", host: "guitar-specs.example.com", wantErr: false},
+		{name: "host with port", host: "localhost:8443", wantErr: false},
+		{name: "with scheme", host: "https://guitar-specs.example.com", wantErr: true},
+		{name: "with path", host: "guitar-specs.example.com/guitars", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &AppConfig{CanonicalHost: tt.host}
+			err := cfg.ValidateCanonicalHost()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCanonicalHost() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNew_HTMLCacheControl(t *testing.T) {
+	tests := []struct {
+		env      string
+		override string
+		want     string
+	}{
+		{env: "development", want: "no-cache"},
+		{env: "production", want: "public, max-age=60"},
+		{env: "production", override: "no-cache", want: "no-cache"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env+"/"+tt.override, func(t *testing.T) {
+			t.Setenv("ENV", tt.env)
+			t.Setenv("HTML_CACHE_CONTROL", tt.override)
+			if tt.override == "" {
+				os.Unsetenv("HTML_CACHE_CONTROL")
+			}
+
+			if got := New().Get().HTMLCacheControl; got != tt.want {
+				t.Errorf("Expected HTMLCacheControl '%s', got '%s'", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAppConfig_ValidateHTMLCache(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        AppConfig
+		wantFields []string
+	}{
+		{name: "no stale directives", cfg: AppConfig{HTMLCacheControl: "no-cache"}},
+		{name: "valid directives", cfg: AppConfig{HTMLCacheControl: "public, max-age=60", HTMLStaleWhileRevalidate: 300 * time.Second, HTMLStaleIfError: 24 * time.Hour}},
+		{name: "negative", cfg: AppConfig{HTMLCacheControl: "public, max-age=60", HTMLStaleWhileRevalidate: -time.Second}, wantFields: []string{"HTML_STALE_WHILE_REVALIDATE"}},
+		{name: "fractional seconds", cfg: AppConfig{HTMLCacheControl: "public, max-age=60", HTMLStaleIfError: 1500 * time.Millisecond}, wantFields: []string{"HTML_STALE_IF_ERROR"}},
+		{name: "no freshness lifetime", cfg: AppConfig{HTMLCacheControl: "no-cache", HTMLStaleWhileRevalidate: time.Minute}, wantFields: []string{"HTML_CACHE_CONTROL"}},
+		{name: "no-store", cfg: AppConfig{HTMLCacheControl: "no-store, max-age=0", HTMLStaleIfError: time.Minute}, wantFields: []string{"HTML_CACHE_CONTROL"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields []string
+			for _, fieldErr := range ValidationErrors(tt.cfg.ValidateHTMLCache()) {
+				fields = append(fields, fieldErr.Field)
+			}
+			if !slices.Equal(fields, tt.wantFields) {
+				t.Errorf("Expected invalid fields %v, got %v", tt.wantFields, fields)
+			}
+		})
+	}
+}
+
+func TestAppConfig_ValidateTimingAllowOrigins(t *testing.T) {
+	valid := &AppConfig{TimingAllowOrigins: []string{"*", "https://guitar-specs.example.com", "http://localhost:8443"}}
+	if err := valid.ValidateTimingAllowOrigins(); err != nil {
+		t.Errorf("Expected valid origins, got %v", err)
+	}
+
+	invalid := &AppConfig{TimingAllowOrigins: []string{"guitar-specs.example.com", "https://example.com/path", "ftp://example.com"}}
+	if errs := ValidationErrors(invalid.ValidateTimingAllowOrigins()); len(errs) != 3 {
+		t.Errorf("Expected 3 invalid origins, got %v", errs)
+	}
+}
+
+func TestAppConfig_ValidatePermissionsPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "unset", value: ""},
+		{name: "valid", value: "geolocation=;camera=self https://scanner.example.com"},
+		{name: "missing equals", value: "camera", wantErr: true},
+		{name: "duplicate feature", value: "camera=;camera=self", wantErr: true},
+		{name: "uppercase feature", value: "Camera=", wantErr: true},
+		{name: "wildcard with origins", value: "camera=* self", wantErr: true},
+		{name: "origin with path", value: "camera=https://example.com/scan", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &AppConfig{PermissionsPolicy: tt.value}
+			errs := ValidationErrors(cfg.ValidatePermissionsPolicy())
+			if (len(errs) > 0) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, errs)
+			}
+			if len(errs) > 0 && errs[0].Field != "PERMISSIONS_POLICY" {
+				t.Errorf("Expected PERMISSIONS_POLICY error, got %v", errs[0])
+			}
+		})
+	}
+}
+
+func TestAppConfig_ValidateTrustedProxies(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        AppConfig
+		wantFields []string
+	}{
+		{name: "loopback", cfg: AppConfig{Env: "production", TrustedProxies: []string{"127.0.0.1", "::1"}}},
+		{name: "catch-all in development", cfg: AppConfig{Env: "development", TrustedProxies: []string{"0.0.0.0/0"}}},
+		{name: "IPv4 catch-all in production", cfg: AppConfig{Env: "production", TrustedProxies: []string{"10.0.0.1", "0.0.0.0/0"}}, wantFields: []string{"TRUSTED_PROXIES"}},
+		{name: "IPv6 catch-all in production", cfg: AppConfig{Env: "production", TrustedProxies: []string{"::/0"}}, wantFields: []string{"TRUSTED_PROXIES"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields []string
+			for _, fieldErr := range ValidationErrors(tt.cfg.ValidateTrustedProxies()) {
+				fields = append(fields, fieldErr.Field)
+			}
+			if !slices.Equal(fields, tt.wantFields) {
+				t.Errorf("Expected invalid fields %v, got %v", tt.wantFields, fields)
+			}
+		})
+	}
+}
+
+func TestAppConfig_TrustedProxyWarnings(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          AppConfig
+		wantWarnings int
+	}{
+		{name: "loopback", cfg: AppConfig{Env: "development", TrustedProxies: []string{"127.0.0.1", "::1"}}},
+		{name: "empty", cfg: AppConfig{Env: "production"}, wantWarnings: 1},
+		{name: "catch-all in development", cfg: AppConfig{Env: "development", TrustedProxies: []string{"0.0.0.0/0", "::/0"}}, wantWarnings: 2},
+		// Production rejects it in ValidateTrustedProxies instead
+		{name: "catch-all in production", cfg: AppConfig{Env: "production", TrustedProxies: []string{"0.0.0.0/0"}}},
+		{name: "narrow prefix", cfg: AppConfig{Env: "development", TrustedProxies: []string{"10.0.0.0/8"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if warnings := tt.cfg.TrustedProxyWarnings(); len(warnings) != tt.wantWarnings {
+				t.Errorf("Expected %d warnings, got %v", tt.wantWarnings, warnings)
+			}
+		})
+	}
+}
+
+func TestLogWarnings(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	cfg := &AppConfig{Env: "development", TrustedProxies: []string{"0.0.0.0/0"}}
+	LogWarnings(logger, cfg.TrustedProxyWarnings())
+
+	out := buf.String()
+	if !strings.Contains(out, "level=WARN") || !strings.Contains(out, "field=TRUSTED_PROXIES") || !strings.Contains(out, "0.0.0.0/0") {
+		t.Errorf("Expected a TRUSTED_PROXIES warning, got %q", out)
+	}
+}
+
+func TestAppConfig_LogLevelWarnings(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        AppConfig
+		wantFields []string
+	}{
+		{name: "known levels", cfg: AppConfig{LogLevel: "info", LogFileLevel: "debug"}},
+		{name: "case and space", cfg: AppConfig{LogLevel: " WARN ", LogFileLevel: "Error"}},
+		{name: "typo", cfg: AppConfig{LogLevel: "debugg", LogFileLevel: "debug"}, wantFields: []string{"LOG_LEVEL"}},
+		{name: "both unknown", cfg: AppConfig{LogLevel: "verbose", LogFileLevel: "trace"}, wantFields: []string{"LOG_LEVEL", "LOG_FILE_LEVEL"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields []string
+			for _, w := range tt.cfg.LogLevelWarnings() {
+				fields = append(fields, w.Field)
+			}
+			if !slices.Equal(fields, tt.wantFields) {
+				t.Errorf("Expected warnings for %v, got %v", tt.wantFields, fields)
+			}
+		})
+	}
+}
+
+func TestConfigProvider_WarningsUnknownLogLevel(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "debugg")
+
+	var buf bytes.Buffer
+	LogWarnings(slog.New(slog.NewTextHandler(&buf, nil)), New().Warnings())
+
+	out := buf.String()
+	for _, want := range []string{"level=WARN", "field=LOG_LEVEL", `\"debugg\"`, "debug, info, warn, error"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the warning, got %q", want, out)
+		}
+	}
+}
+
+func TestConfigProvider_Sources(t *testing.T) {
+	t.Setenv("PORT", "9000")
+	t.Setenv("DB_PASSWORD", "hunter2")
+	t.Setenv("MAX_HEADERS", "lots")
+	t.Setenv("WARMUP_TIMEOUT", "")
+
+	sources := make(map[string]Source)
+	for _, s := range New().Sources() {
+		sources[s.Key] = s
+	}
+
+	tests := []struct {
+		key        string
+		wantOrigin string
+		wantValue  string
+	}{
+		{key: "PORT", wantOrigin: OriginEnv, wantValue: "9000"},
+		{key: "WARMUP_TIMEOUT", wantOrigin: OriginDefault, wantValue: "3s"},
+		{key: "DB_PASSWORD", wantOrigin: OriginEnv, wantValue: redacted},
+		{key: "MAX_HEADERS", wantOrigin: OriginInvalid, wantValue: "lots"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, ok := sources[tt.key]
+			if !ok {
+				t.Fatalf("Expected a source for %s", tt.key)
+			}
+			if got.Origin != tt.wantOrigin || got.Value != tt.wantValue {
+				t.Errorf("Expected %s from %s (%s), got %s (%s)", tt.key, tt.wantOrigin, tt.wantValue, got.Origin, got.Value)
+			}
+		})
+	}
+
+	t.Run(".env attribution", func(t *testing.T) {
+		l := newLoader(map[string]bool{"PORT": true})
+		l.getenv("PORT", "8443")
+		if got := l.sources["PORT"].Origin; got != OriginDotEnv {
+			t.Errorf("Expected PORT from %s, got %s", OriginDotEnv, got)
+		}
+	})
+}
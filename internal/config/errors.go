@@ -0,0 +1,56 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// ConfigError reports one invalid setting: the environment variable at fault
+// and why its value was rejected.
+type ConfigError struct {
+	Field  string // Environment variable name, e.g. "PUBLIC_BASE_URL"
+	Reason string // What is wrong with the value
+}
+
+// Error formats the error as "FIELD: reason".
+func (e *ConfigError) Error() string {
+	return e.Field + ": " + e.Reason
+}
+
+// fieldError returns a ConfigError for field with a formatted reason.
+func fieldError(field, format string, args ...any) *ConfigError {
+	return &ConfigError{Field: field, Reason: fmt.Sprintf(format, args...)}
+}
+
+// ValidationErrors flattens err, as returned by Validate, into its individual
+// ConfigErrors so each can be reported on its own. Errors that are not
+// ConfigErrors are skipped.
+func ValidationErrors(err error) []*ConfigError {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *ConfigError:
+		return []*ConfigError{e}
+	case interface{ Unwrap() []error }:
+		var errs []*ConfigError
+		for _, inner := range e.Unwrap() {
+			errs = append(errs, ValidationErrors(inner)...)
+		}
+		return errs
+	}
+
+	var ce *ConfigError
+	if errors.As(err, &ce) {
+		return []*ConfigError{ce}
+	}
+	return nil
+}
+
+// LogWarnings logs each warning, as returned by Warnings, at Warn level so a
+// risky but valid setting stands out in the startup log.
+func LogWarnings(logger *slog.Logger, warnings []*ConfigError) {
+	for _, w := range warnings {
+		logger.Warn("configuration warning", "field", w.Field, "reason", w.Reason)
+	}
+}
@@ -11,6 +11,9 @@ type ConfigProvider interface {
 	// Validate performs configuration validation and returns any errors
 	Validate() error
 
+	// Warnings reports settings that are valid but likely misconfigured
+	Warnings() []*ConfigError
+
 	// GetString returns a string configuration value by key
 	GetString(key string) string
 
@@ -22,4 +25,7 @@ type ConfigProvider interface {
 
 	// GetStringSlice returns a string slice configuration value by key
 	GetStringSlice(key string) []string
+
+	// Sources reports whether each value came from a default, the environment or .env
+	Sources() []Source
 }
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// Origins of a configuration value, as reported in Source.Origin.
+const (
+	OriginDefault = "default" // Variable unset; built-in default used
+	OriginEnv     = "env"     // Set in the process environment
+	OriginDotEnv  = ".env"    // Set by the .env file (which overrides the environment)
+	OriginInvalid = "invalid" // Set but unparsable; built-in default used
+)
+
+// Source records where a configuration value came from.
+type Source struct {
+	Key    string // Environment variable name, e.g. DB_HOST
+	Origin string // One of the Origin constants
+	Value  string // Raw value, redacted for secrets
+}
+
+// redacted replaces secret values in Source and in logs.
+const redacted = "[REDACTED]"
+
+// loader reads typed values from the environment, recording the source of each.
+type loader struct {
+	dotenv  map[string]bool // keys set by the .env file
+	sources map[string]Source
+}
+
+func newLoader(dotenv map[string]bool) *loader {
+	return &loader{dotenv: dotenv, sources: make(map[string]Source)}
+}
+
+// record notes a value taken from the environment (set) or the default.
+func (l *loader) record(k, v string, set bool) {
+	origin := OriginDefault
+	if set {
+		origin = OriginEnv
+		if l.dotenv[k] {
+			origin = OriginDotEnv
+		}
+	}
+	l.sources[k] = Source{Key: k, Origin: origin, Value: redact(k, v)}
+}
+
+// recordInvalid notes a value that failed to parse, so the default was used.
+func (l *loader) recordInvalid(k, v string) {
+	l.sources[k] = Source{Key: k, Origin: OriginInvalid, Value: redact(k, v)}
+}
+
+// sorted returns the recorded sources ordered by key.
+func (l *loader) sorted() []Source {
+	out := make([]Source, 0, len(l.sources))
+	for _, s := range l.sources {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// redact hides the value of secret-looking keys; empty values stay empty so an
+// unset secret is still visible as such.
+func redact(k, v string) string {
+	if v == "" {
+		return v
+	}
+	for _, marker := range []string{"PASSWORD", "SECRET", "TOKEN"} {
+		if strings.Contains(k, marker) {
+			return redacted
+		}
+	}
+	return v
+}
+
+// Sources returns where each configuration value came from, ordered by key.
+func (c *configProvider) Sources() []Source {
+	return c.sources
+}
+
+// LogSources writes a single "config_source" record at debug level with the
+// origin and (redacted) value of every configuration key.
+func LogSources(logger *slog.Logger, sources []Source) {
+	attrs := make([]any, 0, len(sources))
+	for _, s := range sources {
+		attrs = append(attrs, slog.Group(s.Key, "source", s.Origin, "value", s.Value))
+	}
+	logger.Debug("config_source", attrs...)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -26,6 +27,14 @@ type DatabaseConfig struct {
 	Password string
 	Database string
 	SSLMode  string
+
+	// Server-side limits sent in the DSN; zero leaves the PostgreSQL default
+	ConnectTimeout   time.Duration // connect_timeout, rounded up to whole seconds
+	StatementTimeout time.Duration // statement_timeout, in milliseconds
+
+	// Pool sizing; zero leaves the pgxpool default
+	MinConns int32 // pool_min_conns, connections the pool keeps open
+	WarmUp   bool  // Open MinConns connections in Connect instead of lazily
 }
 
 // New creates a new database instance with the given configuration.
@@ -56,6 +65,13 @@ func (d *Database) Connect(ctx context.Context) error {
 		return fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	if d.config.WarmUp {
+		if err := warmUpPool(ctx, pool, d.config.MinConns); err != nil {
+			pool.Close()
+			return fmt.Errorf("failed to warm up database pool: %w", err)
+		}
+	}
+
 	d.pool = pool
 	d.connected = true
 	now := time.Now()
@@ -108,6 +124,28 @@ func (d *Database) GetConnectionInfo() ConnectionInfo {
 	}
 }
 
+// warmUpPool opens n connections up front by acquiring them all at once, then
+// releases them to sit idle in the pool, so the first burst of requests does
+// not pay connection setup. pgxpool only tops up MinConns in the background.
+// Acquiring stops at ctx's deadline.
+func warmUpPool(ctx context.Context, pool *pgxpool.Pool, n int32) error {
+	conns := make([]*pgxpool.Conn, 0, n)
+	defer func() {
+		for _, c := range conns {
+			c.Release()
+		}
+	}()
+
+	for range n {
+		c, err := pool.Acquire(ctx)
+		if err != nil {
+			return err
+		}
+		conns = append(conns, c)
+	}
+	return nil
+}
+
 // buildDSN assembles a PostgreSQL DSN from configuration parameters.
 // It returns an empty string if required parameters are missing.
 func (d *Database) buildDSN() string {
@@ -126,6 +164,19 @@ func (d *Database) buildDSN() string {
 	if d.config.SSLMode != "" {
 		q.Set("sslmode", d.config.SSLMode)
 	}
+	if d.config.ConnectTimeout > 0 {
+		// connect_timeout only accepts whole seconds
+		seconds := (d.config.ConnectTimeout + time.Second - 1) / time.Second
+		q.Set("connect_timeout", strconv.FormatInt(int64(seconds), 10))
+	}
+	if d.config.StatementTimeout > 0 {
+		// Enforced by the server even if a query's context is never cancelled
+		q.Set("options", fmt.Sprintf("-c statement_timeout=%d", d.config.StatementTimeout.Milliseconds()))
+	}
+
+	if d.config.MinConns > 0 {
+		q.Set("pool_min_conns", strconv.FormatInt(int64(d.config.MinConns), 10))
+	}
 
 	u.RawQuery = q.Encode()
 	return u.String()
@@ -2,7 +2,12 @@ package db
 
 import (
 	"context"
+	"net/url"
+	"os"
 	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func TestNew(t *testing.T) {
@@ -204,6 +209,96 @@ func TestDatabase_BuildDSN(t *testing.T) {
 	}
 }
 
+func TestDatabase_BuildDSN_Timeouts(t *testing.T) {
+	config := DatabaseConfig{
+		Host:     "localhost",
+		Port:     "5432",
+		User:     "testuser",
+		Database: "testdb",
+	}
+
+	t.Run("includes configured timeouts", func(t *testing.T) {
+		cfg := config
+		cfg.ConnectTimeout = 2500 * time.Millisecond
+		cfg.StatementTimeout = 30 * time.Second
+
+		u, err := url.Parse((&Database{config: cfg}).buildDSN())
+		if err != nil {
+			t.Fatalf("Expected a valid DSN, got %v", err)
+		}
+		q := u.Query()
+		if got := q.Get("connect_timeout"); got != "3" {
+			t.Errorf("Expected connect_timeout rounded up to 3, got '%s'", got)
+		}
+		if got := q.Get("options"); got != "-c statement_timeout=30000" {
+			t.Errorf("Expected statement_timeout option, got '%s'", got)
+		}
+	})
+
+	t.Run("omits unset timeouts", func(t *testing.T) {
+		dsn := (&Database{config: config}).buildDSN()
+		for _, param := range []string{"connect_timeout", "options"} {
+			if contains(dsn, param) {
+				t.Errorf("Expected DSN without %s, got '%s'", param, dsn)
+			}
+		}
+	})
+}
+
+func TestDatabase_BuildDSN_MinConns(t *testing.T) {
+	cfg := DatabaseConfig{Host: "localhost", Port: "5432", User: "testuser", Database: "testdb", MinConns: 4}
+
+	u, err := url.Parse((&Database{config: cfg}).buildDSN())
+	if err != nil {
+		t.Fatalf("Expected a valid DSN, got %v", err)
+	}
+	if got := u.Query().Get("pool_min_conns"); got != "4" {
+		t.Errorf("Expected pool_min_conns 4, got '%s'", got)
+	}
+}
+
+func TestWarmUpPool(t *testing.T) {
+	t.Run("respects the context", func(t *testing.T) {
+		// pgxpool.New connects lazily, so the unreachable address is never dialled
+		pool, err := pgxpool.New(context.Background(), "postgres://user@127.0.0.1:1/db")
+		if err != nil {
+			t.Fatalf("Failed to create pool: %v", err)
+		}
+		defer pool.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := warmUpPool(ctx, pool, 2); err == nil {
+			t.Error("Expected an error from a cancelled warm-up")
+		}
+		if n := pool.Stat().TotalConns(); n != 0 {
+			t.Errorf("Expected no connections left acquired, got %d", n)
+		}
+	})
+
+	t.Run("leaves the connections idle", func(t *testing.T) {
+		dsn := os.Getenv("TEST_DATABASE_URL")
+		if dsn == "" {
+			t.Skip("TEST_DATABASE_URL not set; skipping database test")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		pool, err := pgxpool.New(ctx, dsn)
+		if err != nil {
+			t.Fatalf("Failed to create pool: %v", err)
+		}
+		defer pool.Close()
+
+		if err := warmUpPool(ctx, pool, 3); err != nil {
+			t.Fatalf("Expected warm-up to succeed, got %v", err)
+		}
+		if idle := pool.Stat().IdleConns(); idle < 3 {
+			t.Errorf("Expected at least 3 idle connections, got %d", idle)
+		}
+	})
+}
+
 // Helper function to check if string contains substring
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr ||
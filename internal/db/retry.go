@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// maxRetryDelay caps the doubling delay between startup connection attempts.
+const maxRetryDelay = 30 * time.Second
+
+// RetryConfig controls how ConnectWithRetry retries a failed connection.
+type RetryConfig struct {
+	Retries        int           // Attempts after the first; 0 tries once
+	Delay          time.Duration // Wait before the first retry, doubled after each one
+	AttemptTimeout time.Duration // Budget for each connect and ping attempt
+}
+
+// ConnectWithRetry connects and pings the database, retrying failures with
+// exponential backoff so a database that is still starting does not stop the
+// app from booting. It returns the last error once the retries are exhausted,
+// or ctx's error if ctx is done while waiting.
+func ConnectWithRetry(ctx context.Context, database DatabaseProvider, cfg RetryConfig, logger *slog.Logger) error {
+	delay := cfg.Delay
+	for attempt := 0; ; attempt++ {
+		err := connectOnce(ctx, database, cfg.AttemptTimeout)
+		if err == nil {
+			return nil
+		}
+		if attempt >= cfg.Retries {
+			return err
+		}
+
+		if logger != nil {
+			logger.Warn("database connection failed, retrying",
+				"attempt", attempt+1,
+				"retries", cfg.Retries,
+				"retry_in", delay,
+				"error", err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("database connection retry aborted: %w", ctx.Err())
+		case <-timer.C:
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// connectOnce makes a single connect and ping attempt within timeout.
+func connectOnce(ctx context.Context, database DatabaseProvider, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if err := database.Connect(ctx); err != nil {
+		return err
+	}
+	if err := database.Ping(ctx); err != nil {
+		database.Close()
+		return fmt.Errorf("database ping failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// flakyDatabase fails to connect until its succeedOn-th attempt.
+type flakyDatabase struct {
+	succeedOn int
+	attempts  int
+}
+
+func (f *flakyDatabase) Connect(ctx context.Context) error {
+	f.attempts++
+	if f.attempts < f.succeedOn {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (f *flakyDatabase) Close()                            {}
+func (f *flakyDatabase) GetPool() *pgxpool.Pool            { return nil }
+func (f *flakyDatabase) Ping(ctx context.Context) error    { return nil }
+func (f *flakyDatabase) IsConnected() bool                 { return f.attempts >= f.succeedOn }
+func (f *flakyDatabase) GetConnectionInfo() ConnectionInfo { return ConnectionInfo{} }
+
+func TestConnectWithRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		succeedOn    int
+		retries      int
+		wantErr      bool
+		wantAttempts int
+	}{
+		{name: "first attempt succeeds", succeedOn: 1, retries: 0, wantAttempts: 1},
+		{name: "succeeds on third attempt", succeedOn: 3, retries: 3, wantAttempts: 3},
+		{name: "no retries by default", succeedOn: 2, retries: 0, wantErr: true, wantAttempts: 1},
+		{name: "retries exhausted", succeedOn: 5, retries: 2, wantErr: true, wantAttempts: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := &flakyDatabase{succeedOn: tt.succeedOn}
+			err := ConnectWithRetry(context.Background(), database, RetryConfig{
+				Retries:        tt.retries,
+				Delay:          time.Millisecond,
+				AttemptTimeout: time.Second,
+			}, nil)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if database.attempts != tt.wantAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.wantAttempts, database.attempts)
+			}
+		})
+	}
+
+	t.Run("stops waiting when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		database := &flakyDatabase{succeedOn: 2}
+		err := ConnectWithRetry(ctx, database, RetryConfig{Retries: 5, Delay: time.Hour}, nil)
+
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		if database.attempts != 1 {
+			t.Errorf("Expected 1 attempt, got %d", database.attempts)
+		}
+	})
+}
@@ -1,16 +1,14 @@
 package handlers
 
-import "net/http"
+import (
+	"net/http"
 
-func (p *Pages) About(w http.ResponseWriter, r *http.Request) {
-	// Set content type
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	"guitar-specs/internal/render"
+)
 
-	// Render template using new interface with request context
-	if err := p.render.RenderWithRequest(w, "about", r, map[string]any{
+func (p *Pages) About(w http.ResponseWriter, r *http.Request) {
+	// Render template with request context; failures become a clean 500
+	render.HTML(w, r, p.render, "about", map[string]any{
 		"Title": "About Us",
-	}); err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
+	})
 }
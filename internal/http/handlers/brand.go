@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"guitar-specs/internal/models"
+	"guitar-specs/internal/render"
+)
+
+// BrandDetail renders a brand landing page with the brand's guitars.
+// Path expected: /brand/{slug}
+func (p *Pages) BrandDetail(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+	if slug == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	b, guitars, err := p.store.Guitars.GetBrandWithGuitars(r.Context(), slug)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, "Failed to load brand", http.StatusInternalServerError)
+		return
+	}
+
+	// Render template with request context; failures become a clean 500
+	render.HTML(w, r, p.render, "brand", map[string]any{
+		"Title":   b.Name,
+		"brand":   b,
+		"guitars": guitars,
+	})
+}
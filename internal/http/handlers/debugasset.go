@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"guitar-specs/internal/assets"
+)
+
+// debugAssetResponse is the JSON body returned by DebugAsset.
+type debugAssetResponse struct {
+	Path          string `json:"path"`
+	VersionedPath string `json:"versioned_path"`
+	SRI           string `json:"sri"`
+	Size          int64  `json:"size"`
+	ContentType   string `json:"content_type"`
+}
+
+// DebugAsset returns a handler reporting what the asset provider resolves for
+// ?path=, e.g. /debug/asset?path=/static/css/main.css, to help diagnose CDN
+// caching without reading the manifest. It is only routed when debugging is enabled.
+func DebugAsset(provider assets.AssetProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assetPath := r.URL.Query().Get("path")
+		if assetPath == "" {
+			writeDebugJSON(w, http.StatusBadRequest, map[string]string{"error": "missing path parameter"})
+			return
+		}
+
+		info, ok := provider.GetAssetInfo(assetPath)
+		if !ok {
+			writeDebugJSON(w, http.StatusNotFound, map[string]string{"error": "asset not found", "path": assetPath})
+			return
+		}
+
+		writeDebugJSON(w, http.StatusOK, debugAssetResponse{
+			Path:          assetPath,
+			VersionedPath: provider.AssetURL(assetPath),
+			SRI:           info.SRI,
+			Size:          info.Size,
+			ContentType:   info.ContentType,
+		})
+	}
+}
+
+// writeDebugJSON writes v as an uncached JSON response.
+func writeDebugJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"guitar-specs/internal/assets"
+)
+
+func TestDebugAsset(t *testing.T) {
+	provider, err := assets.New(fstest.MapFS{
+		"static/dist/js/manifest.json": &fstest.MapFile{Data: []byte(`{"files": {
+			"static/css/main.css": {
+				"path": "/static/css/main.abc123.css",
+				"filename": "static/css/main.abc123.css",
+				"sri": "sha384-abc123",
+				"size": 1024,
+				"content_type": "text/css"
+			}
+		}}`)},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create asset provider: %v", err)
+	}
+	h := DebugAsset(provider)
+
+	t.Run("known asset", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/debug/asset?path=/static/css/main.css", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Expected JSON content type, got %s", ct)
+		}
+
+		var body debugAssetResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Expected JSON body, got %q: %v", w.Body.String(), err)
+		}
+		want := debugAssetResponse{
+			Path:          "/static/css/main.css",
+			VersionedPath: "/static/css/main.abc123.css",
+			SRI:           "sha384-abc123",
+			Size:          1024,
+			ContentType:   "text/css",
+		}
+		if body != want {
+			t.Errorf("Expected %+v, got %+v", want, body)
+		}
+	})
+
+	t.Run("unknown asset", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/debug/asset?path=/static/css/missing.css", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+		var body map[string]string
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["error"] == "" {
+			t.Errorf("Expected JSON error body, got %q", w.Body.String())
+		}
+	})
+
+	t.Run("missing path", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/debug/asset", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+}
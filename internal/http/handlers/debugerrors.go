@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+
+	mw "guitar-specs/internal/http/middleware"
+)
+
+// debugErrorsResponse is the JSON body returned by DebugErrors.
+type debugErrorsResponse struct {
+	Errors []mw.ErrorRecord `json:"errors"`
+}
+
+// DebugErrors returns a handler listing the most recent 5xx responses kept by
+// errorLog, newest first. It is only routed when debugging is enabled.
+func DebugErrors(errorLog *mw.ErrorLog) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		records := errorLog.Recent()
+		if records == nil {
+			records = []mw.ErrorRecord{}
+		}
+		writeDebugJSON(w, http.StatusOK, debugErrorsResponse{Errors: records})
+	}
+}
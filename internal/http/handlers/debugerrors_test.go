@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mw "guitar-specs/internal/http/middleware"
+)
+
+func TestDebugErrors(t *testing.T) {
+	errorLog := mw.NewErrorLog(10)
+	h := DebugErrors(errorLog)
+
+	serve := func() (int, []mw.ErrorRecord) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/debug/errors", nil))
+
+		if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Expected JSON content type, got %s", ct)
+		}
+		var body struct {
+			Errors []mw.ErrorRecord `json:"errors"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Expected valid JSON, got %v", err)
+		}
+		if body.Errors == nil {
+			t.Error("Expected an errors array, got null")
+		}
+		return w.Code, body.Errors
+	}
+
+	t.Run("empty log", func(t *testing.T) {
+		if status, records := serve(); status != http.StatusOK || len(records) != 0 {
+			t.Errorf("Expected 200 with no records, got %d %v", status, records)
+		}
+	})
+
+	t.Run("recorded errors", func(t *testing.T) {
+		errorLog.Record(mw.ErrorRecord{Method: "GET", Path: "/guitars", Status: 500, RequestID: "abc", Panic: "boom"})
+		errorLog.Record(mw.ErrorRecord{Method: "GET", Path: "/features", Status: 503})
+
+		status, records := serve()
+		if status != http.StatusOK || len(records) != 2 {
+			t.Fatalf("Expected 200 with 2 records, got %d %v", status, records)
+		}
+		if records[0].Path != "/features" {
+			t.Errorf("Expected newest record first, got %s", records[0].Path)
+		}
+		if records[1].RequestID != "abc" || records[1].Panic != "boom" {
+			t.Errorf("Expected request ID and panic to be reported, got %+v", records[1])
+		}
+	})
+}
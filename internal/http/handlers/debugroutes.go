@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+)
+
+// RouteInfo describes one registered route for DebugRoutes.
+type RouteInfo struct {
+	Pattern    string   `json:"pattern"`
+	Method     string   `json:"method,omitempty"` // Empty when the pattern matches every method
+	Path       string   `json:"path"`
+	Middleware []string `json:"middleware"` // Route middleware, outermost first
+}
+
+// RouteLister reports the application's routes and its global middleware chain.
+type RouteLister interface {
+	Routes() []RouteInfo
+	Middleware() []string
+}
+
+// debugRoutesResponse is the JSON body returned by DebugRoutes.
+type debugRoutesResponse struct {
+	Middleware []string    `json:"middleware"`
+	Routes     []RouteInfo `json:"routes"`
+}
+
+// DebugRoutes returns a handler listing the registered routes with their route
+// middleware, and the global middleware chain every request passes through, to
+// check a deployment's configuration. It is only routed when debugging is enabled.
+func DebugRoutes(lister RouteLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeDebugJSON(w, http.StatusOK, debugRoutesResponse{
+			Middleware: lister.Middleware(),
+			Routes:     lister.Routes(),
+		})
+	}
+}
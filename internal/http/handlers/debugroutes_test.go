@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type stubRouteLister struct {
+	routes     []RouteInfo
+	middleware []string
+}
+
+func (s stubRouteLister) Routes() []RouteInfo  { return s.routes }
+func (s stubRouteLister) Middleware() []string { return s.middleware }
+
+func TestDebugRoutes(t *testing.T) {
+	h := DebugRoutes(stubRouteLister{
+		routes: []RouteInfo{
+			{Pattern: "GET /about", Method: "GET", Path: "/about", Middleware: []string{"middleware.ETag"}},
+			{Pattern: "/", Path: "/", Middleware: []string{}},
+		},
+		middleware: []string{"middleware.RequestID"},
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/debug/routes", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected JSON content type, got %s", ct)
+	}
+	var body struct {
+		Middleware []string    `json:"middleware"`
+		Routes     []RouteInfo `json:"routes"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	if len(body.Middleware) != 1 || body.Middleware[0] != "middleware.RequestID" {
+		t.Errorf("Expected global middleware [middleware.RequestID], got %v", body.Middleware)
+	}
+	if len(body.Routes) != 2 {
+		t.Fatalf("Expected 2 routes, got %d", len(body.Routes))
+	}
+	if r := body.Routes[0]; r.Method != "GET" || r.Path != "/about" || len(r.Middleware) != 1 {
+		t.Errorf("Expected GET /about with one middleware, got %+v", r)
+	}
+	if r := body.Routes[1]; r.Method != "" || r.Path != "/" {
+		t.Errorf("Expected any-method /, got %+v", r)
+	}
+}
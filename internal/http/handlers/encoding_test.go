@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"embed"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/andybalholm/brotli"
+
+	mw "guitar-specs/internal/http/middleware"
+)
+
+// TestBrotliNegotiation checks that a br-capable client gets brotli both for a
+// rendered page (compressed on the fly) and a static asset (precompressed).
+func TestBrotliNegotiation(t *testing.T) {
+	pages := New(&mockRenderer{}, embed.FS{}, nil)
+	page := mw.Compress(nil, mw.DefaultCompressLevel)(http.HandlerFunc(pages.Home))
+	static := PrecompressedFileServer(fstest.MapFS{
+		"js/app.js":    &fstest.MapFile{Data: []byte("console.log('app');")},
+		"js/app.js.br": &fstest.MapFile{Data: []byte("brotli-bytes")},
+		"js/app.js.gz": &fstest.MapFile{Data: []byte("gzip-bytes")},
+	})
+
+	t.Run("rendered page", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+		w := httptest.NewRecorder()
+		page.ServeHTTP(w, req)
+
+		if w.Header().Get("Content-Encoding") != "br" {
+			t.Fatalf("Expected Content-Encoding br, got '%s'", w.Header().Get("Content-Encoding"))
+		}
+		body, err := io.ReadAll(brotli.NewReader(w.Body))
+		if err != nil {
+			t.Fatalf("Expected valid brotli body, got %v", err)
+		}
+		if string(body) != "<h1>home</h1>" {
+			t.Errorf("Expected rendered page, got '%s'", body)
+		}
+	})
+
+	t.Run("static asset", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/js/app.js", nil)
+		req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+		w := httptest.NewRecorder()
+		static.ServeHTTP(w, req)
+
+		if w.Header().Get("Content-Encoding") != "br" {
+			t.Errorf("Expected Content-Encoding br, got '%s'", w.Header().Get("Content-Encoding"))
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"guitar-specs/internal/api"
+	"guitar-specs/internal/models"
+)
+
+// exportFlushEvery is the number of NDJSON lines written between flushes.
+const exportFlushEvery = 100
+
+// GuitarSource streams the guitar catalogue one guitar at a time.
+// models.GuitarStore satisfies it.
+type GuitarSource interface {
+	Each(ctx context.Context, batchSize int, fn func(models.Guitar) error) error
+}
+
+// exportGuitar is one NDJSON line written by GuitarsExport.
+type exportGuitar struct {
+	ID        string `json:"id"`
+	Slug      string `json:"slug"`
+	Type      string `json:"type"`
+	Model     string `json:"model"`
+	BrandSlug string `json:"brand_slug"`
+	BrandName string `json:"brand_name"`
+	ShapeSlug string `json:"shape_slug"`
+	ShapeName string `json:"shape_name"`
+}
+
+// GuitarsExport returns a handler streaming every guitar as newline-delimited
+// JSON, one object per line, for bulk export. Rows are read in batches and the
+// response is flushed as it goes, so the catalogue is never buffered whole.
+// Wrapping middleware must pass http.Flusher through (e.g. via Unwrap).
+//
+// A failure before the first line is a JSON 500; once streaming has started
+// the status is already sent, so the response is simply cut short.
+func GuitarsExport(source GuitarSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Cache-Control", "no-store")
+
+		rc := http.NewResponseController(w)
+		enc := json.NewEncoder(w)
+		written := 0
+
+		err := source.Each(r.Context(), models.ExportBatchSize, func(g models.Guitar) error {
+			if err := enc.Encode(exportGuitar{
+				ID:        g.ID,
+				Slug:      g.Slug,
+				Type:      g.Type,
+				Model:     g.Model,
+				BrandSlug: g.BrandSlug,
+				BrandName: g.BrandName,
+				ShapeSlug: g.ShapeSlug,
+				ShapeName: g.ShapeName,
+			}); err != nil {
+				return err
+			}
+			written++
+			if written%exportFlushEvery == 0 {
+				// Without flush support the lines still arrive, just later
+				_ = rc.Flush()
+			}
+			return nil
+		})
+		if err != nil && written == 0 {
+			api.WriteError(w, http.StatusInternalServerError, "failed to export guitars")
+		}
+	}
+}
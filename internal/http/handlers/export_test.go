@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"guitar-specs/internal/models"
+)
+
+// stubGuitarSource yields count generated guitars, then err.
+type stubGuitarSource struct {
+	count int
+	err   error
+}
+
+func (s stubGuitarSource) Each(ctx context.Context, batchSize int, fn func(models.Guitar) error) error {
+	for i := range s.count {
+		g := models.Guitar{
+			ID:        fmt.Sprintf("id-%d", i),
+			Slug:      fmt.Sprintf("guitar-%d", i),
+			Model:     fmt.Sprintf("Model \"%d\"\n", i),
+			BrandName: "Brand",
+		}
+		if err := fn(g); err != nil {
+			return err
+		}
+	}
+	return s.err
+}
+
+func TestGuitarsExport(t *testing.T) {
+	serve := func(source GuitarSource) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		GuitarsExport(source).ServeHTTP(w, httptest.NewRequest("GET", "/api/guitars/export", nil))
+		return w
+	}
+
+	t.Run("one valid JSON object per line", func(t *testing.T) {
+		const count = 250
+		w := serve(stubGuitarSource{count: count})
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+			t.Errorf("Expected Content-Type application/x-ndjson, got '%s'", ct)
+		}
+		if !w.Flushed {
+			t.Error("Expected the response to be flushed while streaming")
+		}
+
+		lines := 0
+		scanner := bufio.NewScanner(w.Body)
+		for scanner.Scan() {
+			var g struct {
+				ID    string `json:"id"`
+				Model string `json:"model"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &g); err != nil {
+				t.Fatalf("Expected line %d to be valid JSON, got %v: %s", lines+1, err, scanner.Text())
+			}
+			if want := fmt.Sprintf("id-%d", lines); g.ID != want {
+				t.Errorf("Expected id '%s' on line %d, got '%s'", want, lines+1, g.ID)
+			}
+			lines++
+		}
+		if lines != count {
+			t.Errorf("Expected %d lines, got %d", count, lines)
+		}
+	})
+
+	t.Run("empty catalogue", func(t *testing.T) {
+		w := serve(stubGuitarSource{})
+		if w.Code != http.StatusOK || w.Body.Len() != 0 {
+			t.Errorf("Expected an empty 200, got %d with '%s'", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("failure before the first line", func(t *testing.T) {
+		w := serve(stubGuitarSource{err: errors.New("db down")})
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+	})
+
+	t.Run("failure mid-stream cuts the response short", func(t *testing.T) {
+		w := serve(stubGuitarSource{count: 3, err: errors.New("db down")})
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected the already-sent status 200, got %d", w.Code)
+		}
+		if n := strings.Count(w.Body.String(), "\n"); n != 3 {
+			t.Errorf("Expected 3 lines, got %d", n)
+		}
+	})
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"net/http"
+
+	"guitar-specs/internal/render"
+)
+
+// Features renders a glossary of every spec with its kind, unit and allowed values.
+func (p *Pages) Features(w http.ResponseWriter, r *http.Request) {
+	features, err := p.store.Features.List(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to load features", http.StatusInternalServerError)
+		return
+	}
+
+	// Render template with request context; failures become a clean 500
+	render.HTML(w, r, p.render, "features", map[string]any{
+		"Title":    "Features",
+		"features": features,
+	})
+}
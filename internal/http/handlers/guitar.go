@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strings"
+
+	"guitar-specs/internal/models"
 )
 
 // GuitarDetail renders a single guitar with its features.
@@ -15,29 +18,17 @@ func (p *Pages) GuitarDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	g, err := p.store.Guitars.GetBySlug(r.Context(), slug)
-	if err != nil {
-		http.NotFound(w, r)
-		return
-	}
-	feats, err := p.store.Guitars.ListFeaturesBySlug(r.Context(), slug)
+	// Concurrent requests for the same slug share one guitar + features lookup
+	g, err := p.store.Guitars.GetDetailBySlug(r.Context(), slug)
 	if err != nil {
-		http.Error(w, "Failed to load features", http.StatusInternalServerError)
+		if errors.Is(err, models.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, "Failed to load guitar", http.StatusInternalServerError)
 		return
 	}
 
-	// Attach features to the guitar
-	g.Features = feats
-
-	// Set content type
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-
-	// Render template using new interface with request context
-	if err := p.render.RenderWithRequest(w, "guitar", r, map[string]any{
-		"Title":  g.BrandName + " " + g.Model,
-		"guitar": g,
-	}); err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
+	// Render template with request context; failures become a clean 500
+	p.RenderGuitarDetail(w, r, g, g.Features)
 }
@@ -1,23 +1,34 @@
 package handlers
 
-import "net/http"
+import (
+	"net/http"
+
+	"guitar-specs/internal/models"
+)
 
 // Guitars renders a simple list of guitars from the database.
+// Passing ?feature=<key>&value=<value> narrows the list to guitars with that spec.
 func (p *Pages) Guitars(w http.ResponseWriter, r *http.Request) {
-	list, err := p.store.Guitars.List(r.Context())
+	featureKey := r.URL.Query().Get("feature")
+	featureValue := r.URL.Query().Get("value")
+
+	var list []models.Guitar
+	var stale bool
+	var err error
+	if featureKey != "" && featureValue != "" {
+		list, err = p.store.Guitars.ListByFeature(r.Context(), featureKey, featureValue)
+	} else {
+		// The unfiltered catalogue may fall back to the last good result if the DB blips
+		list, stale, err = p.store.Guitars.ListOrStale(r.Context())
+	}
 	if err != nil {
 		http.Error(w, "Failed to query guitars", http.StatusInternalServerError)
 		return
 	}
-	// Set content type
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-
-	// Render template using new interface with request context
-	if err := p.render.RenderWithRequest(w, "guitars", r, map[string]any{
-		"Title":   "Guitars",
-		"guitars": list,
-	}); err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
+	if stale {
+		w.Header().Set("Warning", `110 - "Response is Stale"`)
+		w.Header().Set("Cache-Control", "no-store")
 	}
+	// Render template with request context; failures become a clean 500
+	p.RenderGuitarList(w, r, list, featureKey, featureValue)
 }
@@ -1,16 +1,14 @@
 package handlers
 
-import "net/http"
+import (
+	"net/http"
 
-func (p *Pages) Home(w http.ResponseWriter, r *http.Request) {
-	// Set content type
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	"guitar-specs/internal/render"
+)
 
-	// Render template using new interface with request context
-	if err := p.render.RenderWithRequest(w, "home", r, map[string]any{
+func (p *Pages) Home(w http.ResponseWriter, r *http.Request) {
+	// Render template with request context; failures become a clean 500
+	render.HTML(w, r, p.render, "home", map[string]any{
 		"Title": "Home",
-	}); err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
+	})
 }
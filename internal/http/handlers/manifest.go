@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"guitar-specs/internal/assets"
+)
+
+// AssetManifest serves the provider's current manifest as JSON, e.g. for a
+// service worker building its precache list of fingerprinted URLs. The body is
+// built per request from GetManifest, so a reloaded manifest is served at once;
+// clients revalidate every time against an ETag hashed from the body.
+func AssetManifest(provider assets.AssetProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := json.Marshal(provider.GetManifest())
+		if err != nil {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		sum := sha256.Sum256(body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:8])+`"`)
+
+		// ServeContent answers If-None-Match with 304 and handles HEAD
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"guitar-specs/internal/assets"
+)
+
+func TestAssetManifest(t *testing.T) {
+	provider, err := assets.New(fstest.MapFS{
+		"static/dist/js/manifest.json": &fstest.MapFile{Data: []byte(`{"files": {
+			"static/css/main.css": {
+				"path": "/static/css/main.abc123.css",
+				"filename": "static/css/main.abc123.css",
+				"sri": "sha384-abc123",
+				"hash": "abc123",
+				"size": 1024,
+				"content_type": "text/css"
+			},
+			"static/js/app.js": {
+				"path": "/static/js/app.def456.js",
+				"sri": "sha384-def456",
+				"hash": "def456"
+			}
+		}}`)},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create asset provider: %v", err)
+	}
+	h := AssetManifest(provider)
+
+	req := httptest.NewRequest("GET", "/assets/manifest", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected JSON content type, got %s", ct)
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "no-cache" {
+		t.Errorf("Expected Cache-Control 'no-cache', got '%s'", cc)
+	}
+
+	var got assets.AssetManifest
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	if !reflect.DeepEqual(got, provider.GetManifest()) {
+		t.Errorf("Expected served manifest to match the loaded one, got %+v", got)
+	}
+
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag")
+	}
+
+	t.Run("matching ETag revalidates", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/assets/manifest", nil)
+		req.Header.Set("If-None-Match", etag)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotModified {
+			t.Errorf("Expected status 304, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"net/http"
+
+	"guitar-specs/internal/render"
+)
+
+// NotFound renders the HTML 404 page.
+func (p *Pages) NotFound(w http.ResponseWriter, r *http.Request) {
+	// Render template with request context; failures become a clean 500
+	render.HTMLStatus(w, r, p.render, "notfound", http.StatusNotFound, map[string]any{
+		"Title": "Page Not Found",
+	})
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"embed"
+	"errors"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"guitar-specs/internal/render"
+)
+
+// mockRenderer implements render.Renderer, recording the last render call.
+type mockRenderer struct {
+	err          error
+	templateName string
+	data         interface{}
+}
+
+func (m *mockRenderer) Render(w io.Writer, templateName string, data interface{}) error {
+	return m.RenderWithRequest(w, templateName, nil, data)
+}
+
+func (m *mockRenderer) RenderWithRequest(w io.Writer, templateName string, req *http.Request, data interface{}) error {
+	m.templateName = templateName
+	m.data = data
+	if m.err != nil {
+		return m.err
+	}
+	_, err := io.WriteString(w, "<h1>"+templateName+"</h1>")
+	return err
+}
+
+func (m *mockRenderer) RenderString(templateName string, data interface{}) (string, error) {
+	return "", m.Render(io.Discard, templateName, data)
+}
+
+func (m *mockRenderer) GetTemplate(name string) (*template.Template, error) { return nil, nil }
+
+func (m *mockRenderer) GetTemplates() map[string]*template.Template { return nil }
+
+func (m *mockRenderer) AddTemplate(name string, tmpl *template.Template) error { return nil }
+
+func (m *mockRenderer) HasTemplate(name string) bool { return true }
+
+var _ render.Renderer = (*mockRenderer)(nil)
+
+func TestStaticPages(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		handler  func(p *Pages) http.HandlerFunc
+		template string
+		title    string
+		status   int
+	}{
+		{"Home", "/", func(p *Pages) http.HandlerFunc { return p.Home }, "home", "Home", http.StatusOK},
+		{"About", "/about", func(p *Pages) http.HandlerFunc { return p.About }, "about", "About Us", http.StatusOK},
+		{"Contact", "/contact", func(p *Pages) http.HandlerFunc { return p.Contact }, "contact", "Contact", http.StatusOK},
+		{"NotFound", "/missing", func(p *Pages) http.HandlerFunc { return p.NotFound }, "notfound", "Page Not Found", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer := &mockRenderer{}
+			pages := New(renderer, embed.FS{}, nil)
+
+			w := httptest.NewRecorder()
+			tt.handler(pages)(w, httptest.NewRequest("GET", tt.target, nil))
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if renderer.templateName != tt.template {
+				t.Errorf("Expected template '%s', got '%s'", tt.template, renderer.templateName)
+			}
+			data, ok := renderer.data.(map[string]any)
+			if !ok {
+				t.Fatalf("Expected map data, got %T", renderer.data)
+			}
+			if data["Title"] != tt.title {
+				t.Errorf("Expected Title '%s', got '%v'", tt.title, data["Title"])
+			}
+			if w.Body.String() != "<h1>"+tt.template+"</h1>" {
+				t.Errorf("Expected rendered body, got '%s'", w.Body.String())
+			}
+		})
+
+		t.Run(tt.name+" renderer error", func(t *testing.T) {
+			renderer := &mockRenderer{err: errors.New("template exploded")}
+			pages := New(renderer, embed.FS{}, nil)
+
+			w := httptest.NewRecorder()
+			tt.handler(pages)(w, httptest.NewRequest("GET", tt.target, nil))
+
+			if w.Code != http.StatusInternalServerError {
+				t.Errorf("Expected status 500, got %d", w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	mw "guitar-specs/internal/http/middleware"
+)
+
+// precompressedExtensions maps each content coding to the extension of its
+// sibling file. The extension doubles as the ETag suffix.
+var precompressedExtensions = map[string]string{
+	"br":   ".br",
+	"gzip": ".gz",
+}
+
+// PrecompressedFileServer serves files from fsys like http.FileServer, but when
+// the client accepts it and a ".br" or ".gz" sibling exists (e.g. app.js.br), the
+// sibling is served with Content-Encoding instead. Every file gets a strong ETag
+// derived from the original content; encoded variants append the encoding
+// ("-br", "-gz") so a cached gzip body never revalidates against a brotli tag.
+func PrecompressedFileServer(fsys fs.FS) http.Handler {
+	return PrecompressedFileServerWithNotFound(fsys, nil)
+}
+
+// PrecompressedFileServerWithNotFound is PrecompressedFileServer with a fallback
+// for missing files: browser requests (Accept: text/html) for extensionless paths
+// are handed to notFound, typically the app's rendered 404 page. Asset-like paths
+// with an extension keep the plain 404, so a missing stylesheet is never answered
+// with HTML. A nil notFound always uses the plain 404.
+func PrecompressedFileServerWithNotFound(fsys fs.FS, notFound http.Handler) http.Handler {
+	files := http.FileServer(http.FS(fsys))
+	etags := &contentETags{fsys: fsys}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+
+		if notFound != nil && path.Ext(name) == "" {
+			// A miss here is answered differently depending on Accept
+			w.Header().Add("Vary", "Accept")
+			if acceptsHTML(r) && !exists(fsys, name) {
+				// The 404 page must not inherit long-lived caching meant for assets
+				w.Header().Del("Cache-Control")
+				notFound.ServeHTTP(w, r)
+				return
+			}
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		// Negotiate among the variants that exist, in the shared preference order
+		var available []string
+		for _, enc := range mw.PreferredEncodings {
+			if ext, ok := precompressedExtensions[enc]; ok && isRegularFile(fsys, name+ext) {
+				available = append(available, enc)
+			}
+		}
+		if enc := mw.NegotiateEncoding(r, available...); enc != "" {
+			if serveVariant(w, r, fsys, name, enc, etags) {
+				return
+			}
+		}
+
+		// http.FileServer honours a pre-set ETag for conditional requests
+		if tag, ok := etags.get(name); ok {
+			w.Header().Set("ETag", `"`+tag+`"`)
+		}
+		files.ServeHTTP(w, r)
+	})
+}
+
+// contentETags caches content hashes per file; embedded files never change.
+// Misses are not cached so arbitrary request paths cannot grow the map.
+type contentETags struct {
+	fsys  fs.FS
+	cache sync.Map // name -> string
+}
+
+func (c *contentETags) get(name string) (string, bool) {
+	if v, ok := c.cache.Load(name); ok {
+		return v.(string), true
+	}
+	if name == "" {
+		return "", false
+	}
+
+	b, err := fs.ReadFile(c.fsys, name)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(b)
+	tag := hex.EncodeToString(sum[:8])
+	c.cache.Store(name, tag)
+	return tag, true
+}
+
+// serveVariant writes the enc-encoded sibling of name, reporting false if it
+// cannot be opened as a seekable file.
+func serveVariant(w http.ResponseWriter, r *http.Request, fsys fs.FS, name, enc string, etags *contentETags) bool {
+	ext := precompressedExtensions[enc]
+	f, err := fsys.Open(name + ext)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	content, seekable := f.(io.ReadSeeker)
+	if err != nil || stat.IsDir() || !seekable {
+		return false
+	}
+
+	// The type describes the decoded body; never sniff the compressed bytes
+	ctype := mime.TypeByExtension(path.Ext(name))
+	if ctype == "" {
+		ctype = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", ctype)
+	w.Header().Set("Content-Encoding", enc)
+	if tag, ok := etags.get(name); ok {
+		w.Header().Set("ETag", `"`+tag+"-"+strings.TrimPrefix(ext, ".")+`"`)
+	}
+	http.ServeContent(w, r, name, stat.ModTime(), content)
+	return true
+}
+
+// isRegularFile reports whether name exists in fsys and is not a directory.
+func isRegularFile(fsys fs.FS, name string) bool {
+	stat, err := fs.Stat(fsys, name)
+	return err == nil && !stat.IsDir()
+}
+
+// exists reports whether name (a file or directory) exists in fsys.
+func exists(fsys fs.FS, name string) bool {
+	if name == "" {
+		return true
+	}
+	_, err := fs.Stat(fsys, name)
+	return err == nil
+}
+
+// acceptsHTML reports whether the client, typically a browser navigation, asks for HTML.
+func acceptsHTML(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
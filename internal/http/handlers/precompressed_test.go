@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestPrecompressedFileServer(t *testing.T) {
+	fsys := fstest.MapFS{
+		"js/app.js":    &fstest.MapFile{Data: []byte("console.log('app');")},
+		"js/app.js.br": &fstest.MapFile{Data: []byte("brotli-bytes")},
+		"js/app.js.gz": &fstest.MapFile{Data: []byte("gzip-bytes")},
+	}
+	handler := PrecompressedFileServer(fsys)
+
+	get := func(acceptEncoding, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/js/app.js", nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	br := get("gzip, br", "")
+	gz := get("gzip", "")
+	identity := get("", "")
+
+	t.Run("serves variants by preference", func(t *testing.T) {
+		if br.Header().Get("Content-Encoding") != "br" || br.Body.String() != "brotli-bytes" {
+			t.Errorf("Expected brotli variant, got encoding %q body %q", br.Header().Get("Content-Encoding"), br.Body.String())
+		}
+		if gz.Header().Get("Content-Encoding") != "gzip" || gz.Body.String() != "gzip-bytes" {
+			t.Errorf("Expected gzip variant, got encoding %q body %q", gz.Header().Get("Content-Encoding"), gz.Body.String())
+		}
+		if identity.Header().Get("Content-Encoding") != "" || identity.Body.String() != "console.log('app');" {
+			t.Errorf("Expected original file, got encoding %q body %q", identity.Header().Get("Content-Encoding"), identity.Body.String())
+		}
+		if ct := br.Header().Get("Content-Type"); ct != "text/javascript; charset=utf-8" {
+			t.Errorf("Expected Content-Type of the original file, got %s", ct)
+		}
+		if br.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("Expected Vary: Accept-Encoding, got %q", br.Header().Get("Vary"))
+		}
+	})
+
+	t.Run("ETags differ per encoding", func(t *testing.T) {
+		brTag, gzTag, idTag := br.Header().Get("ETag"), gz.Header().Get("ETag"), identity.Header().Get("ETag")
+		if brTag == "" || gzTag == "" || idTag == "" {
+			t.Fatalf("Expected ETags on all responses, got br=%q gz=%q identity=%q", brTag, gzTag, idTag)
+		}
+		if brTag == gzTag || brTag == idTag || gzTag == idTag {
+			t.Errorf("Expected distinct ETags, got br=%q gz=%q identity=%q", brTag, gzTag, idTag)
+		}
+		if brTag != idTag[:len(idTag)-1]+`-br"` {
+			t.Errorf("Expected brotli ETag to extend the original, got %q for %q", brTag, idTag)
+		}
+	})
+
+	t.Run("conditional requests do not cross encodings", func(t *testing.T) {
+		if w := get("gzip", gz.Header().Get("ETag")); w.Code != http.StatusNotModified {
+			t.Errorf("Expected 304 for matching gzip ETag, got %d", w.Code)
+		}
+		if w := get("br", gz.Header().Get("ETag")); w.Code != http.StatusOK {
+			t.Errorf("Expected 200 when revalidating brotli against a gzip ETag, got %d", w.Code)
+		}
+	})
+
+	t.Run("q=0 disables an encoding", func(t *testing.T) {
+		w := get("br;q=0, gzip", "")
+		if w.Header().Get("Content-Encoding") != "gzip" {
+			t.Errorf("Expected gzip when br is refused, got %q", w.Header().Get("Content-Encoding"))
+		}
+	})
+}
+
+func TestPrecompressedFileServer_NotFound(t *testing.T) {
+	fsys := fstest.MapFS{
+		"css/main.css": &fstest.MapFile{Data: []byte("body {}")},
+	}
+	notFound := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("<h1>Page not found</h1>"))
+	})
+	handler := PrecompressedFileServerWithNotFound(fsys, notFound)
+
+	browser := "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+	tests := []struct {
+		name        string
+		target      string
+		accept      string
+		wantStatus  int
+		wantType    string
+		wantHTML404 bool
+	}{
+		{name: "missing stylesheet from a browser", target: "/css/missing.css", accept: browser, wantStatus: http.StatusNotFound, wantType: "text/plain; charset=utf-8"},
+		{name: "missing page from a browser", target: "/page", accept: browser, wantStatus: http.StatusNotFound, wantType: "text/html; charset=utf-8", wantHTML404: true},
+		{name: "missing page from a non-browser", target: "/page", accept: "*/*", wantStatus: http.StatusNotFound, wantType: "text/plain; charset=utf-8"},
+		{name: "existing file", target: "/css/main.css", accept: browser, wantStatus: http.StatusOK, wantType: "text/css; charset=utf-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			req.Header.Set("Accept", tt.accept)
+			w := httptest.NewRecorder()
+			// The app sets asset caching before the file server runs
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != tt.wantType {
+				t.Errorf("Expected Content-Type '%s', got '%s'", tt.wantType, ct)
+			}
+			gotHTML404 := w.Body.String() == "<h1>Page not found</h1>"
+			if gotHTML404 != tt.wantHTML404 {
+				t.Errorf("Expected HTML 404 page %v, got body '%s'", tt.wantHTML404, w.Body.String())
+			}
+			if tt.wantHTML404 && w.Header().Get("Cache-Control") != "" {
+				t.Errorf("Expected asset caching to be dropped, got '%s'", w.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"guitar-specs/internal/models"
+)
+
+// RandomGuitar redirects to the detail page of a randomly chosen guitar.
+// The redirect must not be cached, otherwise browsers would keep landing on the same guitar.
+func (p *Pages) RandomGuitar(w http.ResponseWriter, r *http.Request) {
+	g, err := p.store.Guitars.Random(r.Context())
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, "Failed to pick a guitar", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, "/guitar/"+g.Slug, http.StatusFound)
+}
@@ -1,6 +1,12 @@
 package handlers
 
-import "net/http"
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+
+	mw "guitar-specs/internal/http/middleware"
+)
 
 func (p *Pages) RobotsTxt(w http.ResponseWriter, r *http.Request) {
 	b, err := p.robots.ReadFile("robots.txt")
@@ -8,7 +14,28 @@ func (p *Pages) RobotsTxt(w http.ResponseWriter, r *http.Request) {
 		http.NotFound(w, r)
 		return
 	}
+	if baseURL, ok := mw.PublicBaseURLFromContext(r.Context()); ok {
+		b = rewriteSitemapLines(b, baseURL)
+	}
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write(b)
 }
+
+// rewriteSitemapLines points each "Sitemap:" directive at the public base URL,
+// keeping the path from the embedded file.
+func rewriteSitemapLines(b []byte, baseURL string) []byte {
+	lines := bytes.Split(b, []byte("\n"))
+	for i, line := range lines {
+		name, value, found := bytes.Cut(line, []byte(":"))
+		if !found || !bytes.EqualFold(bytes.TrimSpace(name), []byte("Sitemap")) {
+			continue
+		}
+		u, err := url.Parse(string(bytes.TrimSpace(value)))
+		if err != nil {
+			continue
+		}
+		lines[i] = []byte("Sitemap: " + baseURL + u.Path)
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
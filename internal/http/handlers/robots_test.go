@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"testing"
+)
+
+func TestRewriteSitemapLines(t *testing.T) {
+	in := []byte("User-agent: *\nAllow: /\nSitemap: https://example.com/sitemap.xml\n")
+
+	got := string(rewriteSitemapLines(in, "https://guitar-specs.example.com"))
+
+	expected := "User-agent: *\nAllow: /\nSitemap: https://guitar-specs.example.com/sitemap.xml\n"
+	if got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"guitar-specs/internal/assets"
+)
+
+// RootAsset returns a handler for root-level files browsers request by convention,
+// such as /favicon.ico and /site.webmanifest. When the asset provider knows the
+// asset, the request is redirected to its fingerprinted URL under /static/, which
+// is served with immutable caching. The redirect itself is only cached briefly
+// because the fingerprint changes on every frontend build. When the asset is
+// unknown, fallbackStatus is returned (204 for favicons silences browser retries).
+func RootAsset(provider assets.AssetProvider, assetPath string, fallbackStatus int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if provider == nil || !provider.HasAsset(assetPath) {
+			if fallbackStatus == http.StatusNotFound {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Cache-Control", "public, max-age=86400")
+			w.WriteHeader(fallbackStatus)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		http.Redirect(w, r, provider.AssetURL(assetPath), http.StatusFound)
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"guitar-specs/internal/assets"
+)
+
+// stubAssets implements assets.AssetProvider over a fixed path-to-URL map.
+type stubAssets map[string]string
+
+func (s stubAssets) AssetURL(path string) string {
+	if url, ok := s[path]; ok {
+		return url
+	}
+	return path
+}
+
+func (s stubAssets) AssetQueryURL(path string) string { return path }
+
+func (s stubAssets) AssetSRI(path string) string { return "" }
+
+func (s stubAssets) GetManifest() assets.AssetManifest { return assets.AssetManifest{} }
+
+func (s stubAssets) HasAsset(path string) bool {
+	_, ok := s[path]
+	return ok
+}
+
+func (s stubAssets) GetAssetInfo(path string) (assets.AssetInfo, bool) {
+	return assets.AssetInfo{}, false
+}
+
+func TestRootAsset(t *testing.T) {
+	provider := stubAssets{"/static/favicon.ico": "/static/favicon.abc123.ico"}
+
+	t.Run("redirects to fingerprinted asset", func(t *testing.T) {
+		h := RootAsset(provider, "/static/favicon.ico", http.StatusNoContent)
+		req := httptest.NewRequest("GET", "/favicon.ico", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusFound {
+			t.Errorf("Expected status 302, got %d", w.Code)
+		}
+		if loc := w.Header().Get("Location"); loc != "/static/favicon.abc123.ico" {
+			t.Errorf("Expected fingerprinted location, got '%s'", loc)
+		}
+	})
+
+	t.Run("missing favicon falls back to 204", func(t *testing.T) {
+		h := RootAsset(stubAssets{}, "/static/favicon.ico", http.StatusNoContent)
+		req := httptest.NewRequest("GET", "/favicon.ico", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Errorf("Expected status 204, got %d", w.Code)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("Expected empty body, got %q", w.Body.String())
+		}
+	})
+
+	t.Run("missing manifest falls back to 404", func(t *testing.T) {
+		h := RootAsset(nil, "/static/site.webmanifest", http.StatusNotFound)
+		req := httptest.NewRequest("GET", "/site.webmanifest", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"guitar-specs/internal/models"
+	"guitar-specs/internal/render"
+)
+
+// ShapeDetail renders a body shape landing page with all guitars of that shape.
+// Path expected: /shape/{slug}
+func (p *Pages) ShapeDetail(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+	if slug == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	shape, guitars, err := p.store.Guitars.GetShapeWithGuitars(r.Context(), slug)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, "Failed to load shape", http.StatusInternalServerError)
+		return
+	}
+
+	// Render template with request context; failures become a clean 500
+	render.HTML(w, r, p.render, "shape", map[string]any{
+		"Title":   shape.Name,
+		"shape":   shape,
+		"guitars": guitars,
+	})
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"guitar-specs/internal/models"
+	"guitar-specs/internal/render"
+)
+
+// Typed render helpers for the catalogue pages. Each pairs a template with the
+// data shape it reads, so a handler passing the wrong data fails to compile
+// instead of rendering a broken page.
+
+// RenderGuitarList renders the "guitars" template. featureKey and featureValue
+// describe the active filter and are empty for the full catalogue.
+func (p *Pages) RenderGuitarList(w http.ResponseWriter, r *http.Request, guitars []models.Guitar, featureKey, featureValue string) {
+	render.HTML(w, r, p.render, "guitars", map[string]any{
+		"Title":        "Guitars",
+		"guitars":      guitars,
+		"featureKey":   featureKey,
+		"featureValue": featureValue,
+	})
+}
+
+// RenderGuitarDetail renders the "guitar" template for g with features. g is
+// copied rather than modified, since detail lookups may share it between requests.
+func (p *Pages) RenderGuitarDetail(w http.ResponseWriter, r *http.Request, g *models.Guitar, features []models.GuitarFeatureResolved) {
+	guitar := *g
+	guitar.Features = features
+	render.HTML(w, r, p.render, "guitar", map[string]any{
+		"Title":  guitar.BrandName + " " + guitar.Model,
+		"guitar": &guitar,
+	})
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"embed"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"guitar-specs/internal/models"
+)
+
+func TestRenderGuitarList(t *testing.T) {
+	renderer := &mockRenderer{}
+	pages := New(renderer, embed.FS{}, nil)
+	list := []models.Guitar{{Slug: "strat"}, {Slug: "tele"}}
+
+	w := httptest.NewRecorder()
+	pages.RenderGuitarList(w, httptest.NewRequest("GET", "/guitars", nil), list, "pickups", "hh")
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if renderer.templateName != "guitars" {
+		t.Errorf("Expected template 'guitars', got '%s'", renderer.templateName)
+	}
+	data, ok := renderer.data.(map[string]any)
+	if !ok {
+		t.Fatalf("Expected map data, got %T", renderer.data)
+	}
+	if got, _ := data["guitars"].([]models.Guitar); len(got) != 2 {
+		t.Errorf("Expected 2 guitars, got %v", data["guitars"])
+	}
+	if data["Title"] != "Guitars" || data["featureKey"] != "pickups" || data["featureValue"] != "hh" {
+		t.Errorf("Expected title and filter in data, got %v", data)
+	}
+}
+
+func TestRenderGuitarDetail(t *testing.T) {
+	g := &models.Guitar{BrandName: "Fender", Model: "Stratocaster"}
+	features := []models.GuitarFeatureResolved{{FeatureKey: "pickups"}}
+
+	t.Run("renders the guitar with its features", func(t *testing.T) {
+		renderer := &mockRenderer{}
+		pages := New(renderer, embed.FS{}, nil)
+
+		w := httptest.NewRecorder()
+		pages.RenderGuitarDetail(w, httptest.NewRequest("GET", "/guitar/strat", nil), g, features)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+		if renderer.templateName != "guitar" {
+			t.Errorf("Expected template 'guitar', got '%s'", renderer.templateName)
+		}
+		data, ok := renderer.data.(map[string]any)
+		if !ok {
+			t.Fatalf("Expected map data, got %T", renderer.data)
+		}
+		if data["Title"] != "Fender Stratocaster" {
+			t.Errorf("Expected title 'Fender Stratocaster', got %v", data["Title"])
+		}
+		rendered, _ := data["guitar"].(*models.Guitar)
+		if rendered == nil || len(rendered.Features) != 1 {
+			t.Errorf("Expected the guitar with 1 feature, got %v", data["guitar"])
+		}
+		if g.Features != nil {
+			t.Error("Expected the caller's guitar to be left unmodified")
+		}
+	})
+
+	t.Run("render failure is a clean 500", func(t *testing.T) {
+		pages := New(&mockRenderer{err: errors.New("boom")}, embed.FS{}, nil)
+
+		w := httptest.NewRecorder()
+		pages.RenderGuitarDetail(w, httptest.NewRequest("GET", "/guitar/strat", nil), g, features)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+	})
+}
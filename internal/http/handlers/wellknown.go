@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+)
+
+// SecurityTxt returns a handler serving an RFC 9116 security.txt built from the
+// given contacts, expiry date (RFC3339) and optional policy URL. The body is
+// assembled once, since it only depends on configuration.
+func SecurityTxt(contacts []string, expires string, policy string) http.HandlerFunc {
+	var b strings.Builder
+	for _, c := range contacts {
+		b.WriteString("Contact: " + c + "\n")
+	}
+	b.WriteString("Expires: " + expires + "\n")
+	if policy != "" {
+		b.WriteString("Policy: " + policy + "\n")
+	}
+	body := []byte(b.String())
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(body)
+	}
+}
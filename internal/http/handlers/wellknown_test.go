@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSecurityTxt(t *testing.T) {
+	h := SecurityTxt(
+		[]string{"mailto:security@example.com", "https://example.com/report"},
+		"2027-01-01T00:00:00Z",
+		"https://example.com/policy",
+	)
+
+	req := httptest.NewRequest("GET", "/.well-known/security.txt", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("Expected text/plain content type, got '%s'", ct)
+	}
+
+	expected := "Contact: mailto:security@example.com\n" +
+		"Contact: https://example.com/report\n" +
+		"Expires: 2027-01-01T00:00:00Z\n" +
+		"Policy: https://example.com/policy\n"
+	if w.Body.String() != expected {
+		t.Errorf("Expected body:\n%s\ngot:\n%s", expected, w.Body.String())
+	}
+}
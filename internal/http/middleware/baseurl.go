@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// PublicBaseURL makes the site's public absolute URL (e.g. https://guitar-specs.example.com)
+// available to handlers and templates through the request context. The app binds to an
+// internal address, so absolute links (sitemap, canonical, OpenGraph) must come from config.
+// An empty baseURL disables the middleware.
+func PublicBaseURL(baseURL string) func(http.Handler) http.Handler {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return func(next http.Handler) http.Handler {
+		if baseURL == "" {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(WithPublicBaseURL(r.Context(), baseURL)))
+		})
+	}
+}
+
+// publicBaseURLKey is an unexported type to avoid context key collisions.
+type publicBaseURLKey struct{}
+
+// WithPublicBaseURL stores the public base URL in the context.
+func WithPublicBaseURL(ctx context.Context, baseURL string) context.Context {
+	return context.WithValue(ctx, publicBaseURLKey{}, baseURL)
+}
+
+// PublicBaseURLFromContext retrieves the public base URL from the context.
+func PublicBaseURLFromContext(ctx context.Context) (string, bool) {
+	v := ctx.Value(publicBaseURLKey{})
+	if v == nil {
+		return "", false
+	}
+	s, ok := v.(string)
+	return s, ok
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// WithStaleDirectives appends the RFC 5861 stale-while-revalidate and
+// stale-if-error extensions to a Cache-Control value, in whole seconds, e.g.
+// "public, max-age=60, stale-while-revalidate=300". Zero durations are omitted,
+// as is everything when value is empty.
+func WithStaleDirectives(value string, staleWhileRevalidate, staleIfError time.Duration) string {
+	if value == "" {
+		return ""
+	}
+	if s := int64(staleWhileRevalidate / time.Second); s > 0 {
+		value += ", stale-while-revalidate=" + strconv.FormatInt(s, 10)
+	}
+	if s := int64(staleIfError / time.Second); s > 0 {
+		value += ", stale-if-error=" + strconv.FormatInt(s, 10)
+	}
+	return value
+}
+
+// DefaultCacheControl sets Cache-Control to value on successful and redirect
+// responses (status below 400) that carry none, giving one policy for a group
+// of handlers. A handler that sets its own Cache-Control keeps it, and error
+// responses are left without one so a transient failure is never cached.
+// An empty value disables the middleware.
+func DefaultCacheControl(value string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if value == "" {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(&cacheControlWriter{ResponseWriter: w, value: value}, r)
+		})
+	}
+}
+
+// cacheControlWriter applies the default just before the status line is written.
+type cacheControlWriter struct {
+	http.ResponseWriter
+	value       string
+	wroteHeader bool
+}
+
+func (cw *cacheControlWriter) WriteHeader(code int) {
+	if !cw.wroteHeader {
+		cw.wroteHeader = true
+		if h := cw.ResponseWriter.Header(); code < http.StatusBadRequest && h.Get("Cache-Control") == "" {
+			h.Set("Cache-Control", cw.value)
+		}
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *cacheControlWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	return cw.ResponseWriter.Write(b)
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDefaultCacheControl(t *testing.T) {
+	const policy = "public, max-age=60"
+
+	tests := []struct {
+		name    string
+		value   string
+		handler http.HandlerFunc
+		want    string
+	}{
+		{
+			name:  "sets the default",
+			value: policy,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("<html></html>"))
+			},
+			want: policy,
+		},
+		{
+			name:  "keeps the handler's policy",
+			value: policy,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", "no-store")
+				w.WriteHeader(http.StatusOK)
+			},
+			want: "no-store",
+		},
+		{
+			name:  "leaves errors uncached",
+			value: policy,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			},
+			want: "",
+		},
+		{
+			name: "empty value is a no-op",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			DefaultCacheControl(tt.value)(tt.handler).ServeHTTP(w, httptest.NewRequest("GET", "/about", nil))
+
+			if got := w.Header().Get("Cache-Control"); got != tt.want {
+				t.Errorf("Expected Cache-Control '%s', got '%s'", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWithStaleDirectives(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		swr   time.Duration
+		sie   time.Duration
+		want  string
+	}{
+		{name: "both directives", value: "public, max-age=60", swr: 5 * time.Minute, sie: 24 * time.Hour, want: "public, max-age=60, stale-while-revalidate=300, stale-if-error=86400"},
+		{name: "revalidate only", value: "public, max-age=60", swr: 300 * time.Second, want: "public, max-age=60, stale-while-revalidate=300"},
+		{name: "zero durations omitted", value: "no-cache", want: "no-cache"},
+		{name: "empty value stays empty", swr: time.Minute, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WithStaleDirectives(tt.value, tt.swr, tt.sie); got != tt.want {
+				t.Errorf("Expected '%s', got '%s'", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// canonicalHostSkipPaths are served on any host so load balancers and uptime
+// checks can reach the app by IP or internal name.
+var canonicalHostSkipPaths = map[string]bool{
+	"/healthz": true,
+}
+
+// CanonicalHost permanently redirects requests whose Host differs from host
+// (e.g. www.example.com, or an old domain) to the same path and query on host
+// over HTTPS. The default port is dropped, so "example.com:443" redirects to
+// https://example.com/. An empty host disables the middleware.
+func CanonicalHost(host string) func(http.Handler) http.Handler {
+	canonical := normalizeHost(host)
+	return func(next http.Handler) http.Handler {
+		if canonical == "" {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if canonicalHostSkipPaths[r.URL.Path] || normalizeHost(r.Host) == canonical {
+				next.ServeHTTP(w, r)
+				return
+			}
+			http.Redirect(w, r, "https://"+canonical+r.URL.RequestURI(), http.StatusMovedPermanently)
+		})
+	}
+}
+
+// normalizeHost lower-cases host and strips the default HTTPS port.
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, port, err := net.SplitHostPort(host); err == nil && port == "443" {
+		// Keep IPv6 literals bracketed
+		if strings.Contains(h, ":") {
+			return "[" + h + "]"
+		}
+		return h
+	}
+	return host
+}
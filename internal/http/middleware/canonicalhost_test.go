@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCanonicalHost(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+
+	tests := []struct {
+		name         string
+		canonical    string
+		host         string
+		target       string
+		wantStatus   int
+		wantLocation string
+	}{
+		{
+			name:         "redirects www to apex",
+			canonical:    "example.com",
+			host:         "www.example.com",
+			target:       "/guitars?brand=fender",
+			wantStatus:   http.StatusMovedPermanently,
+			wantLocation: "https://example.com/guitars?brand=fender",
+		},
+		{name: "matching host passes through", canonical: "example.com", host: "example.com", target: "/guitars", wantStatus: http.StatusOK},
+		{name: "host comparison ignores case", canonical: "example.com", host: "Example.COM", target: "/", wantStatus: http.StatusOK},
+		{name: "default port is implied", canonical: "example.com", host: "example.com:443", target: "/", wantStatus: http.StatusOK},
+		{
+			name:         "default port is not forced into the redirect",
+			canonical:    "example.com:443",
+			host:         "old.example.net",
+			target:       "/about",
+			wantStatus:   http.StatusMovedPermanently,
+			wantLocation: "https://example.com/about",
+		},
+		{
+			name:         "non-default port is kept",
+			canonical:    "localhost:8443",
+			host:         "127.0.0.1:8443",
+			target:       "/",
+			wantStatus:   http.StatusMovedPermanently,
+			wantLocation: "https://localhost:8443/",
+		},
+		{name: "health check is not redirected", canonical: "example.com", host: "10.0.0.5:8080", target: "/healthz", wantStatus: http.StatusOK},
+		{name: "disabled when empty", canonical: "", host: "www.example.com", target: "/", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			req.Host = tt.host
+			w := httptest.NewRecorder()
+
+			CanonicalHost(tt.canonical)(handler).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if loc := w.Header().Get("Location"); loc != tt.wantLocation {
+				t.Errorf("Expected Location '%s', got '%s'", tt.wantLocation, loc)
+			}
+		})
+	}
+}
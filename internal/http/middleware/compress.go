@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// DefaultCompressLevel balances CPU cost against response size for HTML pages.
+// It is the COMPRESS_LEVEL default.
+const DefaultCompressLevel = 5
+
+// ClampCompressLevel bounds a compression level to the range of encoding, 0–11
+// for brotli ("br") and 1–9 for gzip, and reports whether it was changed.
+func ClampCompressLevel(encoding string, level int) (int, bool) {
+	lo, hi := gzip.BestSpeed, gzip.BestCompression
+	if encoding == "br" {
+		lo, hi = brotli.BestSpeed, brotli.BestCompression
+	}
+	switch {
+	case level < lo:
+		return lo, true
+	case level > hi:
+		return hi, true
+	default:
+		return level, false
+	}
+}
+
+// Compress encodes text responses with brotli or gzip, negotiated in the same
+// preference order as precompressed static files (see PreferredEncodings). The
+// level is used as the brotli quality (0–11) and the gzip level (1–9), each
+// clamped to its encoding's range; level 0 therefore only lowers brotli and
+// levels 10 and 11 only raise it, while a level outside 0–11 is clamped with a
+// logged warning rather than failing. Responses that already carry a Content-Encoding or have no body
+// (204, 304) pass through. HEAD is encoded like GET so its headers match;
+// HeadNoBody (or the server) drops the body. A strong ETag on a compressed
+// response is weakened (W/"...").
+func Compress(logger *slog.Logger, level int) func(http.Handler) http.Handler {
+	brLevel, brChanged := ClampCompressLevel("br", level)
+	gzLevel, _ := ClampCompressLevel("gzip", level)
+	// gzip's narrower range within brotli's is expected, not a misconfiguration
+	if brChanged {
+		if logger != nil {
+			logger.Warn("compression level out of range, clamping", "level", level, "br", brLevel, "gzip", gzLevel)
+		}
+	}
+
+	// Writers are pooled per coding; gzip.NewWriterLevel only fails on invalid levels
+	pools := map[string]*sync.Pool{
+		"br": {New: func() any {
+			return brotli.NewWriterLevel(io.Discard, brLevel)
+		}},
+		"gzip": {New: func() any {
+			gz, _ := gzip.NewWriterLevel(io.Discard, gzLevel)
+			return gz
+		}},
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoding := NegotiateEncoding(r, PreferredEncodings...)
+			if encoding == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, encoding: encoding, pool: pools[encoding], path: r.URL.Path}
+			defer cw.Close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// encoder is implemented by both *gzip.Writer and *brotli.Writer.
+type encoder interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// compressibleType reports whether a Content-Type is worth compressing.
+func compressibleType(ct string) bool {
+	ct = strings.ToLower(ct)
+	return strings.HasPrefix(ct, "text/") ||
+		strings.Contains(ct, "javascript") ||
+		strings.Contains(ct, "json") ||
+		strings.Contains(ct, "xml") ||
+		strings.HasPrefix(ct, "image/svg")
+}
+
+// compressWriter decides on the first write whether to encode the body. When the
+// handler has not set a Content-Type, the decision waits for the first body bytes
+// so the type can be derived from the path extension or sniffed from the content;
+// otherwise e.g. CSS would be sniffed as text/plain after the decision was made.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding    string // negotiated Content-Encoding
+	pool        *sync.Pool
+	path        string
+	enc         encoder
+	pendingCode int // status held back until the Content-Type is known
+	wroteHeader bool
+}
+
+// WriteHeader enables compression when the response is eligible. As with
+// net/http, only the first status counts: a call after the first Write (which
+// committed an implicit 200 and the encoding headers) is ignored, so the
+// encoder keeps writing to a response whose headers match its output.
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.wroteHeader || cw.pendingCode != 0 {
+		return
+	}
+	if cw.Header().Get("Content-Type") == "" && bodyAllowed(code) {
+		cw.pendingCode = code
+		return
+	}
+	cw.writeHeader(code)
+}
+
+// writeHeader sends the status, switching to the negotiated encoding if the response qualifies.
+func (cw *compressWriter) writeHeader(code int) {
+	cw.wroteHeader = true
+
+	h := cw.Header()
+	if bodyAllowed(code) && h.Get("Content-Encoding") == "" && compressibleType(h.Get("Content-Type")) {
+		h.Set("Content-Encoding", cw.encoding)
+		h.Del("Content-Length")
+		// A strong ETag names the exact bytes; the encoded body is a different representation
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		cw.enc = cw.pool.Get().(encoder)
+		cw.enc.Reset(cw.ResponseWriter)
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+// Write encodes the body when compression was enabled.
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		if cw.Header().Get("Content-Type") == "" {
+			cw.Header().Set("Content-Type", detectContentType(cw.path, b))
+		}
+		code := cw.pendingCode
+		if code == 0 {
+			code = http.StatusOK
+		}
+		cw.writeHeader(code)
+	}
+	if cw.enc != nil {
+		return cw.enc.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// Close flushes the encoded stream and returns the writer to the pool.
+// A status held back for a body that never came is sent as-is.
+func (cw *compressWriter) Close() {
+	if !cw.wroteHeader && cw.pendingCode != 0 {
+		cw.wroteHeader = true
+		cw.ResponseWriter.WriteHeader(cw.pendingCode)
+	}
+	if cw.enc == nil {
+		return
+	}
+	_ = cw.enc.Close()
+	cw.pool.Put(cw.enc)
+	cw.enc = nil
+}
+
+// bodyAllowed reports whether a response with this status may carry a body.
+func bodyAllowed(code int) bool {
+	return code != http.StatusNoContent && code != http.StatusNotModified && code >= http.StatusOK
+}
+
+// detectContentType infers a Content-Type from the request path's extension,
+// falling back to sniffing the body like net/http does.
+func detectContentType(urlPath string, b []byte) string {
+	if ct := mime.TypeByExtension(path.Ext(urlPath)); ct != "" {
+		return ct
+	}
+	return http.DetectContentType(b)
+}
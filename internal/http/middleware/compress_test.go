@@ -0,0 +1,280 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestClampCompressLevel(t *testing.T) {
+	tests := []struct {
+		encoding string
+		level    int
+		want     int
+		changed  bool
+	}{
+		{"gzip", -3, 1, true},
+		{"gzip", 0, 1, true},
+		{"gzip", 1, 1, false},
+		{"gzip", 5, 5, false},
+		{"gzip", 9, 9, false},
+		{"gzip", 11, 9, true},
+		{"br", -3, 0, true},
+		{"br", 0, 0, false},
+		{"br", 5, 5, false},
+		{"br", 11, 11, false},
+		{"br", 12, 11, true},
+	}
+
+	for _, tt := range tests {
+		got, changed := ClampCompressLevel(tt.encoding, tt.level)
+		if got != tt.want || changed != tt.changed {
+			t.Errorf("ClampCompressLevel(%q, %d): Expected (%d, %v), got (%d, %v)", tt.encoding, tt.level, tt.want, tt.changed, got, changed)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	body := strings.Repeat("<p>guitar</p>", 100)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(body))
+	})
+
+	t.Run("out-of-range level is clamped with a warning", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+		h := Compress(logger, 42)(handler)
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if !strings.Contains(buf.String(), "clamping") {
+			t.Errorf("Expected clamp warning to be logged, got: %s", buf.String())
+		}
+		if w.Header().Get("Content-Encoding") != "gzip" {
+			t.Fatalf("Expected gzip encoding, got '%s'", w.Header().Get("Content-Encoding"))
+		}
+
+		zr, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatalf("Expected valid gzip body, got %v", err)
+		}
+		got, _ := io.ReadAll(zr)
+		if string(got) != body {
+			t.Errorf("Expected decompressed body to match")
+		}
+	})
+
+	t.Run("brotli-only levels do not warn", func(t *testing.T) {
+		for _, level := range []int{0, 11} {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+			h := Compress(logger, level)(handler)
+			for _, encoding := range []string{"br", "gzip"} {
+				req := httptest.NewRequest("GET", "/", nil)
+				req.Header.Set("Accept-Encoding", encoding)
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, req)
+
+				if got := w.Header().Get("Content-Encoding"); got != encoding {
+					t.Errorf("Expected %s encoding at level %d, got '%s'", encoding, level, got)
+				}
+			}
+			if strings.Contains(buf.String(), "clamping") {
+				t.Errorf("Expected no clamp warning for brotli quality %d, got: %s", level, buf.String())
+			}
+		}
+	})
+
+	t.Run("brotli is preferred when accepted", func(t *testing.T) {
+		h := Compress(nil, DefaultCompressLevel)(handler)
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Header().Get("Content-Encoding") != "br" {
+			t.Fatalf("Expected br encoding, got '%s'", w.Header().Get("Content-Encoding"))
+		}
+		got, err := io.ReadAll(brotli.NewReader(w.Body))
+		if err != nil {
+			t.Fatalf("Expected valid brotli body, got %v", err)
+		}
+		if string(got) != body {
+			t.Errorf("Expected decompressed body to match")
+		}
+	})
+
+	t.Run("client without gzip gets identity", func(t *testing.T) {
+		h := Compress(nil, DefaultCompressLevel)(handler)
+		req := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Header().Get("Content-Encoding") != "" {
+			t.Errorf("Expected no encoding, got '%s'", w.Header().Get("Content-Encoding"))
+		}
+		if w.Body.String() != body {
+			t.Errorf("Expected uncompressed body")
+		}
+		if w.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("Expected Vary: Accept-Encoding, got '%s'", w.Header().Get("Vary"))
+		}
+	})
+}
+
+func TestCompress_UntypedResponses(t *testing.T) {
+	css := strings.Repeat("body { color: #333; }\n", 50)
+
+	t.Run("css without content type is typed by extension and compressed", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(css))
+		})
+
+		req := httptest.NewRequest("GET", "/static/css/main.css", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		Compress(nil, DefaultCompressLevel)(handler).ServeHTTP(w, req)
+
+		if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/css") {
+			t.Errorf("Expected text/css content type, got '%s'", w.Header().Get("Content-Type"))
+		}
+		if w.Header().Get("Content-Encoding") != "gzip" {
+			t.Fatalf("Expected gzip encoding, got '%s'", w.Header().Get("Content-Encoding"))
+		}
+		zr, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatalf("Expected valid gzip body, got %v", err)
+		}
+		got, _ := io.ReadAll(zr)
+		if string(got) != css {
+			t.Errorf("Expected decompressed body to match")
+		}
+	})
+
+	t.Run("unknown extension falls back to sniffing", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("<!DOCTYPE html><html><body>hello</body></html>"))
+		})
+
+		req := httptest.NewRequest("GET", "/page", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		Compress(nil, DefaultCompressLevel)(handler).ServeHTTP(w, req)
+
+		if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+			t.Errorf("Expected sniffed text/html, got '%s'", w.Header().Get("Content-Type"))
+		}
+		if w.Header().Get("Content-Encoding") != "gzip" {
+			t.Errorf("Expected gzip encoding, got '%s'", w.Header().Get("Content-Encoding"))
+		}
+	})
+
+	t.Run("status without body is still sent", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		})
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		Compress(nil, DefaultCompressLevel)(handler).ServeHTTP(w, req)
+
+		if w.Code != http.StatusAccepted {
+			t.Errorf("Expected status 202, got %d", w.Code)
+		}
+		if w.Header().Get("Content-Encoding") != "" {
+			t.Errorf("Expected no encoding for empty body, got '%s'", w.Header().Get("Content-Encoding"))
+		}
+	})
+}
+
+func TestCompress_HeaderOrdering(t *testing.T) {
+	first := strings.Repeat("<p>first</p>", 50)
+	second := strings.Repeat("<p>second</p>", 50)
+
+	decode := func(t *testing.T, res *http.Response) string {
+		t.Helper()
+		if res.Header.Get("Content-Encoding") != "gzip" {
+			t.Fatalf("Expected gzip encoding, got '%s'", res.Header.Get("Content-Encoding"))
+		}
+		zr, err := gzip.NewReader(res.Body)
+		if err != nil {
+			t.Fatalf("Expected valid gzip body, got %v", err)
+		}
+		got, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("Expected complete gzip stream, got %v", err)
+		}
+		return string(got)
+	}
+
+	t.Run("WriteHeader after writes does not break the encoding", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(first))
+			// Too late: the implicit 200 and its headers are already committed
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("X-Late", "1")
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(second))
+		})
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		Compress(nil, DefaultCompressLevel)(handler).ServeHTTP(w, req)
+		res := w.Result()
+
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("Expected committed status 200, got %d", res.StatusCode)
+		}
+		if ct := res.Header.Get("Content-Type"); ct != "text/html; charset=utf-8" {
+			t.Errorf("Expected committed Content-Type, got '%s'", ct)
+		}
+		if res.Header.Get("X-Late") != "" {
+			t.Error("Expected headers set after the first write to be dropped")
+		}
+		if got := decode(t, res); got != first+second {
+			t.Errorf("Expected both writes in the decoded body, got %d bytes", len(got))
+		}
+	})
+
+	t.Run("explicit WriteHeader before writes keeps the status", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Content-Length", "999")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(first))
+			w.WriteHeader(http.StatusTeapot)
+		})
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		Compress(nil, DefaultCompressLevel)(handler).ServeHTTP(w, req)
+		res := w.Result()
+
+		if res.StatusCode != http.StatusCreated {
+			t.Errorf("Expected status 201, got %d", res.StatusCode)
+		}
+		if res.Header.Get("Content-Length") != "" {
+			t.Errorf("Expected stale Content-Length to be dropped, got '%s'", res.Header.Get("Content-Length"))
+		}
+		if got := decode(t, res); got != first {
+			t.Errorf("Expected decoded body to match, got %d bytes", len(got))
+		}
+	})
+}
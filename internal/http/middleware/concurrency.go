@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ConcurrencyLimit caps the number of requests processed at the same time.
+// Unlike rate limiting it bounds in-flight work, protecting the database from
+// thundering herds. When all slots are taken the request either waits up to
+// queueTimeout for a free slot or, with a zero queueTimeout, is rejected
+// immediately with 503 and a Retry-After hint. A non-positive max disables the limit.
+func ConcurrencyLimit(max int, queueTimeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if max <= 0 {
+			return next
+		}
+
+		// Buffered channel acts as a counting semaphore
+		sem := make(chan struct{}, max)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !acquireSlot(sem, r, queueTimeout) {
+				w.Header().Set("Retry-After", "1")
+				respondError(w, r, http.StatusServiceUnavailable, "Service Unavailable")
+				return
+			}
+			// Deferred so the slot is returned even if the handler panics
+			defer func() { <-sem }()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// acquireSlot tries to take a semaphore slot, optionally waiting for one.
+func acquireSlot(sem chan struct{}, r *http.Request, queueTimeout time.Duration) bool {
+	select {
+	case sem <- struct{}{}:
+		return true
+	default:
+	}
+
+	if queueTimeout <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// PerIPConcurrencyLimit caps the number of in-flight requests from a single client
+// IP, rejecting the excess with 429. Where ConcurrencyLimit protects the server as
+// a whole, this stops one client holding many slow connections open. It must run
+// after RealIP so proxied clients are told apart. A non-positive max disables it.
+func PerIPConcurrencyLimit(max int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if max <= 0 {
+			return next
+		}
+
+		var mu sync.Mutex
+		inFlight := make(map[string]int)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := clientIP(r)
+
+			mu.Lock()
+			if inFlight[ip] >= max {
+				mu.Unlock()
+				w.Header().Set("Retry-After", "1")
+				respondError(w, r, http.StatusTooManyRequests, "Too Many Requests")
+				return
+			}
+			inFlight[ip]++
+			mu.Unlock()
+
+			// Deferred so the counter drops even if the handler panics
+			defer func() {
+				mu.Lock()
+				if inFlight[ip]--; inFlight[ip] <= 0 {
+					delete(inFlight, ip)
+				}
+				mu.Unlock()
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP returns the client address without its port. RealIP leaves either a
+// bare IP from a proxy header or the original host:port in RemoteAddr.
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,238 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimit(t *testing.T) {
+	t.Run("rejects request over the limit immediately", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{}, 2)
+		blockingHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-release
+			w.WriteHeader(http.StatusOK)
+		})
+
+		middleware := ConcurrencyLimit(2, 0)(blockingHandler)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+			}()
+		}
+		<-started
+		<-started
+
+		w := httptest.NewRecorder()
+		middleware.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status 503, got %d", w.Code)
+		}
+		if value := w.Header().Get("Retry-After"); value != "1" {
+			t.Errorf("Expected Retry-After '1', got '%s'", value)
+		}
+
+		close(release)
+		wg.Wait()
+	})
+
+	t.Run("queues request until a slot frees up", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{}, 1)
+		var calls int
+		var mu sync.Mutex
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			calls++
+			first := calls == 1
+			mu.Unlock()
+			if first {
+				started <- struct{}{}
+				<-release
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+
+		middleware := ConcurrencyLimit(1, time.Second)(handler)
+
+		go middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+		<-started
+
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			close(release)
+		}()
+
+		w := httptest.NewRecorder()
+		middleware.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected queued request to succeed with 200, got %d", w.Code)
+		}
+	})
+
+	t.Run("rejects queued request after queue timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{}, 1)
+		blockingHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-release
+		})
+
+		middleware := ConcurrencyLimit(1, 20*time.Millisecond)(blockingHandler)
+
+		go middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+		<-started
+
+		w := httptest.NewRecorder()
+		middleware.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status 503 after queue timeout, got %d", w.Code)
+		}
+
+		close(release)
+	})
+
+	t.Run("releases slot when handler panics", func(t *testing.T) {
+		panicking := true
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if panicking {
+				panic("boom")
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+
+		middleware := ConcurrencyLimit(1, 0)(handler)
+
+		func() {
+			defer func() { _ = recover() }()
+			middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+		}()
+
+		panicking = false
+		w := httptest.NewRecorder()
+		middleware.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected slot to be released after panic, got status %d", w.Code)
+		}
+	})
+
+	t.Run("disabled when max is not positive", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		middleware := ConcurrencyLimit(0, 0)(handler)
+
+		w := httptest.NewRecorder()
+		middleware.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+	})
+}
+
+func TestPerIPConcurrencyLimit(t *testing.T) {
+	const n = 3
+
+	newRequest := func(remoteAddr string) *http.Request {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = remoteAddr
+		return req
+	}
+
+	t.Run("rejects the (N+1)th concurrent request from one IP", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{}, n)
+		blockingHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-release
+			w.WriteHeader(http.StatusOK)
+		})
+
+		middleware := PerIPConcurrencyLimit(n)(blockingHandler)
+
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				// Different ports, same client
+				middleware.ServeHTTP(httptest.NewRecorder(), newRequest("203.0.113.7:"+strconv.Itoa(40000+i)))
+			}(i)
+		}
+		for i := 0; i < n; i++ {
+			<-started
+		}
+
+		w := httptest.NewRecorder()
+		middleware.ServeHTTP(w, newRequest("203.0.113.7:50000"))
+		if w.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected status 429, got %d", w.Code)
+		}
+		if w.Header().Get("Retry-After") == "" {
+			t.Error("Expected Retry-After header")
+		}
+
+		// Another client is unaffected
+		other := httptest.NewRecorder()
+		done := make(chan struct{})
+		go func() {
+			middleware.ServeHTTP(other, newRequest("198.51.100.1:40000"))
+			close(done)
+		}()
+		<-started
+
+		close(release)
+		wg.Wait()
+		<-done
+		if other.Code != http.StatusOK {
+			t.Errorf("Expected other IP to be served, got %d", other.Code)
+		}
+
+		// Slots are returned on completion
+		w = httptest.NewRecorder()
+		go func() { started <- struct{}{} }()
+		middleware.ServeHTTP(w, newRequest("203.0.113.7:50001"))
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected request after completion to be served, got %d", w.Code)
+		}
+	})
+
+	t.Run("decrements counter when handler panics", func(t *testing.T) {
+		panicking := true
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if panicking {
+				panic("boom")
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+
+		middleware := PerIPConcurrencyLimit(1)(handler)
+
+		func() {
+			defer func() { _ = recover() }()
+			middleware.ServeHTTP(httptest.NewRecorder(), newRequest("203.0.113.7:40000"))
+		}()
+
+		panicking = false
+		w := httptest.NewRecorder()
+		middleware.ServeHTTP(w, newRequest("203.0.113.7:40001"))
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected counter to be released after panic, got status %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// RequireContentType rejects unsafe requests (POST, PUT, PATCH, DELETE) whose
+// Content-Type is missing or not one of types, answering 415 with a JSON error
+// before the handler tries to decode the body. Parameters such as charset are
+// ignored and media types compare case-insensitively, so
+// "Application/JSON; charset=utf-8" matches "application/json". Safe methods
+// and requests with an empty body (Content-Length: 0) pass through.
+func RequireContentType(types ...string) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(types))
+	for _, t := range types {
+		allowed[strings.ToLower(t)] = true
+	}
+	msg := "Unsupported Media Type; expected " + strings.Join(types, ", ")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				next.ServeHTTP(w, r)
+				return
+			}
+			if r.ContentLength == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// ParseMediaType lower-cases the type and rejects malformed values
+			mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil || !allowed[mediaType] {
+				respondJSONError(w, http.StatusUnsupportedMediaType, msg)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequireContentType(t *testing.T) {
+	handler := RequireContentType("application/json", "application/merge-patch+json")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	const msg = "Unsupported Media Type; expected application/json, application/merge-patch+json"
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{name: "exact match", method: "POST", contentType: "application/json", body: "{}", wantStatus: http.StatusOK},
+		{name: "charset parameter ignored", method: "POST", contentType: "application/json; charset=utf-8", body: "{}", wantStatus: http.StatusOK},
+		{name: "case-insensitive", method: "PUT", contentType: "Application/JSON", body: "{}", wantStatus: http.StatusOK},
+		{name: "second allowed type", method: "PATCH", contentType: "application/merge-patch+json", body: "{}", wantStatus: http.StatusOK},
+		{name: "wrong type", method: "POST", contentType: "text/plain", body: "{}", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "missing type", method: "POST", body: "{}", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "malformed type", method: "POST", contentType: "application/json; charset", body: "{}", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "type prefix is not enough", method: "POST", contentType: "application/jsonp", body: "{}", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "empty body", method: "DELETE", wantStatus: http.StatusOK},
+		{name: "safe method", method: "GET", contentType: "text/plain", body: "x", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/guitars", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if tt.wantStatus == http.StatusUnsupportedMediaType {
+				// JSON regardless of Accept: these routes serve API clients
+				assertJSONError(t, w, tt.wantStatus, msg)
+			} else if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
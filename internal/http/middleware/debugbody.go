@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// DefaultDebugBodyMaxBytes is the default number of body bytes logged per direction.
+const DefaultDebugBodyMaxBytes = 4096
+
+// DebugBodyLogger logs request and response bodies at debug level for allowlisted paths.
+// Bodies are never logged globally because of PII and size concerns; only exact path
+// matches are inspected and at most maxBytes are logged per direction. The request body
+// is restored so the handler still reads it in full.
+func DebugBodyLogger(logger *slog.Logger, pathAllowlist []string, maxBytes int) func(http.Handler) http.Handler {
+	if maxBytes <= 0 {
+		maxBytes = DefaultDebugBodyMaxBytes
+	}
+
+	allowed := make(map[string]struct{}, len(pathAllowlist))
+	for _, p := range pathAllowlist {
+		allowed[p] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := allowed[r.URL.Path]; !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Peek at the head of the request body and stitch it back in front of
+			// the remaining stream so the handler sees the original bytes
+			var reqBody []byte
+			if r.Body != nil && r.Body != http.NoBody {
+				head, _ := io.ReadAll(io.LimitReader(r.Body, int64(maxBytes)))
+				reqBody = head
+				r.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+			}
+
+			dw := NewResponseObserverWithBody(w, maxBytes)
+			next.ServeHTTP(dw, r)
+
+			reqLogger := logger
+			if rid, ok := RequestIDFromContext(r.Context()); ok {
+				reqLogger = reqLogger.With("request_id", rid)
+			}
+
+			reqLogger.Debug("debug body",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", dw.Status(),
+				"request_body", string(reqBody),
+				"response_body", string(dw.Body()),
+				"response_truncated", dw.BodyTruncated(),
+			)
+		})
+	}
+}
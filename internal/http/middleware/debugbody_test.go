@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugBodyLogger(t *testing.T) {
+	var logOutput bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logOutput, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	// Echo handler proves the request body is still readable in full
+	echoHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	})
+
+	t.Run("logs bodies for allowlisted path and restores request body", func(t *testing.T) {
+		logOutput.Reset()
+		middleware := DebugBodyLogger(logger, []string{"/api/echo"}, 1024)(echoHandler)
+
+		req := httptest.NewRequest("POST", "/api/echo", strings.NewReader(`{"name":"strat"}`))
+		w := httptest.NewRecorder()
+
+		middleware.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Errorf("Expected status 201, got %d", w.Code)
+		}
+		if w.Body.String() != `{"name":"strat"}` {
+			t.Errorf("Expected handler to see full body, got '%s'", w.Body.String())
+		}
+
+		logContent := logOutput.String()
+		if !strings.Contains(logContent, "debug body") {
+			t.Error("Expected debug body log entry")
+		}
+		if !strings.Contains(logContent, `request_body="{\"name\":\"strat\"}"`) {
+			t.Errorf("Expected request body to be logged, got: %s", logContent)
+		}
+		if !strings.Contains(logContent, `response_body="{\"name\":\"strat\"}"`) {
+			t.Errorf("Expected response body to be logged, got: %s", logContent)
+		}
+	})
+
+	t.Run("handler sees full body when it exceeds maxBytes", func(t *testing.T) {
+		logOutput.Reset()
+		middleware := DebugBodyLogger(logger, []string{"/api/echo"}, 8)(echoHandler)
+
+		payload := strings.Repeat("abcdefgh", 64)
+		req := httptest.NewRequest("POST", "/api/echo", strings.NewReader(payload))
+		w := httptest.NewRecorder()
+
+		middleware.ServeHTTP(w, req)
+
+		if w.Body.String() != payload {
+			t.Errorf("Expected handler to see %d bytes, got %d", len(payload), w.Body.Len())
+		}
+
+		logContent := logOutput.String()
+		if !strings.Contains(logContent, "request_body=abcdefgh ") {
+			t.Errorf("Expected request body truncated to 8 bytes, got: %s", logContent)
+		}
+		if !strings.Contains(logContent, "response_truncated=true") {
+			t.Errorf("Expected response to be marked truncated, got: %s", logContent)
+		}
+	})
+
+	t.Run("is a no-op for non-allowlisted paths", func(t *testing.T) {
+		logOutput.Reset()
+		middleware := DebugBodyLogger(logger, []string{"/api/echo"}, 1024)(echoHandler)
+
+		req := httptest.NewRequest("POST", "/other", strings.NewReader("secret"))
+		w := httptest.NewRecorder()
+
+		middleware.ServeHTTP(w, req)
+
+		if w.Body.String() != "secret" {
+			t.Errorf("Expected body 'secret', got '%s'", w.Body.String())
+		}
+		if logOutput.Len() != 0 {
+			t.Errorf("Expected nothing logged, got: %s", logOutput.String())
+		}
+	})
+}
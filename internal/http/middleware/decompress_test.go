@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestDecompressRequest(t *testing.T) {
+	gzipBody := func(data []byte) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write(data)
+		_ = zw.Close()
+		return buf.Bytes()
+	}
+
+	// echo writes back the body the handler read, or the read error
+	var readErr error
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		readErr = err
+		if r.Header.Get("Content-Encoding") != "" {
+			t.Errorf("Expected Content-Encoding to be removed, got '%s'", r.Header.Get("Content-Encoding"))
+		}
+		_, _ = w.Write(body)
+	})
+
+	t.Run("decodes gzip body", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/test", bytes.NewReader(gzipBody([]byte(`{"model":"Stratocaster"}`))))
+		req.Header.Set("Content-Encoding", "gzip")
+		w := httptest.NewRecorder()
+
+		DecompressRequest(0)(echo).ServeHTTP(w, req)
+
+		if readErr != nil {
+			t.Fatalf("Expected no read error, got %v", readErr)
+		}
+		if w.Body.String() != `{"model":"Stratocaster"}` {
+			t.Errorf("Expected decoded body, got '%s'", w.Body.String())
+		}
+	})
+
+	t.Run("decodes brotli body", func(t *testing.T) {
+		var buf bytes.Buffer
+		bw := brotli.NewWriter(&buf)
+		_, _ = bw.Write([]byte("hello"))
+		_ = bw.Close()
+
+		req := httptest.NewRequest("POST", "/test", &buf)
+		req.Header.Set("Content-Encoding", "br")
+		w := httptest.NewRecorder()
+
+		DecompressRequest(0)(echo).ServeHTTP(w, req)
+
+		if readErr != nil || w.Body.String() != "hello" {
+			t.Errorf("Expected decoded body 'hello', got '%s' (err %v)", w.Body.String(), readErr)
+		}
+	})
+
+	t.Run("caps decompressed size", func(t *testing.T) {
+		// 1MB of zeros compresses to about 1KB
+		bomb := gzipBody(make([]byte, 1<<20))
+		req := httptest.NewRequest("POST", "/test", bytes.NewReader(bomb))
+		req.Header.Set("Content-Encoding", "gzip")
+		w := httptest.NewRecorder()
+
+		DecompressRequest(64<<10)(echo).ServeHTTP(w, req)
+
+		var maxErr *http.MaxBytesError
+		if !errors.As(readErr, &maxErr) {
+			t.Fatalf("Expected *http.MaxBytesError, got %v", readErr)
+		}
+		if w.Body.Len() > 64<<10 {
+			t.Errorf("Expected at most %d bytes read, got %d", 64<<10, w.Body.Len())
+		}
+	})
+
+	t.Run("rejects malformed gzip", func(t *testing.T) {
+		called := false
+		handler := DecompressRequest(0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+
+		req := httptest.NewRequest("POST", "/test", strings.NewReader("not gzip"))
+		req.Header.Set("Content-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+		if called {
+			t.Error("Expected handler not to be called")
+		}
+	})
+
+	t.Run("rejects unsupported encoding", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/test", strings.NewReader("data"))
+		req.Header.Set("Content-Encoding", "compress")
+		w := httptest.NewRecorder()
+
+		DecompressRequest(0)(echo).ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("Expected status 415, got %d", w.Code)
+		}
+	})
+
+	t.Run("passes plain body through", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/test", strings.NewReader("plain"))
+		w := httptest.NewRecorder()
+
+		DecompressRequest(0)(echo).ServeHTTP(w, req)
+
+		if w.Body.String() != "plain" {
+			t.Errorf("Expected body 'plain', got '%s'", w.Body.String())
+		}
+	})
+}
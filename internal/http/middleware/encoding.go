@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PreferredEncodings lists the content codings the server produces, best first.
+// Dynamic pages (Compress) and precompressed static files share this order.
+var PreferredEncodings = []string{"br", "gzip"}
+
+// NegotiateEncoding returns the first of offered (in server preference order)
+// that the request's Accept-Encoding allows, or "" for identity. A coding is
+// allowed when listed, or covered by "*", with a non-zero q-value.
+func NegotiateEncoding(r *http.Request, offered ...string) string {
+	header := r.Header.Get("Accept-Encoding")
+	if header == "" || len(offered) == 0 {
+		return ""
+	}
+
+	accepted := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		token, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		token = strings.ToLower(strings.TrimSpace(token))
+		if token == "" {
+			continue
+		}
+		q := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = v
+			}
+		}
+		accepted[token] = q
+	}
+
+	for _, enc := range offered {
+		q, ok := accepted[enc]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+		if ok && q > 0 {
+			return enc
+		}
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		offered        []string
+		want           string
+	}{
+		{name: "no header", acceptEncoding: "", offered: PreferredEncodings, want: ""},
+		{name: "server preference wins", acceptEncoding: "gzip, br", offered: PreferredEncodings, want: "br"},
+		{name: "client q-values do not reorder", acceptEncoding: "gzip;q=1.0, br;q=0.5", offered: PreferredEncodings, want: "br"},
+		{name: "gzip only", acceptEncoding: "gzip, deflate", offered: PreferredEncodings, want: "gzip"},
+		{name: "q=0 refuses", acceptEncoding: "br;q=0, gzip", offered: PreferredEncodings, want: "gzip"},
+		{name: "q=0.0 refuses", acceptEncoding: "br;q=0.0, gzip;q=0", offered: PreferredEncodings, want: ""},
+		{name: "case insensitive", acceptEncoding: "GZIP", offered: PreferredEncodings, want: "gzip"},
+		{name: "wildcard", acceptEncoding: "*", offered: PreferredEncodings, want: "br"},
+		{name: "explicit refusal beats wildcard", acceptEncoding: "br;q=0, *", offered: PreferredEncodings, want: "gzip"},
+		{name: "only offered codings", acceptEncoding: "br", offered: []string{"gzip"}, want: ""},
+		{name: "nothing offered", acceptEncoding: "br, gzip", offered: nil, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			if got := NegotiateEncoding(req, tt.offered...); got != tt.want {
+				t.Errorf("NegotiateEncoding(%q) = %q, want %q", tt.acceptEncoding, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultErrorLogSize is the number of recent 5xx responses an ErrorLog keeps.
+const DefaultErrorLogSize = 100
+
+// ErrorRecord describes one 5xx response kept by ErrorLog.
+type ErrorRecord struct {
+	Time      time.Time `json:"time"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	RequestID string    `json:"request_id,omitempty"`
+	Panic     string    `json:"panic,omitempty"` // Recovered panic value, when Recoverer produced the 500
+}
+
+// ErrorLog is a fixed-size, concurrency-safe ring buffer of recent 5xx
+// responses, for triage from a debug endpoint without log aggregation. Once
+// full, each new record evicts the oldest. A nil *ErrorLog records nothing.
+type ErrorLog struct {
+	mu      sync.Mutex
+	records []ErrorRecord
+	next    int // Index the next record is written to
+	full    bool
+}
+
+// NewErrorLog creates an ErrorLog keeping the last size records; a
+// non-positive size uses DefaultErrorLogSize.
+func NewErrorLog(size int) *ErrorLog {
+	if size <= 0 {
+		size = DefaultErrorLogSize
+	}
+	return &ErrorLog{records: make([]ErrorRecord, size)}
+}
+
+// Record adds rec, evicting the oldest record when the buffer is full.
+func (l *ErrorLog) Record(rec ErrorRecord) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.records[l.next] = rec
+	l.next = (l.next + 1) % len(l.records)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Recent returns a copy of the kept records, newest first.
+func (l *ErrorLog) Recent() []ErrorRecord {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n := l.next
+	if l.full {
+		n = len(l.records)
+	}
+	recent := make([]ErrorRecord, 0, n)
+	for i := 1; i <= n; i++ {
+		recent = append(recent, l.records[(l.next-i+len(l.records))%len(l.records)])
+	}
+	return recent
+}
+
+// Middleware records every response with a 5xx status. It must wrap Recoverer
+// so a recovered panic's value is attached to the record. On a nil *ErrorLog
+// it returns next unchanged.
+func (l *ErrorLog) Middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		note := &panicNote{}
+		r = r.WithContext(context.WithValue(r.Context(), panicNoteKey{}, note))
+
+		ww := NewResponseObserver(w)
+		next.ServeHTTP(ww, r)
+
+		if ww.Status() < http.StatusInternalServerError {
+			return
+		}
+
+		// Long paths are truncated, as in the request log
+		path := r.URL.Path
+		if len(path) > 100 {
+			path = path[:100] + "..."
+		}
+		rid, _ := RequestIDFromContext(r.Context())
+
+		l.Record(ErrorRecord{
+			Time:      time.Now(),
+			Method:    r.Method,
+			Path:      path,
+			Status:    ww.Status(),
+			RequestID: rid,
+			Panic:     note.message,
+		})
+	})
+}
+
+// panicNote carries a recovered panic's value from Recoverer, or from the
+// timeout middleware's handler goroutine, out to ErrorLog.
+type panicNote struct {
+	message string
+}
+
+// panicNoteKey is the context key for the request's panicNote.
+type panicNoteKey struct{}
+
+// notePanic attaches a recovered panic value to the request's ErrorLog record,
+// if an ErrorLog is observing the request.
+func notePanic(ctx context.Context, v any) {
+	if note, ok := ctx.Value(panicNoteKey{}).(*panicNote); ok {
+		note.message = fmt.Sprint(v)
+	}
+}
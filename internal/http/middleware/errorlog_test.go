@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestErrorLog_Eviction(t *testing.T) {
+	l := NewErrorLog(3)
+	if got := l.Recent(); len(got) != 0 {
+		t.Fatalf("Expected no records, got %d", len(got))
+	}
+
+	for i := 1; i <= 5; i++ {
+		l.Record(ErrorRecord{Path: fmt.Sprintf("/%d", i), Status: 500})
+	}
+
+	got := l.Recent()
+	expected := []string{"/5", "/4", "/3"}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d records, got %d", len(expected), len(got))
+	}
+	for i, path := range expected {
+		if got[i].Path != path {
+			t.Errorf("Expected record %d to be %s, got %s", i, path, got[i].Path)
+		}
+	}
+}
+
+func TestErrorLog_Concurrent(t *testing.T) {
+	l := NewErrorLog(10)
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.Record(ErrorRecord{Status: 500})
+			_ = l.Recent()
+		}()
+	}
+	wg.Wait()
+
+	if got := len(l.Recent()); got != 10 {
+		t.Errorf("Expected the buffer to stay at 10 records, got %d", got)
+	}
+}
+
+func TestErrorLog_Middleware(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	l := NewErrorLog(10)
+	handler := RequestID(l.Middleware(Recoverer(logger)(mux)))
+
+	for _, target := range []string{"/ok", "/fail", "/panic"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", target, nil))
+	}
+
+	got := l.Recent()
+	if len(got) != 2 {
+		t.Fatalf("Expected only the 2 server errors to be recorded, got %d", len(got))
+	}
+	if got[0].Path != "/panic" || got[0].Status != 500 || got[0].Panic != "boom" {
+		t.Errorf("Expected the recovered panic record, got %+v", got[0])
+	}
+	if got[1].Path != "/fail" || got[1].Status != 503 || got[1].Panic != "" {
+		t.Errorf("Expected the 503 record without a panic, got %+v", got[1])
+	}
+	if got[0].RequestID == "" || got[0].Time.IsZero() {
+		t.Errorf("Expected request ID and time to be set, got %+v", got[0])
+	}
+
+	t.Run("panic recovered inside Timeout", func(t *testing.T) {
+		// As in the app: Timeout recovers the panic itself, so Recoverer never sees it
+		l := NewErrorLog(10)
+		handler := l.Middleware(Recoverer(logger)(Timeout(logger, time.Second)(mux)))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/panic", nil))
+
+		got := l.Recent()
+		if len(got) != 1 || got[0].Status != 500 || got[0].Panic != "boom" {
+			t.Errorf("Expected one 500 record with the panic, got %+v", got)
+		}
+	})
+
+	t.Run("nil log is a no-op", func(t *testing.T) {
+		var nilLog *ErrorLog
+		w := httptest.NewRecorder()
+		nilLog.Middleware(mux).ServeHTTP(w, httptest.NewRequest("GET", "/ok", nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+		if nilLog.Recent() != nil {
+			t.Error("Expected no records from a nil log")
+		}
+	})
+}
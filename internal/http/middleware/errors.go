@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"embed"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// errorPageFS holds the last-resort error page, compiled into the binary so it
+// can be served when templates, the renderer or the database are unavailable.
+//
+//go:embed errorpage/500.html
+var errorPageFS embed.FS
+
+// errorPage500 is the embedded 500 page. It is static HTML with no inline
+// styles or scripts, so the page's own CSP never blocks any of it.
+var errorPage500, _ = errorPageFS.ReadFile("errorpage/500.html")
+
+// respondError writes an error response in the format the client asked for.
+// Clients preferring application/json get {"error": msg, "status": status};
+// browsers asking for HTML get the embedded error page for a 500; everyone
+// else gets the plain-text body http.Error would produce.
+func respondError(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	switch {
+	case prefersJSON(r):
+		respondJSONError(w, status, msg)
+	case status == http.StatusInternalServerError && acceptsHTML(r):
+		writeErrorPage(w, status)
+	default:
+		http.Error(w, msg, status)
+	}
+}
+
+// RespondInternalError answers with a 500 in the format the client asked for,
+// including the embedded error page for browsers. It is the fallback for code
+// outside this package, such as the renderer, when a page can't be produced.
+func RespondInternalError(w http.ResponseWriter, r *http.Request) {
+	respondError(w, r, http.StatusInternalServerError, "Internal Server Error")
+}
+
+// writeErrorPage writes the embedded error page with status. Like http.Error,
+// it drops headers describing a body it replaces.
+func writeErrorPage(w http.ResponseWriter, status int) {
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Del("Content-Encoding")
+	h.Del("ETag")
+	h.Set("Content-Type", "text/html; charset=utf-8")
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Set("Cache-Control", NoStoreValue)
+	w.WriteHeader(status)
+	_, _ = w.Write(errorPage500)
+}
+
+// respondJSONError writes {"error": msg, "status": status} regardless of Accept,
+// for endpoints whose clients are known to speak JSON.
+func respondJSONError(w http.ResponseWriter, status int, msg string) {
+	// Mirror http.Error: drop headers that describe a body we are replacing
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Del("Content-Encoding")
+	h.Set("Content-Type", "application/json; charset=utf-8")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(struct {
+		Error  string `json:"error"`
+		Status int    `json:"status"`
+	}{msg, status})
+}
+
+// prefersJSON reports whether the Accept header ranks application/json above
+// any text type. Ties go to text, so browsers keep getting plain errors.
+func prefersJSON(r *http.Request) bool {
+	jsonQ, textQ := -1.0, -1.0
+	forEachAccept(r, func(mediaType string, q float64) {
+		switch {
+		case mediaType == "application/json" && q > jsonQ:
+			jsonQ = q
+		case strings.HasPrefix(mediaType, "text/") && q > textQ:
+			textQ = q
+		}
+	})
+	return jsonQ > 0 && jsonQ > textQ
+}
+
+// acceptsHTML reports whether the Accept header explicitly allows text/html,
+// as browser navigations do. Wildcards alone don't count, so curl and API
+// clients keep getting plain errors.
+func acceptsHTML(r *http.Request) bool {
+	html := false
+	forEachAccept(r, func(mediaType string, q float64) {
+		if mediaType == "text/html" && q > 0 {
+			html = true
+		}
+	})
+	return html
+}
+
+// forEachAccept calls fn with each well-formed media range in the Accept
+// header and its quality (1 when absent).
+func forEachAccept(r *http.Request, fn func(mediaType string, q float64)) {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		fn(mediaType, q)
+	}
+}
@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// assertJSONError checks a respondError JSON body for the given status and message.
+func assertJSONError(t *testing.T, w *httptest.ResponseRecorder, status int, msg string) {
+	t.Helper()
+
+	if w.Code != status {
+		t.Errorf("Expected status %d, got %d", status, w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("Expected JSON content type, got '%s'", ct)
+	}
+	var body struct {
+		Error  string `json:"error"`
+		Status int    `json:"status"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Expected valid JSON body, got %q: %v", w.Body.String(), err)
+	}
+	if body.Error != msg || body.Status != status {
+		t.Errorf("Expected {%q, %d}, got {%q, %d}", msg, status, body.Error, body.Status)
+	}
+}
+
+// assertPlainError checks a plain-text http.Error style response.
+func assertPlainError(t *testing.T, w *httptest.ResponseRecorder, status int, msg string) {
+	t.Helper()
+
+	if w.Code != status {
+		t.Errorf("Expected status %d, got %d", status, w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("Expected plain-text content type, got '%s'", ct)
+	}
+	if strings.TrimSpace(w.Body.String()) != msg {
+		t.Errorf("Expected body '%s', got '%s'", msg, w.Body.String())
+	}
+}
+
+// assertErrorPage checks for the embedded 500 page.
+func assertErrorPage(t *testing.T, w *httptest.ResponseRecorder) {
+	t.Helper()
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("Expected HTML content type, got '%s'", ct)
+	}
+	if !strings.Contains(w.Body.String(), "Something went wrong") {
+		t.Errorf("Expected the embedded error page, got '%s'", w.Body.String())
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != NoStoreValue {
+		t.Errorf("Expected Cache-Control '%s', got '%s'", NoStoreValue, cc)
+	}
+}
+
+func TestAcceptsHTML(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"text/plain", false},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", true},
+		{"text/html;q=0", false},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", tt.accept)
+		if got := acceptsHTML(req); got != tt.want {
+			t.Errorf("acceptsHTML(%q): Expected %v, got %v", tt.accept, tt.want, got)
+		}
+	}
+}
+
+func TestPrefersJSON(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", true},
+		{"application/json, text/plain;q=0.5", true},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", false},
+		{"text/plain, application/json", false},
+		{"application/json;q=0", false},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", tt.accept)
+		if got := prefersJSON(req); got != tt.want {
+			t.Errorf("prefersJSON(%q): Expected %v, got %v", tt.accept, tt.want, got)
+		}
+	}
+}
+
+func TestRespondError_PerMiddleware(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		target  string
+		status  int
+		msg     string
+	}{
+		{"Timeout", Timeout(logger, 10*time.Millisecond)(slow), "/", http.StatusRequestTimeout, "Request Timeout"},
+		{"TimeoutWithCause panic", TimeoutWithCause(logger, time.Second, errors.New("timeout"))(panicking), "/", http.StatusInternalServerError, "Internal Server Error"},
+		{"Recoverer", Recoverer(logger)(panicking), "/", http.StatusInternalServerError, "Internal Server Error"},
+		{"RequestLimits", RequestLimits(100, 16)(slow), "/" + strings.Repeat("a", 32), http.StatusRequestURITooLong, "URI Too Long"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" json", func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			req.Header.Set("Accept", "application/json")
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, req)
+
+			assertJSONError(t, w, tt.status, tt.msg)
+		})
+
+		t.Run(tt.name+" plain", func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			req.Header.Set("Accept", "text/plain")
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, req)
+
+			assertPlainError(t, w, tt.status, tt.msg)
+		})
+
+		t.Run(tt.name+" html", func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			req.Header.Set("Accept", "text/html")
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, req)
+
+			// Only a 500 has an embedded page; other statuses stay plain
+			if tt.status == http.StatusInternalServerError {
+				assertErrorPage(t, w)
+			} else {
+				assertPlainError(t, w, tt.status, tt.msg)
+			}
+		})
+	}
+
+	t.Run("ConcurrencyLimit", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		blocking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+		})
+		middleware := ConcurrencyLimit(1, 0)(blocking)
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		}()
+		<-started
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		middleware.ServeHTTP(w, req)
+		assertJSONError(t, w, http.StatusServiceUnavailable, "Service Unavailable")
+
+		w = httptest.NewRecorder()
+		middleware.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		assertPlainError(t, w, http.StatusServiceUnavailable, "Service Unavailable")
+
+		close(release)
+		wg.Wait()
+	})
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// ETag buffers successful GET and HEAD responses, tags them with a strong ETag
+// derived from the body, and answers a matching If-None-Match with 304. A handler
+// that sets its own ETag keeps it. The tag describes the uncompressed bytes, so
+// ETag must run inside Compress, which weakens it when it encodes the body.
+// Bodies embedding the request's CSP nonce get a weak tag that ignores it, and
+// a 304 for them omits Content-Security-Policy so the cached nonce stays valid.
+func ETag(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ew := &etagResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(ew, r)
+
+		if ew.status != http.StatusOK {
+			ew.flush()
+			return
+		}
+
+		h := w.Header()
+		etag := h.Get("ETag")
+		if etag == "" {
+			etag = bodyETag(ew.buf.Bytes(), r)
+			h.Set("ETag", etag)
+		}
+
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			h.Del("Content-Type")
+			h.Del("Content-Length")
+			// The client keeps its cached body, whose nonce matches the cached policy
+			h.Del("Content-Security-Policy")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		ew.flush()
+	})
+}
+
+// bodyETag derives a tag from body. A CSP nonce differs on every request
+// without changing the page, so it is left out of the hash and the tag is weak.
+func bodyETag(body []byte, r *http.Request) string {
+	weak := ""
+	if nonce, ok := CSPNonceFromContext(r.Context()); ok && nonce != "" && bytes.Contains(body, []byte(nonce)) {
+		body = bytes.ReplaceAll(body, []byte(nonce), nil)
+		weak = "W/"
+	}
+	sum := sha256.Sum256(body)
+	return weak + `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// etagMatches applies the weak comparison If-None-Match requires (RFC 7232 section 3.2),
+// so a W/ tag handed out after compression still matches the strong original.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// etagResponseWriter buffers the body and status until the ETag is known.
+type etagResponseWriter struct {
+	http.ResponseWriter
+	buf         bytes.Buffer
+	status      int
+	wroteHeader bool
+}
+
+func (ew *etagResponseWriter) WriteHeader(code int) {
+	if ew.wroteHeader {
+		return
+	}
+	ew.wroteHeader = true
+	ew.status = code
+}
+
+func (ew *etagResponseWriter) Write(b []byte) (int, error) {
+	if !ew.wroteHeader {
+		ew.WriteHeader(http.StatusOK)
+	}
+	return ew.buf.Write(b)
+}
+
+// flush sends the buffered status and body downstream.
+func (ew *etagResponseWriter) flush() {
+	ew.ResponseWriter.WriteHeader(ew.status)
+	if ew.buf.Len() > 0 {
+		_, _ = ew.ResponseWriter.Write(ew.buf.Bytes())
+	}
+}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestETag(t *testing.T) {
+	body := strings.Repeat("<p>guitar</p>", 100)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(body))
+	})
+
+	t.Run("sets a strong ETag and answers If-None-Match with 304", func(t *testing.T) {
+		h := ETag(handler)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		etag := w.Header().Get("ETag")
+		if !strings.HasPrefix(etag, `"`) {
+			t.Fatalf("Expected strong ETag, got '%s'", etag)
+		}
+		if w.Body.String() != body {
+			t.Errorf("Expected body to pass through")
+		}
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("If-None-Match", etag)
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotModified {
+			t.Errorf("Expected status 304, got %d", w.Code)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("Expected empty body, got %d bytes", w.Body.Len())
+		}
+	})
+
+	t.Run("ignores the CSP nonce", func(t *testing.T) {
+		h := SecurityHeaders(ETag(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			nonce, _ := CSPNonceFromContext(r.Context())
+			_, _ = w.Write([]byte(`<script nonce="` + nonce + `"></script>`))
+		})))
+
+		first := httptest.NewRecorder()
+		h.ServeHTTP(first, httptest.NewRequest("GET", "/", nil))
+		etag := first.Header().Get("ETag")
+		if !strings.HasPrefix(etag, `W/"`) {
+			t.Fatalf("Expected weak ETag for a nonce-bearing body, got '%s'", etag)
+		}
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("If-None-Match", etag)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotModified {
+			t.Errorf("Expected status 304 despite a new nonce, got %d", w.Code)
+		}
+		if csp := w.Header().Get("Content-Security-Policy"); csp != "" {
+			t.Errorf("Expected 304 to keep the cached policy, got '%s'", csp)
+		}
+	})
+
+	t.Run("compressed responses get a weak ETag", func(t *testing.T) {
+		h := Compress(nil, DefaultCompressLevel)(ETag(handler))
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Header().Get("Content-Encoding") != "gzip" {
+			t.Fatalf("Expected gzip encoding, got '%s'", w.Header().Get("Content-Encoding"))
+		}
+		etag := w.Header().Get("ETag")
+		if !strings.HasPrefix(etag, `W/"`) {
+			t.Fatalf("Expected weak ETag on compressed response, got '%s'", etag)
+		}
+
+		// Uncompressed clients get the strong form of the same tag
+		plain := httptest.NewRecorder()
+		h.ServeHTTP(plain, httptest.NewRequest("GET", "/", nil))
+		if got := plain.Header().Get("ETag"); "W/"+got != etag {
+			t.Errorf("Expected strong ETag %s for identity response, got %s", strings.TrimPrefix(etag, "W/"), got)
+		}
+
+		// The weak tag still revalidates
+		req = httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		req.Header.Set("If-None-Match", etag)
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != http.StatusNotModified {
+			t.Errorf("Expected status 304 for weak If-None-Match, got %d", w.Code)
+		}
+	})
+
+	t.Run("non-200 responses are not tagged", func(t *testing.T) {
+		h := ETag(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		}))
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/missing", nil))
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+		if w.Header().Get("ETag") != "" {
+			t.Errorf("Expected no ETag, got '%s'", w.Header().Get("ETag"))
+		}
+	})
+}
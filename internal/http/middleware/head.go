@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// HeadNoBody makes HEAD responses carry the headers the same GET would, with an
+// empty body. Handlers (and the buffering or encoding writers they sit behind)
+// run as for GET; their body bytes are counted and dropped. The status is held
+// until the handler returns so a Content-Length can be derived from the count,
+// and a missing Content-Type is sniffed from the first bytes as net/http would.
+// Other methods pass through.
+func HeadNoBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		hw := &headWriter{ResponseWriter: w, path: r.URL.Path, status: http.StatusOK}
+		next.ServeHTTP(hw, r)
+		hw.finish()
+	})
+}
+
+// headWriter discards the body of a HEAD response and defers the status until
+// the body's length is known. It deliberately has no Unwrap: flushing the
+// underlying writer early would send headers before Content-Length is set.
+type headWriter struct {
+	http.ResponseWriter
+	path        string
+	status      int
+	wroteHeader bool
+	sniffed     bool
+	written     int64
+}
+
+// WriteHeader records the first final status; informational 1xx responses are
+// sent straight away.
+func (hw *headWriter) WriteHeader(code int) {
+	if code >= 100 && code < 200 {
+		hw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if hw.wroteHeader {
+		return
+	}
+	hw.wroteHeader = true
+	hw.status = code
+}
+
+// Write counts and discards b, sniffing the Content-Type from the first bytes.
+func (hw *headWriter) Write(b []byte) (int, error) {
+	if !hw.wroteHeader {
+		hw.WriteHeader(http.StatusOK)
+	}
+	if !hw.sniffed && len(b) > 0 {
+		hw.sniffed = true
+		h := hw.Header()
+		if _, ok := h["Content-Type"]; !ok && h.Get("Content-Encoding") == "" {
+			h.Set("Content-Type", detectContentType(hw.path, b))
+		}
+	}
+	hw.written += int64(len(b))
+	return len(b), nil
+}
+
+// finish sends the held status with a Content-Length for the discarded body,
+// unless the handler set one or the status has no body.
+func (hw *headWriter) finish() {
+	h := hw.Header()
+	if hw.written > 0 && bodyAllowed(hw.status) && h.Get("Content-Length") == "" && h.Get("Transfer-Encoding") == "" {
+		h.Set("Content-Length", strconv.FormatInt(hw.written, 10))
+	}
+	hw.ResponseWriter.WriteHeader(hw.status)
+}
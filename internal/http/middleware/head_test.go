@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHeadNoBody(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantType   string
+		wantLength string
+	}{
+		{
+			name: "body counted and dropped",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				w.Write([]byte("<p>hello</p>"))
+			},
+			wantStatus: http.StatusOK,
+			wantType:   "text/html; charset=utf-8",
+			wantLength: "12",
+		},
+		{
+			name: "content type sniffed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("plain text"))
+			},
+			wantStatus: http.StatusOK,
+			wantType:   "text/plain; charset=utf-8",
+			wantLength: "10",
+		},
+		{
+			name: "handler length kept",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Length", "2048")
+				w.WriteHeader(http.StatusOK)
+			},
+			wantStatus: http.StatusOK,
+			wantLength: "2048",
+		},
+		{
+			name: "status without body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotModified)
+			},
+			wantStatus: http.StatusNotModified,
+		},
+		{
+			name: "error status kept",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "missing", http.StatusNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantType:   "text/plain; charset=utf-8",
+			wantLength: "8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			HeadNoBody(tt.handler).ServeHTTP(w, httptest.NewRequest("HEAD", "/", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != tt.wantType {
+				t.Errorf("Expected Content-Type '%s', got '%s'", tt.wantType, ct)
+			}
+			if cl := w.Header().Get("Content-Length"); cl != tt.wantLength {
+				t.Errorf("Expected Content-Length '%s', got '%s'", tt.wantLength, cl)
+			}
+			if w.Body.Len() != 0 {
+				t.Errorf("Expected an empty body, got %d bytes", w.Body.Len())
+			}
+		})
+	}
+
+	t.Run("GET passes through", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		HeadNoBody(tests[0].handler).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		if w.Body.String() != "<p>hello</p>" {
+			t.Errorf("Expected the GET body, got '%s'", w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// hopByHopHeaders are meaningful only for a single connection (RFC 7230 section 6.1)
+// and must not be trusted once a request has passed through a proxy.
+var hopByHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Trailers",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// StripHopByHop removes hop-by-hop headers from incoming requests, including any
+// extra header named in the Connection header, before handlers see them.
+func StripHopByHop(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Headers listed in Connection are hop-by-hop too; collect them before deleting it
+		for _, v := range r.Header.Values("Connection") {
+			for _, name := range strings.Split(v, ",") {
+				if name = textproto.TrimString(name); name != "" {
+					r.Header.Del(name)
+				}
+			}
+		}
+		for _, h := range hopByHopHeaders {
+			r.Header.Del(h)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
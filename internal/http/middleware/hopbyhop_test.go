@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStripHopByHop(t *testing.T) {
+	var seen http.Header
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Connection", "keep-alive, X-Internal-Hop")
+	req.Header.Set("Keep-Alive", "timeout=5")
+	req.Header.Set("Proxy-Authenticate", "Basic")
+	req.Header.Set("Proxy-Authorization", "Basic Zm9vOmJhcg==")
+	req.Header.Set("Te", "trailers")
+	req.Header.Set("Trailers", "Expires")
+	req.Header.Set("Transfer-Encoding", "chunked")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("X-Internal-Hop", "secret")
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+
+	w := httptest.NewRecorder()
+	StripHopByHop(handler).ServeHTTP(w, req)
+
+	for _, h := range []string{"Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization", "Te", "Trailers", "Transfer-Encoding", "Upgrade", "X-Internal-Hop"} {
+		if v := seen.Get(h); v != "" {
+			t.Errorf("Expected %s to be stripped, got '%s'", h, v)
+		}
+	}
+
+	if seen.Get("Accept") != "text/html" {
+		t.Errorf("Expected Accept to pass through, got '%s'", seen.Get("Accept"))
+	}
+	if seen.Get("X-Forwarded-For") != "203.0.113.7" {
+		t.Errorf("Expected X-Forwarded-For to pass through, got '%s'", seen.Get("X-Forwarded-For"))
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// InFlightCounter counts requests currently being served. Wrap the outermost
+// handler with Middleware so every request is counted, including ones later
+// rejected by limits; Count can then be read during shutdown.
+type InFlightCounter struct {
+	n atomic.Int64
+}
+
+// Middleware increments the counter for the lifetime of each request.
+func (c *InFlightCounter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.n.Add(1)
+		// Deferred so the counter drops even if the handler panics
+		defer c.n.Add(-1)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Count returns the number of requests in flight.
+func (c *InFlightCounter) Count() int64 {
+	return c.n.Load()
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestInFlightCounter(t *testing.T) {
+	t.Run("counts requests while they are served", func(t *testing.T) {
+		var counter InFlightCounter
+		release := make(chan struct{})
+		started := make(chan struct{}, 3)
+		handler := counter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-release
+		}))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+			}()
+		}
+		for i := 0; i < 3; i++ {
+			<-started
+		}
+
+		if got := counter.Count(); got != 3 {
+			t.Errorf("Expected 3 requests in flight, got %d", got)
+		}
+
+		close(release)
+		wg.Wait()
+
+		if got := counter.Count(); got != 0 {
+			t.Errorf("Expected 0 requests in flight after completion, got %d", got)
+		}
+	})
+
+	t.Run("decrements after a panic", func(t *testing.T) {
+		var counter InFlightCounter
+		handler := counter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}))
+
+		func() {
+			defer func() { _ = recover() }()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+		}()
+
+		if got := counter.Count(); got != 0 {
+			t.Errorf("Expected 0 requests in flight after panic, got %d", got)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestLimits(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+
+	t.Run("rejects too many headers with 431", func(t *testing.T) {
+		middleware := RequestLimits(100, 2048)(handler)
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		for i := 0; i < 200; i++ {
+			req.Header.Set(fmt.Sprintf("X-Test-%d", i), "v")
+		}
+		w := httptest.NewRecorder()
+
+		middleware.ServeHTTP(w, req)
+
+		if w.Code != http.StatusRequestHeaderFieldsTooLarge {
+			t.Errorf("Expected status 431, got %d", w.Code)
+		}
+		if strings.TrimSpace(w.Body.String()) != "Request Header Fields Too Large" {
+			t.Errorf("Unexpected body '%s'", w.Body.String())
+		}
+	})
+
+	t.Run("counts repeated header values", func(t *testing.T) {
+		middleware := RequestLimits(10, 2048)(handler)
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		for i := 0; i < 20; i++ {
+			req.Header.Add("X-Repeated", "v")
+		}
+		w := httptest.NewRecorder()
+
+		middleware.ServeHTTP(w, req)
+
+		if w.Code != http.StatusRequestHeaderFieldsTooLarge {
+			t.Errorf("Expected status 431, got %d", w.Code)
+		}
+	})
+
+	t.Run("rejects overly long URL with 414", func(t *testing.T) {
+		middleware := RequestLimits(100, 64)(handler)
+
+		req := httptest.NewRequest("GET", "/test?q="+strings.Repeat("a", 100), nil)
+		w := httptest.NewRecorder()
+
+		middleware.ServeHTTP(w, req)
+
+		if w.Code != http.StatusRequestURITooLong {
+			t.Errorf("Expected status 414, got %d", w.Code)
+		}
+	})
+
+	t.Run("allows requests within limits", func(t *testing.T) {
+		middleware := RequestLimits(100, 2048)(handler)
+
+		req := httptest.NewRequest("GET", "/test?q=ok", nil)
+		req.Header.Set("Accept", "text/html")
+		w := httptest.NewRecorder()
+
+		middleware.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+		if w.Body.String() != "OK" {
+			t.Errorf("Expected body 'OK', got '%s'", w.Body.String())
+		}
+	})
+
+	t.Run("falls back to defaults for non-positive limits", func(t *testing.T) {
+		middleware := RequestLimits(0, 0)(handler)
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		for i := 0; i < DefaultMaxHeaders+1; i++ {
+			req.Header.Set(fmt.Sprintf("X-Test-%d", i), "v")
+		}
+		w := httptest.NewRecorder()
+
+		middleware.ServeHTTP(w, req)
+
+		if w.Code != http.StatusRequestHeaderFieldsTooLarge {
+			t.Errorf("Expected status 431 with default limit, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// microCacheMaxEntries bounds memory when many distinct URLs (e.g. query
+// strings) are requested within one TTL; beyond it responses are not cached.
+const microCacheMaxEntries = 1024
+
+// MicroCache serves repeated identical GET requests from memory for ttl,
+// replaying the recorded response bytes (including any compression applied
+// inside it). Concurrent misses for the same key are coalesced so a stampede
+// renders the page once. Entries are keyed by URI and negotiated encoding.
+//
+// Only plain 200 responses are stored: responses with Set-Cookie,
+// Cache-Control no-store/private, or a Vary beyond Accept-Encoding are not.
+// Conditional requests bypass the cache so 304 handling stays with the
+// handler. A CSP nonce embedded in a cached body is replaced with the nonce
+// of the request being served, so it matches that request's policy header.
+// Encoded bodies cannot be searched or rewritten, so they are not cached when
+// the request has a nonce; place compression outside the cache for pages.
+// A ttl of zero or less disables the middleware.
+func MicroCache(ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if ttl <= 0 {
+			return next
+		}
+		c := &microCache{ttl: ttl, entries: make(map[string]*microCacheEntry)}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := r.URL.RequestURI() + "|" + NegotiateEncoding(r, PreferredEncodings...)
+			if e, ok := c.get(key); ok {
+				w.Header().Set("X-Cache", "HIT")
+				e.replay(w, r)
+				return
+			}
+
+			// Only the caller that renders sets leader; the rest wait for its result
+			leader := false
+			v, _, _ := c.flight.Do(key, func() (any, error) {
+				leader = true
+				rec := &recordingWriter{header: make(http.Header)}
+				next.ServeHTTP(rec, r)
+				e := &microCacheEntry{
+					status:  rec.statusCode(),
+					header:  rec.header,
+					body:    rec.body.Bytes(),
+					expires: time.Now().Add(c.ttl),
+				}
+				// Encoded bytes may hide the nonce, so they are assumed to carry it
+				if nonce, ok := CSPNonceFromContext(r.Context()); ok && nonce != "" &&
+					(e.header.Get("Content-Encoding") != "" || bytes.Contains(e.body, []byte(nonce))) {
+					e.nonce = nonce
+				}
+				if e.cacheable() {
+					c.set(key, e)
+				}
+				return e, nil
+			})
+			w.Header().Set("X-Cache", "MISS")
+			e := v.(*microCacheEntry)
+			// A response unsafe to cache is unsafe to share too: a waiter renders its own
+			if !leader && !e.cacheable() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			e.replay(w, r)
+		})
+	}
+}
+
+type microCache struct {
+	ttl     time.Duration
+	flight  singleflight.Group
+	mu      sync.Mutex
+	entries map[string]*microCacheEntry
+}
+
+func (c *microCache) get(key string) (*microCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+	return e, true
+}
+
+// set stores e, sweeping expired entries first when the cache is full.
+func (c *microCache) set(key string, e *microCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= microCacheMaxEntries {
+		now := time.Now()
+		for k, old := range c.entries {
+			if now.After(old.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= microCacheMaxEntries {
+			return
+		}
+	}
+	c.entries[key] = e
+}
+
+// microCacheEntry is a complete recorded response. It is shared between
+// requests and must not be modified after it is created.
+type microCacheEntry struct {
+	status  int
+	header  http.Header
+	body    []byte
+	nonce   string // CSP nonce the body was rendered with, if it contains one
+	expires time.Time
+}
+
+// cacheable reports whether the response is safe to serve to other clients.
+func (e *microCacheEntry) cacheable() bool {
+	if e.status != http.StatusOK || len(e.header.Values("Set-Cookie")) > 0 {
+		return false
+	}
+	// A nonce inside encoded bytes cannot be swapped for the next request's
+	if e.nonce != "" && e.header.Get("Content-Encoding") != "" {
+		return false
+	}
+	cc := strings.ToLower(e.header.Get("Cache-Control"))
+	if strings.Contains(cc, "no-store") || strings.Contains(cc, "private") {
+		return false
+	}
+	for _, v := range e.header.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" && !strings.EqualFold(name, "Accept-Encoding") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// replay writes the recorded response to w, swapping the recorded CSP nonce
+// for the one r was given.
+func (e *microCacheEntry) replay(w http.ResponseWriter, r *http.Request) {
+	h := w.Header()
+	for k, vs := range e.header {
+		h[k] = append([]string(nil), vs...)
+	}
+	body := e.body
+	if nonce, ok := CSPNonceFromContext(r.Context()); ok && e.nonce != "" && nonce != e.nonce {
+		body = bytes.ReplaceAll(body, []byte(e.nonce), []byte(nonce))
+		h.Del("Content-Length")
+	}
+	w.WriteHeader(e.status)
+	_, _ = w.Write(body)
+}
+
+// recordingWriter captures a handler's status, headers and body.
+type recordingWriter struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rw *recordingWriter) Header() http.Header { return rw.header }
+
+func (rw *recordingWriter) WriteHeader(code int) {
+	if rw.wroteHeader {
+		return
+	}
+	rw.wroteHeader = true
+	rw.status = code
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	return rw.body.Write(b)
+}
+
+func (rw *recordingWriter) statusCode() int {
+	if rw.status == 0 {
+		return http.StatusOK
+	}
+	return rw.status
+}
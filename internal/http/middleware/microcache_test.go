@@ -0,0 +1,250 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMicroCache(t *testing.T) {
+	var calls atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("render " + string(rune('0'+n))))
+	})
+
+	get := func(h http.Handler, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/guitars", nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("serves a hit from memory", func(t *testing.T) {
+		calls.Store(0)
+		h := MicroCache(time.Minute)(handler)
+
+		first := get(h, "")
+		second := get(h, "")
+
+		if calls.Load() != 1 {
+			t.Errorf("Expected handler to run once, got %d", calls.Load())
+		}
+		if first.Header().Get("X-Cache") != "MISS" || second.Header().Get("X-Cache") != "HIT" {
+			t.Errorf("Expected MISS then HIT, got %s then %s", first.Header().Get("X-Cache"), second.Header().Get("X-Cache"))
+		}
+		if second.Body.String() != "render 1" || second.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+			t.Errorf("Expected replayed response, got '%s' (%s)", second.Body.String(), second.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("entries expire", func(t *testing.T) {
+		calls.Store(0)
+		h := MicroCache(20 * time.Millisecond)(handler)
+
+		get(h, "")
+		time.Sleep(40 * time.Millisecond)
+		w := get(h, "")
+
+		if calls.Load() != 2 {
+			t.Errorf("Expected handler to run again after expiry, got %d calls", calls.Load())
+		}
+		if w.Body.String() != "render 2" {
+			t.Errorf("Expected fresh render, got '%s'", w.Body.String())
+		}
+	})
+
+	t.Run("keys on negotiated encoding and caches compressed bytes", func(t *testing.T) {
+		calls.Store(0)
+		h := MicroCache(time.Minute)(Compress(nil, DefaultCompressLevel)(handler))
+
+		get(h, "")
+		get(h, "gzip")
+		w := get(h, "gzip")
+
+		if calls.Load() != 2 {
+			t.Errorf("Expected one render per encoding, got %d", calls.Load())
+		}
+		if w.Header().Get("X-Cache") != "HIT" || w.Header().Get("Content-Encoding") != "gzip" {
+			t.Fatalf("Expected cached gzip response, got %s %s", w.Header().Get("X-Cache"), w.Header().Get("Content-Encoding"))
+		}
+		if _, err := gzip.NewReader(w.Body); err != nil {
+			t.Errorf("Expected valid gzip body, got %v", err)
+		}
+	})
+
+	t.Run("coalesces concurrent misses", func(t *testing.T) {
+		calls.Store(0)
+		slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			time.Sleep(50 * time.Millisecond)
+			w.Write([]byte("slow"))
+		})
+		h := MicroCache(time.Minute)(slow)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if w := get(h, ""); w.Body.String() != "slow" {
+					t.Errorf("Expected shared body, got '%s'", w.Body.String())
+				}
+			}()
+		}
+		wg.Wait()
+
+		if calls.Load() != 1 {
+			t.Errorf("Expected a single render for the stampede, got %d", calls.Load())
+		}
+	})
+
+	t.Run("does not share unsafe responses with concurrent misses", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			handle func(w http.ResponseWriter, user string)
+		}{
+			{"set-cookie", func(w http.ResponseWriter, user string) { w.Header().Set("Set-Cookie", "session="+user) }},
+			{"private", func(w http.ResponseWriter, user string) { w.Header().Set("Cache-Control", "private") }},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				release := make(chan struct{})
+				h := MicroCache(time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					user := r.Header.Get("X-User")
+					if user == "alice" {
+						// Hold the render so bob's miss joins it
+						<-release
+					}
+					tt.handle(w, user)
+					w.Write([]byte("hello " + user))
+				}))
+
+				serve := func(user string) *httptest.ResponseRecorder {
+					req := httptest.NewRequest("GET", "/account", nil)
+					req.Header.Set("X-User", user)
+					w := httptest.NewRecorder()
+					h.ServeHTTP(w, req)
+					return w
+				}
+
+				results := make(chan *httptest.ResponseRecorder, 2)
+				go func() { results <- serve("alice") }()
+				time.Sleep(20 * time.Millisecond)
+				go func() { results <- serve("bob") }()
+				time.Sleep(20 * time.Millisecond)
+				close(release)
+
+				bodies := map[string]bool{}
+				for i := 0; i < 2; i++ {
+					w := <-results
+					bodies[w.Body.String()] = true
+					if cookie := w.Header().Get("Set-Cookie"); cookie != "" && !strings.HasSuffix(w.Body.String(), strings.TrimPrefix(cookie, "session=")) {
+						t.Errorf("Expected each client to get its own cookie, got '%s' with body '%s'", cookie, w.Body.String())
+					}
+				}
+				if !bodies["hello alice"] || !bodies["hello bob"] {
+					t.Errorf("Expected each client to get its own response, got %v", bodies)
+				}
+			})
+		}
+	})
+
+	t.Run("does not cache unsafe responses", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			handle func(w http.ResponseWriter)
+		}{
+			{"non-200", func(w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) }},
+			{"no-store", func(w http.ResponseWriter) { w.Header().Set("Cache-Control", "no-store") }},
+			{"set-cookie", func(w http.ResponseWriter) { w.Header().Set("Set-Cookie", "a=1") }},
+			{"vary on cookie", func(w http.ResponseWriter) { w.Header().Set("Vary", "Cookie") }},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				calls.Store(0)
+				h := MicroCache(time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calls.Add(1)
+					tt.handle(w)
+				}))
+
+				get(h, "")
+				get(h, "")
+
+				if calls.Load() != 2 {
+					t.Errorf("Expected no caching, got %d calls", calls.Load())
+				}
+			})
+		}
+	})
+
+	t.Run("replays a fresh CSP nonce on every hit", func(t *testing.T) {
+		calls.Store(0)
+		page := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			nonce, _ := CSPNonceFromContext(r.Context())
+			w.Write([]byte(`<script nonce="` + nonce + `"></script>`))
+		})
+		h := SecurityHeaders(MicroCache(time.Minute)(page))
+
+		seen := map[string]bool{}
+		for i := 0; i < 2; i++ {
+			w := get(h, "")
+			nonce := strings.TrimSuffix(strings.TrimPrefix(w.Body.String(), `<script nonce="`), `"></script>`)
+			if !strings.Contains(w.Header().Get("Content-Security-Policy"), "'nonce-"+nonce+"'") {
+				t.Errorf("Expected body nonce '%s' to match the CSP header, got '%s'", nonce, w.Header().Get("Content-Security-Policy"))
+			}
+			seen[nonce] = true
+		}
+
+		if calls.Load() != 1 {
+			t.Errorf("Expected the second request to be a hit, got %d calls", calls.Load())
+		}
+		if len(seen) != 2 {
+			t.Errorf("Expected two different nonces, got %v", seen)
+		}
+	})
+
+	t.Run("does not cache encoded bodies carrying a nonce", func(t *testing.T) {
+		calls.Store(0)
+		page := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			nonce, _ := CSPNonceFromContext(r.Context())
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<script nonce="` + nonce + `"></script>` + strings.Repeat(" ", 2048)))
+		})
+		h := SecurityHeaders(MicroCache(time.Minute)(Compress(nil, DefaultCompressLevel)(page)))
+
+		get(h, "gzip")
+		get(h, "gzip")
+
+		if calls.Load() != 2 {
+			t.Errorf("Expected no caching, got %d calls", calls.Load())
+		}
+	})
+
+	t.Run("conditional requests bypass the cache", func(t *testing.T) {
+		calls.Store(0)
+		h := MicroCache(time.Minute)(handler)
+		get(h, "")
+
+		req := httptest.NewRequest("GET", "/guitars", nil)
+		req.Header.Set("If-None-Match", `"abc"`)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+
+		if calls.Load() != 2 {
+			t.Errorf("Expected conditional request to reach the handler, got %d calls", calls.Load())
+		}
+	})
+}
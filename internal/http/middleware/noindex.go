@@ -0,0 +1,21 @@
+package middleware
+
+import "net/http"
+
+// NoIndexValue asks crawlers not to index a response or follow its links.
+const NoIndexValue = "noindex, nofollow"
+
+// NoIndex sets X-Robots-Tag on every response while enabled, so a staging or
+// development deployment stays out of search results even for crawlers that
+// ignore robots.txt. A disabled middleware is a no-op.
+func NoIndex(enabled bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !enabled {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Robots-Tag", NoIndexValue)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
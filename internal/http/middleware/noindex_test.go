@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNoIndex(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name    string
+		enabled bool
+		want    string
+	}{
+		{name: "enabled", enabled: true, want: NoIndexValue},
+		{name: "disabled", enabled: false, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			NoIndex(tt.enabled)(handler).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+			if got := w.Header().Get("X-Robots-Tag"); got != tt.want {
+				t.Errorf("Expected X-Robots-Tag '%s', got '%s'", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import "net/http"
+
+// NoStoreValue is the Cache-Control value set on sensitive responses.
+const NoStoreValue = "no-store, max-age=0"
+
+// SetNoStore marks a response as uncacheable by any cache, shared or private.
+// Pragma covers HTTP/1.0 caches; Expires and validators are dropped because
+// they only make sense for a stored response.
+func SetNoStore(h http.Header) {
+	h.Set("Cache-Control", NoStoreValue)
+	h.Set("Pragma", "no-cache")
+	h.Del("Expires")
+	h.Del("ETag")
+	h.Del("Last-Modified")
+}
+
+// NoStore forbids caching of every response from next. The headers are applied
+// when the response is committed, so they win over any cache directive set
+// earlier in the chain or by the handler itself.
+func NoStore(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&noStoreWriter{ResponseWriter: w}, r)
+	})
+}
+
+// noStoreWriter applies SetNoStore just before the status line is written.
+type noStoreWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (nw *noStoreWriter) WriteHeader(code int) {
+	if !nw.wroteHeader {
+		nw.wroteHeader = true
+		SetNoStore(nw.ResponseWriter.Header())
+	}
+	nw.ResponseWriter.WriteHeader(code)
+}
+
+func (nw *noStoreWriter) Write(b []byte) (int, error) {
+	if !nw.wroteHeader {
+		nw.WriteHeader(http.StatusOK)
+	}
+	return nw.ResponseWriter.Write(b)
+}
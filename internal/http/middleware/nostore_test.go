@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNoStore(t *testing.T) {
+	// publicCache stands in for a middleware marking pages publicly cacheable
+	publicCache := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", "public, max-age=3600")
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+	}{
+		{
+			name: "handler without directives",
+			handler: NoStore(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("secret"))
+			})),
+		},
+		{
+			name: "public cache set earlier in the chain",
+			handler: publicCache(NoStore(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("secret"))
+			}))),
+		},
+		{
+			name: "public cache set by the handler",
+			handler: NoStore(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", "public, max-age=86400")
+				w.Header().Set("Expires", "Thu, 01 Jan 2099 00:00:00 GMT")
+				w.Header().Set("ETag", `"abc"`)
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte("secret"))
+			})),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, httptest.NewRequest("GET", "/account", nil))
+
+			if cc := w.Header().Get("Cache-Control"); cc != "no-store, max-age=0" {
+				t.Errorf("Expected Cache-Control 'no-store, max-age=0', got '%s'", cc)
+			}
+			if pragma := w.Header().Get("Pragma"); pragma != "no-cache" {
+				t.Errorf("Expected Pragma 'no-cache', got '%s'", pragma)
+			}
+			if w.Header().Get("Expires") != "" || w.Header().Get("ETag") != "" {
+				t.Errorf("Expected Expires and ETag to be removed, got '%s' and '%s'", w.Header().Get("Expires"), w.Header().Get("ETag"))
+			}
+			if w.Body.String() != "secret" {
+				t.Errorf("Expected body 'secret', got '%s'", w.Body.String())
+			}
+		})
+	}
+}
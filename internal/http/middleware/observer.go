@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+)
+
+// ResponseObserver wraps a ResponseWriter and records what passes through it:
+// the status code, the number of body bytes and, when enabled, the head of the
+// body. Everything is forwarded unchanged, so it suits middleware that only
+// needs to look at a response, such as access logging, and tests.
+//
+// It implements http.Flusher and http.Hijacker by delegating through
+// http.ResponseController, and Unwrap exposes the wrapped writer.
+type ResponseObserver struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+
+	bodyLimit int // Body bytes to keep; zero keeps none
+	body      bytes.Buffer
+	truncated bool
+}
+
+// NewResponseObserver returns an observer of w that records the status and
+// byte count but not the body.
+func NewResponseObserver(w http.ResponseWriter) *ResponseObserver {
+	return &ResponseObserver{ResponseWriter: w}
+}
+
+// NewResponseObserverWithBody is NewResponseObserver that also keeps the first
+// limit bytes of the body. The full body is always forwarded.
+func NewResponseObserverWithBody(w http.ResponseWriter, limit int) *ResponseObserver {
+	return &ResponseObserver{ResponseWriter: w, bodyLimit: max(limit, 0)}
+}
+
+// Status returns the status code sent, or 200 if the handler has not written
+// a header yet, since that is what net/http sends for it.
+func (o *ResponseObserver) Status() int {
+	if !o.wroteHeader {
+		return http.StatusOK
+	}
+	return o.status
+}
+
+// WroteHeader reports whether a final status has been sent, explicitly or by
+// the first Write or Flush.
+func (o *ResponseObserver) WroteHeader() bool { return o.wroteHeader }
+
+// BytesWritten returns the number of body bytes written downstream.
+func (o *ResponseObserver) BytesWritten() int64 { return o.bytes }
+
+// Body returns the kept head of the body; it is empty unless the observer was
+// created with NewResponseObserverWithBody.
+func (o *ResponseObserver) Body() []byte { return o.body.Bytes() }
+
+// BodyTruncated reports whether the body was longer than the kept head.
+func (o *ResponseObserver) BodyTruncated() bool { return o.truncated }
+
+// WriteHeader records the first final status code before delegating.
+// Informational 1xx codes are forwarded without being recorded.
+func (o *ResponseObserver) WriteHeader(code int) {
+	if !o.wroteHeader && code >= http.StatusOK {
+		o.status = code
+		o.wroteHeader = true
+	}
+	o.ResponseWriter.WriteHeader(code)
+}
+
+// Write counts, and optionally keeps, the bytes before delegating.
+func (o *ResponseObserver) Write(b []byte) (int, error) {
+	o.markWritten()
+	if o.bodyLimit > 0 {
+		if room := o.bodyLimit - o.body.Len(); room > 0 {
+			if len(b) > room {
+				o.body.Write(b[:room])
+				o.truncated = true
+			} else {
+				o.body.Write(b)
+			}
+		} else if len(b) > 0 {
+			o.truncated = true
+		}
+	}
+	n, err := o.ResponseWriter.Write(b)
+	o.bytes += int64(n)
+	return n, err
+}
+
+// Flush sends buffered data to the client if the wrapped writer supports it.
+func (o *ResponseObserver) Flush() {
+	o.markWritten()
+	_ = http.NewResponseController(o.ResponseWriter).Flush()
+}
+
+// Hijack hands over the connection if the wrapped writer supports it.
+func (o *ResponseObserver) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(o.ResponseWriter).Hijack()
+}
+
+// Unwrap exposes the wrapped writer so http.ResponseController can reach
+// optional interfaces such as http.Flusher.
+func (o *ResponseObserver) Unwrap() http.ResponseWriter { return o.ResponseWriter }
+
+// markWritten records the implicit 200 that net/http sends when the body is
+// written or flushed before WriteHeader.
+func (o *ResponseObserver) markWritten() {
+	if !o.wroteHeader {
+		o.status = http.StatusOK
+		o.wroteHeader = true
+	}
+}
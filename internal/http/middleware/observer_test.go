@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseObserver(t *testing.T) {
+	tests := []struct {
+		name        string
+		limit       int
+		handler     func(w http.ResponseWriter)
+		wantStatus  int
+		wantBytes   int64
+		wantBody    string
+		wantTrunc   bool
+		wantWritten bool
+	}{
+		{
+			name:       "nothing written",
+			handler:    func(w http.ResponseWriter) {},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "explicit status",
+			handler: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte("missing"))
+			},
+			wantStatus:  http.StatusNotFound,
+			wantBytes:   7,
+			wantWritten: true,
+		},
+		{
+			name: "implicit 200 on write",
+			handler: func(w http.ResponseWriter) {
+				_, _ = w.Write([]byte("hello"))
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			wantStatus:  http.StatusOK,
+			wantBytes:   5,
+			wantWritten: true,
+		},
+		{
+			name: "informational status is not final",
+			handler: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusEarlyHints)
+				w.WriteHeader(http.StatusCreated)
+			},
+			wantStatus:  http.StatusCreated,
+			wantWritten: true,
+		},
+		{
+			name:  "body kept within limit",
+			limit: 16,
+			handler: func(w http.ResponseWriter) {
+				_, _ = w.Write([]byte("hello "))
+				_, _ = w.Write([]byte("world"))
+			},
+			wantStatus:  http.StatusOK,
+			wantBytes:   11,
+			wantBody:    "hello world",
+			wantWritten: true,
+		},
+		{
+			name:  "body truncated at limit",
+			limit: 4,
+			handler: func(w http.ResponseWriter) {
+				_, _ = w.Write([]byte("hello"))
+				_, _ = w.Write([]byte("world"))
+			},
+			wantStatus:  http.StatusOK,
+			wantBytes:   10,
+			wantBody:    "hell",
+			wantTrunc:   true,
+			wantWritten: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			o := NewResponseObserverWithBody(rec, tt.limit)
+			tt.handler(o)
+
+			if o.Status() != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, o.Status())
+			}
+			if o.WroteHeader() != tt.wantWritten {
+				t.Errorf("Expected WroteHeader %v, got %v", tt.wantWritten, o.WroteHeader())
+			}
+			if o.BytesWritten() != tt.wantBytes {
+				t.Errorf("Expected %d bytes, got %d", tt.wantBytes, o.BytesWritten())
+			}
+			if string(o.Body()) != tt.wantBody {
+				t.Errorf("Expected body '%s', got '%s'", tt.wantBody, o.Body())
+			}
+			if o.BodyTruncated() != tt.wantTrunc {
+				t.Errorf("Expected truncated %v, got %v", tt.wantTrunc, o.BodyTruncated())
+			}
+			// The full body always reaches the client
+			if int64(rec.Body.Len()) != tt.wantBytes {
+				t.Errorf("Expected %d bytes forwarded, got %d", tt.wantBytes, rec.Body.Len())
+			}
+		})
+	}
+}
+
+func TestResponseObserver_Flush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	var w http.ResponseWriter = NewResponseObserver(rec)
+
+	f, ok := w.(http.Flusher)
+	if !ok {
+		t.Fatal("Expected ResponseObserver to implement http.Flusher")
+	}
+	f.Flush()
+
+	if !rec.Flushed {
+		t.Error("Expected Flush to reach the wrapped writer")
+	}
+	if o := w.(*ResponseObserver); !o.WroteHeader() || o.Status() != http.StatusOK {
+		t.Errorf("Expected Flush to send an implicit 200, got %d", o.Status())
+	}
+}
+
+// hijackRecorder is a ResponseRecorder whose connection can be hijacked.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	conn net.Conn
+}
+
+func (h *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return h.conn, nil, nil
+}
+
+func TestResponseObserver_Hijack(t *testing.T) {
+	t.Run("supported", func(t *testing.T) {
+		server, client := net.Pipe()
+		defer server.Close()
+		defer client.Close()
+
+		var w http.ResponseWriter = NewResponseObserver(&hijackRecorder{ResponseRecorder: httptest.NewRecorder(), conn: server})
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			t.Fatal("Expected ResponseObserver to implement http.Hijacker")
+		}
+		conn, _, err := hj.Hijack()
+		if err != nil || conn != server {
+			t.Errorf("Expected the wrapped connection, got %v, %v", conn, err)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, _, err := NewResponseObserver(httptest.NewRecorder()).Hijack()
+		if !errors.Is(err, http.ErrNotSupported) {
+			t.Errorf("Expected http.ErrNotSupported, got %v", err)
+		}
+	})
+}
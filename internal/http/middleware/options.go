@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// optionsProbeMethods are the methods AutoOptions checks against the router, in
+// the order they are listed in the Allow header.
+var optionsProbeMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// AutoOptions answers OPTIONS requests with 204 and an Allow header listing the
+// methods mux has registered for the request path, plus OPTIONS itself. Other
+// methods, and paths no route matches, are passed to mux unchanged.
+//
+// Patterns without a method (such as the "/" catch-all) match every method but
+// only serve reads, so they contribute GET and HEAD.
+func AutoOptions(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		allow := allowedMethods(mux, r)
+		if len(allow) == 0 {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Allow", strings.Join(append(allow, http.MethodOptions), ", "))
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// allowedMethods probes mux with each candidate method for r's path.
+func allowedMethods(mux *http.ServeMux, r *http.Request) []string {
+	allowed := make(map[string]bool, len(optionsProbeMethods))
+	probe := r.Clone(r.Context())
+	for _, method := range optionsProbeMethods {
+		probe.Method = method
+		_, pattern := mux.Handler(probe)
+		if pattern == "" {
+			continue
+		}
+		if strings.Contains(pattern, " ") {
+			allowed[method] = true
+		} else {
+			allowed[http.MethodGet] = true
+			allowed[http.MethodHead] = true
+		}
+	}
+
+	methods := make([]string, 0, len(allowed))
+	for _, method := range optionsProbeMethods {
+		if allowed[method] {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
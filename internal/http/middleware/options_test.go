@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAutoOptions(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /guitars", ok)
+	mux.Handle("GET /items/{id}", ok)
+	mux.Handle("DELETE /items/{id}", ok)
+	mux.Handle("/static/", ok)
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		wantAllow  string
+	}{
+		{name: "GET route", method: "OPTIONS", target: "/guitars", wantStatus: http.StatusNoContent, wantAllow: "GET, HEAD, OPTIONS"},
+		{name: "several methods", method: "OPTIONS", target: "/items/1", wantStatus: http.StatusNoContent, wantAllow: "GET, HEAD, DELETE, OPTIONS"},
+		{name: "method-less pattern", method: "OPTIONS", target: "/static/app.css", wantStatus: http.StatusNoContent, wantAllow: "GET, HEAD, OPTIONS"},
+		{name: "unknown path", method: "OPTIONS", target: "/missing", wantStatus: http.StatusNotFound},
+		{name: "other methods pass through", method: "GET", target: "/guitars", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			AutoOptions(mux).ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if allow := w.Header().Get("Allow"); allow != tt.wantAllow {
+				t.Errorf("Expected Allow '%s', got '%s'", tt.wantAllow, allow)
+			}
+		})
+	}
+}
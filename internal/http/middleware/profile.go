@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// ProfileConfig controls which requests ProfileRequests measures and reports.
+type ProfileConfig struct {
+	SampleRate    int           // Measure one request in SampleRate; values below 1 measure every request
+	MinAllocBytes uint64        // Report sampled requests allocating at least this many bytes (0 ignores allocations)
+	MinDuration   time.Duration // Report sampled requests taking at least this long (0 ignores duration)
+}
+
+// exceeded reports whether a measured request crosses a configured threshold.
+// With no thresholds set, every sampled request is reported.
+func (c ProfileConfig) exceeded(allocBytes uint64, duration time.Duration) bool {
+	if c.MinAllocBytes == 0 && c.MinDuration == 0 {
+		return true
+	}
+	return (c.MinAllocBytes > 0 && allocBytes >= c.MinAllocBytes) ||
+		(c.MinDuration > 0 && duration >= c.MinDuration)
+}
+
+// ProfileRequests logs allocation deltas and duration for sampled requests that
+// exceed either threshold, to find heavy handlers without running pprof.
+//
+// runtime.ReadMemStats briefly stops the world, so only one request in
+// SampleRate is measured. Its counters are process-wide: allocations made by
+// concurrent requests are included, so a single report is a hint rather than an
+// exact figure. A disabled middleware is a no-op.
+func ProfileRequests(logger *slog.Logger, enabled bool, cfg ProfileConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !enabled {
+			return next
+		}
+
+		var seen atomic.Uint64
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cfg.SampleRate > 1 && seen.Add(1)%uint64(cfg.SampleRate) != 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			start := time.Now()
+
+			next.ServeHTTP(w, r)
+
+			duration := time.Since(start)
+			runtime.ReadMemStats(&after)
+
+			allocBytes := after.TotalAlloc - before.TotalAlloc
+			if !cfg.exceeded(allocBytes, duration) {
+				return
+			}
+
+			reqLogger := logger
+			if rid, ok := RequestIDFromContext(r.Context()); ok {
+				reqLogger = reqLogger.With("request_id", rid)
+			}
+			reqLogger.Warn("heavy request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"duration_ms", duration.Milliseconds(),
+				"alloc_bytes", allocBytes,
+				"allocs", after.Mallocs-before.Mallocs,
+				"gc_cycles", after.NumGC-before.NumGC,
+			)
+		})
+	}
+}
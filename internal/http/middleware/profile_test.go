@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// heavySink keeps the heavy handler's allocations from being optimised away.
+var heavySink [][]byte
+
+func TestProfileRequests(t *testing.T) {
+	heavy := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		heavySink = nil
+		for range 64 {
+			heavySink = append(heavySink, make([]byte, 64<<10))
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	light := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	cfg := ProfileConfig{SampleRate: 1, MinAllocBytes: 1 << 20}
+
+	serve := func(handler http.Handler) string {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&buf, nil))
+		handler = ProfileRequests(logger, true, cfg)(handler)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/heavy", nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+		return buf.String()
+	}
+
+	t.Run("logs a heavy request", func(t *testing.T) {
+		out := serve(heavy)
+		if !strings.Contains(out, `msg="heavy request"`) || !strings.Contains(out, "path=/heavy") {
+			t.Errorf("Expected a heavy request log, got '%s'", out)
+		}
+		if !strings.Contains(out, "alloc_bytes=") || !strings.Contains(out, "duration_ms=") {
+			t.Errorf("Expected allocation and duration fields, got '%s'", out)
+		}
+	})
+
+	t.Run("skips requests under the thresholds", func(t *testing.T) {
+		if out := serve(light); out != "" {
+			t.Errorf("Expected no log for a light request, got '%s'", out)
+		}
+	})
+
+	t.Run("samples one request in N", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&buf, nil))
+		handler := ProfileRequests(logger, true, ProfileConfig{SampleRate: 3})(light)
+
+		for range 6 {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		}
+		if n := strings.Count(buf.String(), "heavy request"); n != 2 {
+			t.Errorf("Expected 2 sampled reports, got %d", n)
+		}
+	})
+
+	t.Run("disabled is a no-op", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&buf, nil))
+		ProfileRequests(logger, false, cfg)(heavy).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/heavy", nil))
+
+		if buf.Len() != 0 {
+			t.Errorf("Expected no log when disabled, got '%s'", buf.String())
+		}
+	})
+}
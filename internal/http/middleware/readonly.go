@@ -0,0 +1,26 @@
+package middleware
+
+import "net/http"
+
+// ReadOnlyMessage is returned to write requests while read-only mode is on.
+const ReadOnlyMessage = "Service is in read-only mode for maintenance; writes are temporarily disabled"
+
+// ReadOnly rejects state-changing requests (POST, PUT, PATCH, DELETE) with 503
+// while enabled, so nothing is written to the database during a maintenance
+// window. Reads are served normally; unlike full maintenance mode, the site
+// stays browsable. A disabled middleware is a no-op.
+func ReadOnly(enabled bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !enabled {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+				respondError(w, r, http.StatusServiceUnavailable, ReadOnlyMessage)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
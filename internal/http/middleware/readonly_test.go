@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadOnly(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+
+	t.Run("blocks writes when enabled", func(t *testing.T) {
+		for _, method := range []string{"POST", "PUT", "PATCH", "DELETE"} {
+			req := httptest.NewRequest(method, "/guitars", nil)
+			w := httptest.NewRecorder()
+			ReadOnly(true)(handler).ServeHTTP(w, req)
+
+			assertPlainError(t, w, http.StatusServiceUnavailable, ReadOnlyMessage)
+		}
+	})
+
+	t.Run("blocked writes honour JSON clients", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/guitars", nil)
+		req.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		ReadOnly(true)(handler).ServeHTTP(w, req)
+
+		assertJSONError(t, w, http.StatusServiceUnavailable, ReadOnlyMessage)
+	})
+
+	t.Run("reads pass when enabled", func(t *testing.T) {
+		for _, method := range []string{"GET", "HEAD", "OPTIONS"} {
+			req := httptest.NewRequest(method, "/guitars", nil)
+			w := httptest.NewRecorder()
+			ReadOnly(true)(handler).ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status 200 for %s, got %d", method, w.Code)
+			}
+		}
+	})
+
+	t.Run("writes pass when disabled", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/guitars", nil)
+		w := httptest.NewRecorder()
+		ReadOnly(false)(handler).ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+	})
+}
@@ -1,8 +1,11 @@
 package middleware
 
 import (
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -210,4 +213,161 @@ func TestRealIP(t *testing.T) {
 			t.Errorf("Expected RemoteAddr to be '%s', got '%s'", expectedIP, req.RemoteAddr)
 		}
 	})
+
+	t.Run("restricts to configured headers", func(t *testing.T) {
+		trustedProxies := []string{"127.0.0.1", "::1"}
+		middleware := RealIPWithHeaders(trustedProxies, []string{"CF-Connecting-IP"})(handler)
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = "127.0.0.1:12345"
+		req.Header.Set("X-Forwarded-For", "192.0.2.66")
+		req.Header.Set("CF-Connecting-IP", "198.51.100.7")
+		w := httptest.NewRecorder()
+
+		middleware.ServeHTTP(w, req)
+
+		// X-Forwarded-For is ignored even though it would win by default
+		expectedIP := "198.51.100.7"
+		if req.RemoteAddr != expectedIP {
+			t.Errorf("Expected RemoteAddr to be '%s', got '%s'", expectedIP, req.RemoteAddr)
+		}
+	})
+
+	t.Run("ignores unlisted headers", func(t *testing.T) {
+		trustedProxies := []string{"127.0.0.1", "::1"}
+		middleware := RealIPWithHeaders(trustedProxies, []string{"CF-Connecting-IP"})(handler)
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = "127.0.0.1:12345"
+		req.Header.Set("X-Forwarded-For", "192.0.2.66")
+		w := httptest.NewRecorder()
+
+		middleware.ServeHTTP(w, req)
+
+		expectedIP := "127.0.0.1:12345"
+		if req.RemoteAddr != expectedIP {
+			t.Errorf("Expected RemoteAddr to be '%s', got '%s'", expectedIP, req.RemoteAddr)
+		}
+	})
+}
+
+func TestRealIP_ForwardedChainLimit(t *testing.T) {
+	trusted := newTrustedProxySet([]string{"127.0.0.1"})
+
+	// chain builds "203.0.113.1, 10.0.0.1, 10.0.0.1, ..." with n entries
+	chain := func(n int) string {
+		return "203.0.113.1" + strings.Repeat(", 10.0.0.1", n-1)
+	}
+
+	newRequest := func(xff string) *http.Request {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = "127.0.0.1:12345"
+		req.Header.Set("X-Forwarded-For", xff)
+		req.Header.Set("X-Real-IP", "198.51.100.1")
+		return req
+	}
+
+	t.Run("accepts a chain within the limit", func(t *testing.T) {
+		got := extractRealIP(newRequest(chain(16)), trusted, DefaultRealIPHeaders, 16)
+		if got != "203.0.113.1" {
+			t.Errorf("Expected '203.0.113.1', got '%s'", got)
+		}
+	})
+
+	t.Run("ignores a chain over the limit", func(t *testing.T) {
+		got := extractRealIP(newRequest(chain(17)), trusted, DefaultRealIPHeaders, 16)
+		if got != "198.51.100.1" {
+			t.Errorf("Expected fallback to X-Real-IP '198.51.100.1', got '%s'", got)
+		}
+	})
+
+	t.Run("10,000-entry header costs bounded work", func(t *testing.T) {
+		req := newRequest(chain(10000))
+
+		if got := extractRealIP(req, trusted, DefaultRealIPHeaders, 16); got != "198.51.100.1" {
+			t.Errorf("Expected fallback to X-Real-IP '198.51.100.1', got '%s'", got)
+		}
+
+		// Rejection happens on length alone, so it costs no more than having no
+		// X-Forwarded-For at all: no per-entry allocations
+		absent := newRequest("")
+		hugeAllocs := testing.AllocsPerRun(100, func() { extractRealIP(req, trusted, DefaultRealIPHeaders, 16) })
+		absentAllocs := testing.AllocsPerRun(100, func() { extractRealIP(absent, trusted, DefaultRealIPHeaders, 16) })
+		if hugeAllocs > absentAllocs {
+			t.Errorf("Expected no more allocations than without the header (%v), got %v", absentAllocs, hugeAllocs)
+		}
+	})
+
+	t.Run("configurable through RealIPWithConfig", func(t *testing.T) {
+		middleware := RealIPWithConfig(RealIPConfig{
+			TrustedProxies:      []string{"127.0.0.1"},
+			Headers:             []string{"X-Forwarded-For"},
+			MaxForwardedEntries: 2,
+		})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		req := newRequest(chain(3))
+		middleware.ServeHTTP(httptest.NewRecorder(), req)
+
+		if req.RemoteAddr != "127.0.0.1:12345" {
+			t.Errorf("Expected direct address for a 3-entry chain with a limit of 2, got '%s'", req.RemoteAddr)
+		}
+	})
+}
+
+func BenchmarkRealIP(b *testing.B) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, n := range []int{2, 64, 1024} {
+		proxies := make([]string, 0, n)
+		for i := range n {
+			proxies = append(proxies, fmt.Sprintf("10.%d.%d.1", i/256, i%256))
+		}
+		handler := RealIP(proxies)(next)
+		last := proxies[len(proxies)-1]
+
+		for _, tc := range []struct {
+			name       string
+			remoteAddr string
+		}{
+			{"trusted", last + ":12345"},
+			{"untrusted", "203.0.113.9:12345"},
+		} {
+			b.Run(fmt.Sprintf("%s/%d", tc.name, n), func(b *testing.B) {
+				req := httptest.NewRequest("GET", "/", nil)
+				req.Header.Set("X-Forwarded-For", "198.51.100.1")
+				w := httptest.NewRecorder()
+
+				b.ReportAllocs()
+				for b.Loop() {
+					req.RemoteAddr = tc.remoteAddr
+					handler.ServeHTTP(w, req)
+				}
+			})
+		}
+	}
+}
+
+func TestTrustedProxySet(t *testing.T) {
+	set := newTrustedProxySet([]string{"10.0.0.1", "::ffff:192.0.2.1", "2001:db8::1", "not-an-ip"})
+
+	tests := []struct {
+		ip   net.IP
+		want bool
+	}{
+		{net.ParseIP("10.0.0.1"), true},
+		{net.ParseIP("::ffff:10.0.0.1"), true},
+		{net.ParseIP("192.0.2.1"), true},
+		{net.ParseIP("2001:db8::1"), true},
+		{net.ParseIP("10.0.0.2"), false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := set.contains(tt.ip); got != tt.want {
+			t.Errorf("contains(%v): Expected %v, got %v", tt.ip, tt.want, got)
+		}
+	}
+	if len(set) != 3 {
+		t.Errorf("Expected 3 parsed proxies, got %d", len(set))
+	}
 }
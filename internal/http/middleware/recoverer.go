@@ -3,6 +3,7 @@ package middleware
 import (
 	"log/slog"
 	"net/http"
+	"runtime"
 	"runtime/debug"
 )
 
@@ -14,6 +15,11 @@ func Recoverer(logger *slog.Logger) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if err := recover(); err != nil {
+					// ErrAbortHandler asks the server to abort the response silently
+					if err == http.ErrAbortHandler {
+						panic(err)
+					}
+
 					// Log the panic details for debugging
 					logger.Error("panic recovered",
 						"error", err,
@@ -21,11 +27,15 @@ func Recoverer(logger *slog.Logger) func(http.Handler) http.Handler {
 						"path", r.URL.Path,
 						"remote_addr", r.RemoteAddr,
 						"user_agent", r.UserAgent(),
+						"nil_panic", isNilPanic(err),
 						"stack", string(debug.Stack()),
 					)
 
+					// Let an observing ErrorLog show what panicked
+					notePanic(r.Context(), err)
+
 					// Return a 500 Internal Server Error to the client
-					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+					respondError(w, r, http.StatusInternalServerError, "Internal Server Error")
 				}
 			}()
 
@@ -33,3 +43,10 @@ func Recoverer(logger *slog.Logger) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// isNilPanic reports whether a recovered value came from panic(nil), which
+// Go 1.21+ turns into a *runtime.PanicNilError.
+func isNilPanic(v any) bool {
+	_, ok := v.(*runtime.PanicNilError)
+	return ok
+}
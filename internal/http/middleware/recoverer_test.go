@@ -231,4 +231,58 @@ func TestRecoverer(t *testing.T) {
 			t.Error("Expected panic value to be logged")
 		}
 	})
+
+	t.Run("re-panics http.ErrAbortHandler", func(t *testing.T) {
+		logOutput.Reset()
+		abortHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		})
+
+		middleware := Recoverer(logger)(abortHandler)
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+
+		var recovered any
+		func() {
+			defer func() { recovered = recover() }()
+			middleware.ServeHTTP(w, req)
+		}()
+
+		if recovered != http.ErrAbortHandler {
+			t.Errorf("Expected http.ErrAbortHandler to propagate, got %v", recovered)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("Expected no response body, got '%s'", w.Body.String())
+		}
+		if strings.Contains(logOutput.String(), "panic recovered") {
+			t.Error("Expected abort not to be logged as a panic")
+		}
+	})
+
+	t.Run("logs panic(nil) clearly", func(t *testing.T) {
+		logOutput.Reset()
+		nilHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(nil)
+		})
+
+		middleware := Recoverer(logger)(nilHandler)
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+
+		middleware.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+
+		logContent := logOutput.String()
+		if !strings.Contains(logContent, "nil_panic=true") {
+			t.Errorf("Expected nil panic to be flagged, got: %s", logContent)
+		}
+		if !strings.Contains(logContent, "panic called with nil argument") {
+			t.Errorf("Expected PanicNilError message to be logged, got: %s", logContent)
+		}
+	})
 }
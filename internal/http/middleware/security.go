@@ -5,51 +5,115 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"net/http"
+	"strings"
+
+	"guitar-specs/internal/permissionspolicy"
 )
 
+// DefaultPermissionsPolicy disables browser APIs the site does not use. It is
+// sent when SecurityConfig.PermissionsPolicy is empty.
+const DefaultPermissionsPolicy = permissionspolicy.Default
+
+// SecurityConfig holds the tunable parts of the security header set.
+// Each CSP allowlist is merged into its directive alongside 'self'; empty
+// lists keep the hardened default.
+type SecurityConfig struct {
+	ScriptSrc  []string // Extra script-src sources (e.g. an analytics host)
+	StyleSrc   []string // Extra style-src sources
+	ImgSrc     []string // Extra img-src sources
+	FontSrc    []string // Extra font-src sources (e.g. a font CDN)
+	ConnectSrc []string // Extra connect-src sources (XHR/fetch/WebSocket)
+
+	// PermissionsPolicy is the Permissions-Policy header value, as rendered by
+	// permissionspolicy.Header; empty sends DefaultPermissionsPolicy.
+	PermissionsPolicy string
+}
+
 // SecurityHeaders adds security-related HTTP headers to all responses.
 // This middleware implements defence-in-depth by setting multiple security headers
 // that protect against common web vulnerabilities. It also injects a per-request
 // CSP nonce for safe inline/templated scripts.
 func SecurityHeaders(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Prevent clickjacking attacks by disallowing frame embedding
-		w.Header().Set("X-Frame-Options", "DENY")
-
-		// Prevent MIME type sniffing which can lead to XSS attacks
-		w.Header().Set("X-Content-Type-Options", "nosniff")
-
-		// Enable legacy XSS protection for older browsers
-		w.Header().Set("X-XSS-Protection", "1; mode=block")
-
-		// Control referrer information leakage to third-party sites
-		w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
-
-		// Generate CSP nonce
-		var nonceBytes [16]byte
-		_, _ = rand.Read(nonceBytes[:])
-		nonce := base64.StdEncoding.EncodeToString(nonceBytes[:])
-
-		// Content Security Policy with nonce for scripts
-		csp := "default-src 'self'; " +
-			"script-src 'self' 'nonce-" + nonce + "'; " +
-			"style-src 'self'; " +
-			"img-src 'self' data:; " +
-			"font-src 'self'; " +
-			"object-src 'none'; " +
-			"base-uri 'self'; " +
-			"frame-ancestors 'none'"
-		w.Header().Set("Content-Security-Policy", csp)
-
-		// Restrict access to browser APIs that could be abused
-		w.Header().Set("Permissions-Policy", "geolocation=(), microphone=(), camera=()")
-
-		// Attach nonce to context so templates can access it
-		r = r.WithContext(WithCSPNonce(r.Context(), nonce))
-
-		// Note: HSTS is handled by Cloudflare CDN layer
-		next.ServeHTTP(w, r)
-	})
+	return SecurityHeadersWithConfig(SecurityConfig{})(next)
+}
+
+// SecurityHeadersWithConfig behaves like SecurityHeaders but merges the
+// configured external sources into the Content Security Policy and sends the
+// configured Permissions-Policy.
+func SecurityHeadersWithConfig(cfg SecurityConfig) func(http.Handler) http.Handler {
+	permissionsPolicy := cfg.PermissionsPolicy
+	if permissionsPolicy == "" {
+		permissionsPolicy = DefaultPermissionsPolicy
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Prevent clickjacking attacks by disallowing frame embedding
+			w.Header().Set("X-Frame-Options", "DENY")
+
+			// Prevent MIME type sniffing which can lead to XSS attacks
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+
+			// Enable legacy XSS protection for older browsers
+			w.Header().Set("X-XSS-Protection", "1; mode=block")
+
+			// Control referrer information leakage to third-party sites
+			w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
+
+			// Generate CSP nonce; URL-safe base64 needs no HTML escaping, so the
+			// nonce appears verbatim in rendered pages (see MicroCache)
+			var nonceBytes [16]byte
+			_, _ = rand.Read(nonceBytes[:])
+			nonce := base64.RawURLEncoding.EncodeToString(nonceBytes[:])
+
+			// Content Security Policy with nonce for scripts
+			w.Header().Set("Content-Security-Policy", buildCSP(cfg, nonce))
+
+			// Restrict access to browser APIs that could be abused
+			w.Header().Set("Permissions-Policy", permissionsPolicy)
+
+			// Attach nonce to context so templates can access it
+			r = r.WithContext(WithCSPNonce(r.Context(), nonce))
+
+			// Note: HSTS is handled by Cloudflare CDN layer
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// buildCSP assembles the Content Security Policy for a single request.
+// connect-src is only emitted when configured; otherwise default-src applies.
+func buildCSP(cfg SecurityConfig, nonce string) string {
+	directives := []string{
+		"default-src 'self'",
+		cspDirective("script-src", append([]string{"'self'", "'nonce-" + nonce + "'"}, cfg.ScriptSrc...)),
+		cspDirective("style-src", append([]string{"'self'"}, cfg.StyleSrc...)),
+		cspDirective("img-src", append([]string{"'self'", "data:"}, cfg.ImgSrc...)),
+		cspDirective("font-src", append([]string{"'self'"}, cfg.FontSrc...)),
+	}
+	if len(cfg.ConnectSrc) > 0 {
+		directives = append(directives, cspDirective("connect-src", append([]string{"'self'"}, cfg.ConnectSrc...)))
+	}
+	directives = append(directives,
+		"object-src 'none'",
+		"base-uri 'self'",
+		"frame-ancestors 'none'",
+	)
+	return strings.Join(directives, "; ")
+}
+
+// cspDirective renders a directive with its sources, dropping duplicates.
+func cspDirective(name string, sources []string) string {
+	seen := make(map[string]bool, len(sources))
+	parts := []string{name}
+	for _, src := range sources {
+		if src == "" || seen[src] {
+			continue
+		}
+		seen[src] = true
+		parts = append(parts, src)
+	}
+	return strings.Join(parts, " ")
 }
 
 // context key for CSP nonce
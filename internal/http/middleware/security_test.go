@@ -51,6 +51,11 @@ func TestSecurityHeaders(t *testing.T) {
 	if !strings.Contains(csp, "script-src 'self' 'nonce-") {
 		t.Errorf("CSP missing script-src nonce: %s", csp)
 	}
+	// Templates escape '+', '/' and '=', so the nonce must avoid them
+	_, nonce, _ := strings.Cut(csp, "'nonce-")
+	if nonce, _, _ = strings.Cut(nonce, "'"); strings.ContainsAny(nonce, "+/=") {
+		t.Errorf("Expected a URL-safe nonce, got '%s'", nonce)
+	}
 
 	// Verify response body is preserved
 	if w.Body.String() != "OK" {
@@ -89,3 +94,91 @@ func TestSecurityHeadersPreservesExistingHeaders(t *testing.T) {
 		t.Errorf("Expected X-Frame-Options to be 'DENY', got '%s'", value)
 	}
 }
+
+func TestSecurityHeadersWithConfig(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("merges configured sources into CSP", func(t *testing.T) {
+		middleware := SecurityHeadersWithConfig(SecurityConfig{
+			ImgSrc:     []string{"https://images.example.com"},
+			FontSrc:    []string{"https://fonts.gstatic.com"},
+			ConnectSrc: []string{"https://api.example.com"},
+		})(handler)
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+		middleware.ServeHTTP(w, req)
+
+		csp := w.Header().Get("Content-Security-Policy")
+		if !strings.Contains(csp, "img-src 'self' data: https://images.example.com;") {
+			t.Errorf("CSP missing configured img source: %s", csp)
+		}
+		if !strings.Contains(csp, "font-src 'self' https://fonts.gstatic.com;") {
+			t.Errorf("CSP missing configured font source: %s", csp)
+		}
+		if !strings.Contains(csp, "connect-src 'self' https://api.example.com;") {
+			t.Errorf("CSP missing configured connect source: %s", csp)
+		}
+		if !strings.Contains(csp, "script-src 'self' 'nonce-") {
+			t.Errorf("CSP lost script-src nonce: %s", csp)
+		}
+	})
+
+	t.Run("omits connect-src when not configured", func(t *testing.T) {
+		middleware := SecurityHeadersWithConfig(SecurityConfig{})(handler)
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+		middleware.ServeHTTP(w, req)
+
+		csp := w.Header().Get("Content-Security-Policy")
+		if strings.Contains(csp, "connect-src") {
+			t.Errorf("Expected no connect-src directive, got: %s", csp)
+		}
+	})
+
+	t.Run("drops duplicate sources", func(t *testing.T) {
+		middleware := SecurityHeadersWithConfig(SecurityConfig{
+			StyleSrc: []string{"'self'", "https://cdn.example.com", "https://cdn.example.com"},
+		})(handler)
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+		middleware.ServeHTTP(w, req)
+
+		csp := w.Header().Get("Content-Security-Policy")
+		if !strings.Contains(csp, "style-src 'self' https://cdn.example.com;") {
+			t.Errorf("Expected deduplicated style-src, got: %s", csp)
+		}
+	})
+}
+
+func TestSecurityHeadersPermissionsPolicy(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		policy string
+		want   string
+	}{
+		{name: "empty config falls back to the default", policy: "", want: DefaultPermissionsPolicy},
+		{name: "configured policy is emitted", policy: "camera=(self), geolocation=()", want: "camera=(self), geolocation=()"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middleware := SecurityHeadersWithConfig(SecurityConfig{PermissionsPolicy: tt.policy})(handler)
+
+			w := httptest.NewRecorder()
+			middleware.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+
+			if got := w.Header().Get("Permissions-Policy"); got != tt.want {
+				t.Errorf("Expected Permissions-Policy '%s', got '%s'", tt.want, got)
+			}
+		})
+	}
+}
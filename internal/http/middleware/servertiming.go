@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"guitar-specs/internal/timing"
+)
+
+// ServerTimingHeader emits a Server-Timing header built from the phases handlers,
+// models and the renderer record with timing.ServerTiming, plus a "total" entry.
+// The header is set just before the status is written, so only phases finished by
+// then appear; HTML responses are rendered into a buffer first, so they are complete.
+// Timings reveal server internals, so this is only wired in when enabled in config.
+func ServerTimingHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, rec := timing.NewContext(r.Context())
+		tw := &serverTimingWriter{ResponseWriter: w, rec: rec, start: time.Now()}
+		next.ServeHTTP(tw, r.WithContext(ctx))
+
+		if !tw.wroteHeader {
+			tw.WriteHeader(http.StatusOK)
+		}
+	})
+}
+
+// serverTimingWriter adds the Server-Timing header when the status is written.
+type serverTimingWriter struct {
+	http.ResponseWriter
+	rec         *timing.Recorder
+	start       time.Time
+	wroteHeader bool
+}
+
+func (tw *serverTimingWriter) WriteHeader(code int) {
+	if tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+
+	value := tw.rec.Header()
+	total := timing.FormatEntry("total", time.Since(tw.start))
+	if value != "" {
+		value += ", " + total
+	} else {
+		value = total
+	}
+	tw.Header().Set("Server-Timing", value)
+	tw.ResponseWriter.WriteHeader(code)
+}
+
+func (tw *serverTimingWriter) Write(b []byte) (int, error) {
+	if !tw.wroteHeader {
+		tw.WriteHeader(http.StatusOK)
+	}
+	return tw.ResponseWriter.Write(b)
+}
+
+// Flush writes the header first, as Write does, so a streamed response still
+// carries Server-Timing.
+func (tw *serverTimingWriter) Flush() {
+	if !tw.wroteHeader {
+		tw.WriteHeader(http.StatusOK)
+	}
+	_ = http.NewResponseController(tw.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the wrapped writer to http.ResponseController.
+func (tw *serverTimingWriter) Unwrap() http.ResponseWriter { return tw.ResponseWriter }
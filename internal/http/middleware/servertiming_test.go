@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"guitar-specs/internal/timing"
+)
+
+func TestServerTimingHeader(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timing.ServerTiming(r.Context(), "db", 12*time.Millisecond)
+		timing.ServerTiming(r.Context(), "render", 3*time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	w := httptest.NewRecorder()
+	ServerTimingHeader(handler).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	header := w.Header().Get("Server-Timing")
+	pattern := regexp.MustCompile(`^db;dur=12\.0, render;dur=3\.0, total;dur=\d+\.\d$`)
+	if !pattern.MatchString(header) {
+		t.Errorf("Expected Server-Timing like 'db;dur=12.0, render;dur=3.0, total;dur=N', got %q", header)
+	}
+	if w.Body.String() != "ok" {
+		t.Errorf("Expected body to pass through, got '%s'", w.Body.String())
+	}
+
+	t.Run("handler writing nothing still gets total", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ServerTimingHeader(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).
+			ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		if !regexp.MustCompile(`^total;dur=\d+\.\d$`).MatchString(w.Header().Get("Server-Timing")) {
+			t.Errorf("Expected total only, got %q", w.Header().Get("Server-Timing"))
+		}
+	})
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -14,10 +15,35 @@ var DefaultTimeout = 60 * time.Second
 // It captures request details including method, path, status code, duration, and client information.
 // The middleware also sanitises input to prevent log injection attacks.
 func SlogLogger(l *slog.Logger) func(next http.Handler) http.Handler {
+	return SlogLoggerWithOptions(l, LoggerOptions{})
+}
+
+// LoggerOptions tunes SlogLoggerWithOptions. The zero value logs every request at info.
+type LoggerOptions struct {
+	// ExcludePaths lists path prefixes, such as health probes, logged at debug
+	// instead of info. A prefix matches itself and anything below it, so
+	// "/healthz" covers "/healthz/db" but not "/healthzz". Excluded requests
+	// that fail with a 5xx are still logged at info.
+	ExcludePaths []string
+}
+
+// excluded reports whether path falls under one of the ExcludePaths prefixes.
+func (o LoggerOptions) excluded(path string) bool {
+	for _, prefix := range o.ExcludePaths {
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// SlogLoggerWithOptions is SlogLogger with options, e.g. to keep probe traffic
+// out of the info-level access log.
+func SlogLoggerWithOptions(l *slog.Logger, opts LoggerOptions) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
-			ww := &statusWriter{ResponseWriter: w, status: 200}
+			ww := NewResponseObserver(w)
 			next.ServeHTTP(ww, r)
 
 			// Sanitise path to prevent log injection attacks
@@ -33,11 +59,18 @@ func SlogLogger(l *slog.Logger) func(next http.Handler) http.Handler {
 				reqLogger = reqLogger.With("request_id", rid)
 			}
 
+			// Probe noise drops to debug unless the probe is failing
+			level := slog.LevelInfo
+			if ww.Status() < http.StatusInternalServerError && opts.excluded(r.URL.Path) {
+				level = slog.LevelDebug
+			}
+
 			// Log structured request information for monitoring and debugging
-			reqLogger.Info("request",
+			reqLogger.Log(r.Context(), level, "request",
 				"method", r.Method,
 				"path", sanitisedPath,
-				"status", ww.status,
+				"status", ww.Status(),
+				"bytes", ww.BytesWritten(),
 				"duration_ms", time.Since(start).Milliseconds(),
 				"ip", r.RemoteAddr,
 				"user_agent", r.UserAgent(),
@@ -45,17 +78,3 @@ func SlogLogger(l *slog.Logger) func(next http.Handler) http.Handler {
 		})
 	}
 }
-
-// statusWriter wraps the original ResponseWriter to capture the HTTP status code.
-// This is necessary because the status code is not directly accessible from the ResponseWriter interface.
-type statusWriter struct {
-	http.ResponseWriter
-	status int // Captures the HTTP status code for logging purposes
-}
-
-// WriteHeader captures the status code before delegating to the original ResponseWriter.
-// This allows the middleware to log the actual status code returned to the client.
-func (w *statusWriter) WriteHeader(code int) {
-	w.status = code
-	w.ResponseWriter.WriteHeader(code)
-}
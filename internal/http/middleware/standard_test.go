@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSlogLoggerWithOptions_ExcludePaths(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	opts := LoggerOptions{ExcludePaths: []string{"/healthz", "/readyz", "/metrics"}}
+
+	handler := SlogLoggerWithOptions(logger, opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		target string
+		logged bool
+	}{
+		{target: "/guitars", logged: true},
+		{target: "/healthz", logged: false},
+		{target: "/healthz/db", logged: false},
+		{target: "/metrics", logged: false},
+		{target: "/healthzz", logged: true},
+		{target: "/healthz?fail=1", logged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			buf.Reset()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tt.target, nil))
+
+			if got := strings.Contains(buf.String(), "msg=request"); got != tt.logged {
+				t.Errorf("Expected logged at info=%v for %s, got '%s'", tt.logged, tt.target, buf.String())
+			}
+		})
+	}
+
+	t.Run("excluded paths still log at debug", func(t *testing.T) {
+		var debugBuf bytes.Buffer
+		debugLogger := slog.New(slog.NewTextHandler(&debugBuf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		SlogLoggerWithOptions(debugLogger, opts)(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+
+		if !strings.Contains(debugBuf.String(), "level=DEBUG") {
+			t.Errorf("Expected a debug entry, got '%s'", debugBuf.String())
+		}
+	})
+
+	t.Run("SlogLogger logs everything", func(t *testing.T) {
+		buf.Reset()
+		SlogLogger(logger)(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+
+		if !strings.Contains(buf.String(), "path=/healthz") {
+			t.Errorf("Expected /healthz to be logged, got '%s'", buf.String())
+		}
+	})
+}
+
+func TestSlogLogger_StatusAndBytes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	SlogLogger(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte("short and stout"))
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/teapot", nil))
+
+	if out := buf.String(); !strings.Contains(out, "status=418") || !strings.Contains(out, "bytes=15") {
+		t.Errorf("Expected status=418 and bytes=15 in the log, got '%s'", out)
+	}
+}
@@ -3,8 +3,11 @@ package middleware
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"runtime/debug"
 	"sync"
 	"time"
 )
@@ -12,7 +15,8 @@ import (
 // Timeout adds a timeout to HTTP requests.
 // This middleware ensures that requests don't hang indefinitely
 // and provides better error context when timeouts occur.
-func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
+// Handler panics are recovered, logged via logger and answered with a 500.
+func Timeout(logger *slog.Logger, timeout time.Duration) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Create context with timeout and cause
@@ -23,31 +27,14 @@ func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
 			// Update request with new context
 			r = r.WithContext(ctx)
 
-			// Capture downstream response to avoid writes after timeout
-			crw := newCapturingResponseWriter(w)
-			done := make(chan struct{})
-
-			go func() {
-				next.ServeHTTP(crw, r)
-				close(done)
-			}()
-
-			// Prefer timeout when both happen nearly simultaneously
-			select {
-			case <-ctx.Done():
-				http.Error(w, "Request Timeout", http.StatusRequestTimeout)
-				return
-			case <-done:
-				crw.flush()
-				return
-			}
+			serveWithTimeout(logger, w, r, ctx, next)
 		})
 	}
 }
 
 // TimeoutWithCause adds a timeout to HTTP requests with a custom cause.
 // This provides better error context for debugging and monitoring.
-func TimeoutWithCause(timeout time.Duration, cause error) func(http.Handler) http.Handler {
+func TimeoutWithCause(logger *slog.Logger, timeout time.Duration, cause error) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Create context with custom timeout cause
@@ -57,29 +44,14 @@ func TimeoutWithCause(timeout time.Duration, cause error) func(http.Handler) htt
 			// Update request with new context
 			r = r.WithContext(ctx)
 
-			crw := newCapturingResponseWriter(w)
-			done := make(chan struct{})
-
-			go func() {
-				next.ServeHTTP(crw, r)
-				close(done)
-			}()
-
-			select {
-			case <-ctx.Done():
-				http.Error(w, "Request Timeout", http.StatusRequestTimeout)
-				return
-			case <-done:
-				crw.flush()
-				return
-			}
+			serveWithTimeout(logger, w, r, ctx, next)
 		})
 	}
 }
 
 // TimeoutWithDeadline adds a timeout to HTTP requests with an absolute deadline.
 // This is useful when you need to enforce a specific end time.
-func TimeoutWithDeadline(deadline time.Time) func(http.Handler) http.Handler {
+func TimeoutWithDeadline(logger *slog.Logger, deadline time.Time) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Create context with absolute deadline
@@ -89,36 +61,133 @@ func TimeoutWithDeadline(deadline time.Time) func(http.Handler) http.Handler {
 			// Update request with new context
 			r = r.WithContext(ctx)
 
-			crw := newCapturingResponseWriter(w)
-			done := make(chan struct{})
-
-			go func() {
-				next.ServeHTTP(crw, r)
-				close(done)
-			}()
-
-			select {
-			case <-ctx.Done():
-				http.Error(w, "Request Timeout", http.StatusRequestTimeout)
-				return
-			case <-done:
-				crw.flush()
-				return
-			}
+			serveWithTimeout(logger, w, r, ctx, next)
 		})
 	}
 }
 
+// serveWithTimeout runs next for r, which must carry ctx, and answers on w
+// before ctx is done. The handler writes into a buffer, so on timeout it can be
+// replaced with a 408 unless it has already started streaming; a handler that
+// finishes late is reported. A recovered panic is answered with a 500 and
+// http.ErrAbortHandler is re-raised here, on the serving goroutine.
+func serveWithTimeout(logger *slog.Logger, w http.ResponseWriter, r *http.Request, ctx context.Context, next http.Handler) {
+	// Capture downstream response to avoid writes after timeout
+	crw := newCapturingResponseWriter(w)
+	done := serveAsync(logger, next, crw, r)
+
+	// Prefer timeout when both happen nearly simultaneously
+	select {
+	case <-ctx.Done():
+		// A response that already started streaming cannot be replaced
+		if !crw.abandon() {
+			respondError(w, r, http.StatusRequestTimeout, "Request Timeout")
+		}
+		reportLateCompletion(logger, r, done)
+	case err := <-done:
+		if err == http.ErrAbortHandler {
+			// Abort on the serving goroutine, where net/http expects it
+			panic(err)
+		}
+		if err != nil {
+			// Discard any partial output captured before the panic
+			if !crw.abandon() {
+				respondError(w, r, http.StatusInternalServerError, "Internal Server Error")
+			}
+			return
+		}
+		crw.flush()
+	}
+}
+
+// errHandlerPanicked reports a panic recovered in the handler goroutine.
+var errHandlerPanicked = errors.New("handler panicked")
+
+// serveAsync runs next in its own goroutine. The returned channel yields
+// errHandlerPanicked or http.ErrAbortHandler if the handler panicked, and is closed
+// when it returns. Panics must be recovered here: Recoverer sits outside the
+// timeout middleware and cannot see a panic on another goroutine, so an unrecovered
+// one would crash the process. The panic is logged here too, so one that happens
+// after the timeout fired (when nobody reads the channel) is not lost, and noted
+// for an ErrorLog observing the request.
+func serveAsync(logger *slog.Logger, next http.Handler, w http.ResponseWriter, r *http.Request) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				if err == http.ErrAbortHandler {
+					done <- http.ErrAbortHandler
+				} else {
+					if logger != nil {
+						logger.Error("panic recovered in timeout handler",
+							"error", err,
+							"method", r.Method,
+							"path", r.URL.Path,
+							"nil_panic", isNilPanic(err),
+							"stack", string(debug.Stack()),
+						)
+					}
+					// Recoverer never sees this panic, so let an observing ErrorLog
+					// show it; the done send orders the note before the record is made
+					notePanic(r.Context(), err)
+					done <- errHandlerPanicked
+				}
+			}
+			close(done)
+		}()
+		next.ServeHTTP(w, r)
+	}()
+	return done
+}
+
+// reportLateCompletion waits in the background for a handler the timeout has
+// already answered for, and logs a warning with the overrun once it returns.
+// Go cannot preempt a handler that ignores its cancelled context, so this is
+// how uncooperative handlers are found. A handler that never returns is never
+// reported; one that panics late is logged by serveAsync instead.
+func reportLateCompletion(logger *slog.Logger, r *http.Request, done <-chan error) {
+	if logger == nil {
+		return
+	}
+	timedOutAt := time.Now()
+	go func() {
+		if err := <-done; err != nil {
+			return
+		}
+		reqLogger := logger
+		if rid, ok := RequestIDFromContext(r.Context()); ok {
+			reqLogger = reqLogger.With("request_id", rid)
+		}
+		reqLogger.Warn("handler finished after timeout",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"overrun_ms", time.Since(timedOutAt).Milliseconds(),
+		)
+	}()
+}
+
 // capturingResponseWriter buffers downstream writes until we decide to emit.
+// A handler that calls Flush opts into streaming: the buffered response is
+// committed and later writes go straight to the destination, so the timeout
+// can no longer replace it.
 type capturingResponseWriter struct {
 	dst         http.ResponseWriter
 	header      http.Header
 	statusCode  int
 	wroteHeader bool
 	buf         bytes.Buffer
+	flushed     bool
+	abandoned   bool // Set once the middleware has answered; later writes fail
 	mu          sync.Mutex
 }
 
+// multiValueHeaders may legitimately repeat, so flush appends to any values
+// already on the destination; every other header replaces them.
+var multiValueHeaders = map[string]bool{
+	"Set-Cookie": true,
+	"Vary":       true,
+}
+
 func newCapturingResponseWriter(w http.ResponseWriter) *capturingResponseWriter {
 	return &capturingResponseWriter{
 		dst:    w,
@@ -142,14 +211,61 @@ func (c *capturingResponseWriter) Write(b []byte) (int, error) {
 	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if c.abandoned {
+		return 0, http.ErrHandlerTimeout
+	}
+	if c.flushed {
+		return c.dst.Write(b)
+	}
 	return c.buf.Write(b)
 }
 
+// Flush commits the buffered response and flushes the destination, switching
+// the writer to streaming. It does nothing once the middleware has answered.
+func (c *capturingResponseWriter) Flush() {
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.abandoned {
+		return
+	}
+	c.commit()
+	_ = http.NewResponseController(c.dst).Flush()
+}
+
+// abandon stops the handler's writes from reaching the destination and reports
+// whether a response was already committed by Flush.
+func (c *capturingResponseWriter) abandon() (streamed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.abandoned = true
+	return c.flushed
+}
+
 func (c *capturingResponseWriter) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.commit()
+}
+
+// commit writes the captured status, headers and body to the destination once.
+// The caller must hold c.mu.
+func (c *capturingResponseWriter) commit() {
+	if c.flushed {
+		return
+	}
+	c.flushed = true
+
 	// Copy headers
+	dst := c.dst.Header()
 	for k, vs := range c.header {
-		for _, v := range vs {
-			c.dst.Header().Add(k, v)
+		k = http.CanonicalHeaderKey(k)
+		if multiValueHeaders[k] {
+			dst[k] = append(dst[k], vs...)
+		} else {
+			dst[k] = append([]string(nil), vs...)
 		}
 	}
 	if c.statusCode == 0 {
@@ -1,9 +1,14 @@
 package middleware
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -16,7 +21,7 @@ func TestTimeout(t *testing.T) {
 			w.Write([]byte("OK"))
 		})
 
-		middleware := Timeout(100 * time.Millisecond)(fastHandler)
+		middleware := Timeout(nil, 100*time.Millisecond)(fastHandler)
 
 		req := httptest.NewRequest("GET", "/test", nil)
 		w := httptest.NewRecorder()
@@ -41,7 +46,7 @@ func TestTimeout(t *testing.T) {
 			w.Write([]byte("OK"))
 		})
 
-		middleware := Timeout(50 * time.Millisecond)(slowHandler)
+		middleware := Timeout(nil, 50*time.Millisecond)(slowHandler)
 
 		req := httptest.NewRequest("GET", "/test", nil)
 		w := httptest.NewRecorder()
@@ -67,7 +72,7 @@ func TestTimeout(t *testing.T) {
 			w.Write([]byte("OK"))
 		})
 
-		middleware := Timeout(10 * time.Millisecond)(contextHandler)
+		middleware := Timeout(nil, 10*time.Millisecond)(contextHandler)
 
 		req := httptest.NewRequest("GET", "/test", nil)
 		w := httptest.NewRecorder()
@@ -95,7 +100,7 @@ func TestTimeout(t *testing.T) {
 			w.Write([]byte("Context OK"))
 		})
 
-		middleware := Timeout(100 * time.Millisecond)(contextCheckHandler)
+		middleware := Timeout(nil, 100*time.Millisecond)(contextCheckHandler)
 
 		req := httptest.NewRequest("GET", "/test", nil)
 		w := httptest.NewRecorder()
@@ -130,7 +135,7 @@ func TestTimeout(t *testing.T) {
 				})
 
 				// Set timeout slightly shorter to trigger timeout
-				middleware := Timeout(timeout - 10*time.Millisecond)(exactHandler)
+				middleware := Timeout(nil, timeout-10*time.Millisecond)(exactHandler)
 
 				req := httptest.NewRequest("GET", "/test", nil)
 				w := httptest.NewRecorder()
@@ -152,7 +157,7 @@ func TestTimeout(t *testing.T) {
 			w.Write([]byte("OK"))
 		})
 
-		middleware := Timeout(0)(fastHandler)
+		middleware := Timeout(nil, 0)(fastHandler)
 
 		req := httptest.NewRequest("GET", "/test", nil)
 		w := httptest.NewRecorder()
@@ -173,7 +178,7 @@ func TestTimeout(t *testing.T) {
 			w.Write([]byte("OK"))
 		})
 
-		middleware := Timeout(5 * time.Millisecond)(veryFastHandler)
+		middleware := Timeout(nil, 5*time.Millisecond)(veryFastHandler)
 
 		req := httptest.NewRequest("GET", "/test", nil)
 		w := httptest.NewRecorder()
@@ -202,7 +207,7 @@ func TestTimeout(t *testing.T) {
 					w.Write([]byte("OK"))
 				})
 
-				middleware := Timeout(50 * time.Millisecond)(slowHandler)
+				middleware := Timeout(nil, 50*time.Millisecond)(slowHandler)
 
 				req := httptest.NewRequest(method, "/test", nil)
 				w := httptest.NewRecorder()
@@ -217,3 +222,317 @@ func TestTimeout(t *testing.T) {
 		}
 	})
 }
+
+func TestTimeout_HandlerPanicUnderFullStack(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom in handler")
+	})
+
+	// Same order as app.New: Recoverer sits outside the timeout goroutine
+	handler := RequestID(
+		Recoverer(logger)(
+			SlogLogger(logger)(
+				TimeoutWithCause(logger, time.Second, errors.New("request timeout"))(
+					SecurityHeaders(panicking),
+				),
+			),
+		),
+	)
+
+	req := httptest.NewRequest("GET", "/guitar/boom", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+	if !strings.Contains(buf.String(), "boom in handler") {
+		t.Errorf("Expected panic value to be logged, got: %s", buf.String())
+	}
+	// The panicking closure only appears in the stack captured on the handler goroutine
+	if !strings.Contains(buf.String(), "TestTimeout_HandlerPanicUnderFullStack.func1") {
+		t.Errorf("Expected handler stack to be logged, got: %s", buf.String())
+	}
+}
+
+func TestTimeoutVariants_RecoverPanics(t *testing.T) {
+	variants := map[string]func(*slog.Logger) func(http.Handler) http.Handler{
+		"Timeout": func(l *slog.Logger) func(http.Handler) http.Handler {
+			return Timeout(l, time.Second)
+		},
+		"TimeoutWithCause": func(l *slog.Logger) func(http.Handler) http.Handler {
+			return TimeoutWithCause(l, time.Second, errors.New("request timeout"))
+		},
+		"TimeoutWithDeadline": func(l *slog.Logger) func(http.Handler) http.Handler {
+			return TimeoutWithDeadline(l, time.Now().Add(time.Second))
+		},
+	}
+
+	for name, variant := range variants {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+			panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Partial", "yes")
+				_, _ = w.Write([]byte("partial"))
+				panic("boom")
+			})
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			w := httptest.NewRecorder()
+			variant(logger)(panicking).ServeHTTP(w, req)
+
+			if w.Code != http.StatusInternalServerError {
+				t.Errorf("Expected status 500, got %d", w.Code)
+			}
+			if strings.Contains(w.Body.String(), "partial") || w.Header().Get("X-Partial") != "" {
+				t.Errorf("Expected partial output to be discarded, got body '%s'", w.Body.String())
+			}
+			if !strings.Contains(buf.String(), "panic recovered in timeout handler") || !strings.Contains(buf.String(), "boom") {
+				t.Errorf("Expected panic to be logged, got: %s", buf.String())
+			}
+		})
+	}
+}
+
+func TestTimeout_PanicAfterTimeoutIsLogged(t *testing.T) {
+	var mu sync.Mutex
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&lockedWriter{mu: &mu, w: &buf}, nil))
+
+	finished := make(chan struct{})
+	late := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(finished)
+		<-r.Context().Done()
+		panic("late boom")
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	w := httptest.NewRecorder()
+	Timeout(logger, 10*time.Millisecond)(late).ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestTimeout {
+		t.Errorf("Expected status 408, got %d", w.Code)
+	}
+
+	<-finished
+	// The deferred log happens after close(finished); wait briefly for it
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		logged := strings.Contains(buf.String(), "late boom")
+		mu.Unlock()
+		if logged {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected late panic to be logged")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// lockedWriter serialises writes so the log buffer can be read while a goroutine logs.
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  *bytes.Buffer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+func TestTimeout_AbortHandlerPropagates(t *testing.T) {
+	aborting := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	w := httptest.NewRecorder()
+
+	var recovered any
+	func() {
+		defer func() { recovered = recover() }()
+		Timeout(nil, time.Second)(aborting).ServeHTTP(w, req)
+	}()
+
+	if recovered != http.ErrAbortHandler {
+		t.Errorf("Expected http.ErrAbortHandler on the serving goroutine, got %v", recovered)
+	}
+}
+
+func TestTimeout_HeaderCopy(t *testing.T) {
+	t.Run("keeps every Set-Cookie exactly once", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.SetCookie(w, &http.Cookie{Name: "a", Value: "1"})
+			http.SetCookie(w, &http.Cookie{Name: "b", Value: "2"})
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+		Timeout(nil, time.Second)(handler).ServeHTTP(w, req)
+
+		cookies := w.Header().Values("Set-Cookie")
+		if len(cookies) != 2 || cookies[0] != "a=1" || cookies[1] != "b=2" {
+			t.Errorf("Expected cookies [a=1 b=2], got %v", cookies)
+		}
+	})
+
+	t.Run("single-valued headers replace existing values", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rec.Header().Set("Content-Type", "text/plain")
+		rec.Header().Set("Vary", "Origin")
+
+		crw := newCapturingResponseWriter(rec)
+		crw.Header().Set("Content-Type", "text/html")
+		crw.Header().Add("Vary", "Accept-Encoding")
+		crw.Write([]byte("body"))
+		crw.flush()
+
+		if got := rec.Header().Values("Content-Type"); len(got) != 1 || got[0] != "text/html" {
+			t.Errorf("Expected Content-Type [text/html], got %v", got)
+		}
+		if got := rec.Header().Values("Vary"); len(got) != 2 {
+			t.Errorf("Expected Vary values to accumulate, got %v", got)
+		}
+	})
+
+	t.Run("flush is idempotent", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		crw := newCapturingResponseWriter(rec)
+		http.SetCookie(crw, &http.Cookie{Name: "a", Value: "1"})
+		crw.Write([]byte("body"))
+
+		crw.flush()
+		crw.flush()
+
+		if got := rec.Header().Values("Set-Cookie"); len(got) != 1 {
+			t.Errorf("Expected one Set-Cookie after double flush, got %v", got)
+		}
+		if rec.Body.String() != "body" {
+			t.Errorf("Expected body written once, got '%s'", rec.Body.String())
+		}
+	})
+}
+
+func TestTimeout_FlushStreams(t *testing.T) {
+	t.Run("flushed output survives the timeout", func(t *testing.T) {
+		timedOut := make(chan struct{})
+		lateErr := make(chan error, 1)
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			_, _ = w.Write([]byte("{\"n\":1}\n"))
+			if err := http.NewResponseController(w).Flush(); err != nil {
+				t.Errorf("Expected Flush to be supported, got %v", err)
+			}
+			<-timedOut
+			_, err := w.Write([]byte("{\"n\":2}\n"))
+			lateErr <- err
+		})
+
+		req := httptest.NewRequest("GET", "/export", nil)
+		w := httptest.NewRecorder()
+		Timeout(nil, 50*time.Millisecond)(handler).ServeHTTP(w, req)
+		close(timedOut)
+
+		if err := <-lateErr; err == nil {
+			t.Error("Expected writes after the timeout to fail")
+		}
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected the streamed status 200, got %d", w.Code)
+		}
+		if !w.Flushed {
+			t.Error("Expected the destination to be flushed")
+		}
+		if got := w.Body.String(); got != "{\"n\":1}\n" {
+			t.Errorf("Expected only the flushed line, got '%s'", got)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+			t.Errorf("Expected Content-Type to be committed, got '%s'", ct)
+		}
+	})
+
+	t.Run("flush passes through logging and timing wrappers", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("a"))
+			if err := http.NewResponseController(w).Flush(); err != nil {
+				t.Errorf("Expected Flush to be supported, got %v", err)
+			}
+			_, _ = w.Write([]byte("b"))
+		})
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		wrapped := SlogLogger(logger)(Timeout(nil, time.Second)(ServerTimingHeader(handler)))
+
+		req := httptest.NewRequest("GET", "/export", nil)
+		w := httptest.NewRecorder()
+		wrapped.ServeHTTP(w, req)
+
+		if !w.Flushed {
+			t.Error("Expected the flush to reach the underlying writer")
+		}
+		if w.Body.String() != "ab" {
+			t.Errorf("Expected body 'ab', got '%s'", w.Body.String())
+		}
+		if w.Header().Get("Server-Timing") == "" {
+			t.Error("Expected Server-Timing on a streamed response")
+		}
+	})
+}
+
+func TestTimeout_LateCompletionIsLogged(t *testing.T) {
+	var mu sync.Mutex
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&lockedWriter{mu: &mu, w: &buf}, nil))
+
+	finished := make(chan struct{})
+	uncooperative := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(finished)
+		// Ignores its context, like a tight CPU loop would
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/slow", nil)
+	w := httptest.NewRecorder()
+	start := time.Now()
+	Timeout(logger, 20*time.Millisecond)(uncooperative).ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestTimeout {
+		t.Errorf("Expected status 408, got %d", w.Code)
+	}
+	if elapsed := time.Since(start); elapsed >= 100*time.Millisecond {
+		t.Errorf("Expected the client to be answered at the deadline, took %v", elapsed)
+	}
+
+	mu.Lock()
+	early := buf.String()
+	mu.Unlock()
+	if strings.Contains(early, "handler finished after timeout") {
+		t.Error("Expected no late-completion log before the handler returns")
+	}
+
+	<-finished
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		out := buf.String()
+		mu.Unlock()
+		if strings.Contains(out, "handler finished after timeout") {
+			if !strings.Contains(out, "level=WARN") || !strings.Contains(out, "path=/slow") || !strings.Contains(out, "overrun_ms=") {
+				t.Errorf("Expected a warning with path and overrun, got '%s'", out)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the late completion to be logged, got '%s'", out)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTimingAllowOrigin(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name    string
+		origins []string
+		want    string
+	}{
+		{name: "single origin", origins: []string{"https://guitar-specs.example.com"}, want: "https://guitar-specs.example.com"},
+		{name: "several origins", origins: []string{"https://a.example.com", "https://b.example.com"}, want: "https://a.example.com, https://b.example.com"},
+		{name: "any origin", origins: []string{"*"}, want: "*"},
+		{name: "disabled", origins: nil, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			TimingAllowOrigin(tt.origins)(ok).ServeHTTP(w, httptest.NewRequest("GET", "/static/css/main.css", nil))
+
+			if got := w.Header().Get("Timing-Allow-Origin"); got != tt.want {
+				t.Errorf("Expected Timing-Allow-Origin '%s', got '%s'", tt.want, got)
+			}
+		})
+	}
+}
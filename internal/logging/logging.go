@@ -0,0 +1,114 @@
+// Package logging builds the runtime logger: human-readable text on stdout,
+// optionally teed to a JSON log file with its own level.
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Levels lists the level names ParseLevel recognises, most verbose first.
+var Levels = []string{"debug", "info", "warn", "error"}
+
+// KnownLevel reports whether ParseLevel recognises level, ignoring case and
+// surrounding space.
+func KnownLevel(level string) bool {
+	return slices.Contains(Levels, strings.ToLower(strings.TrimSpace(level)))
+}
+
+// ParseLevel maps "debug", "info", "warn" and "error" to a slog level.
+// Anything else falls back to info.
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "info":
+		return slog.LevelInfo
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// Options configures New.
+type Options struct {
+	Stdout    io.Writer  // Text output (default: os.Stdout)
+	Level     slog.Level // Minimum level written to Stdout
+	FilePath  string     // JSON log file, appended to; empty disables it
+	FileLevel slog.Level // Minimum level written to the file
+}
+
+// New returns a logger writing text to Stdout at Level and, when FilePath is
+// set, JSON to that file at FileLevel. The returned close function releases
+// the file and is always safe to call. If the file cannot be opened the logger
+// still writes to Stdout and the error is returned for the caller to report.
+func New(opts Options) (*slog.Logger, func() error, error) {
+	if opts.Stdout == nil {
+		opts.Stdout = os.Stdout
+	}
+	text := slog.NewTextHandler(opts.Stdout, &slog.HandlerOptions{Level: opts.Level})
+	noop := func() error { return nil }
+
+	if opts.FilePath == "" {
+		return slog.New(text), noop, nil
+	}
+
+	f, err := os.OpenFile(opts.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return slog.New(text), noop, fmt.Errorf("failed to open log file, logging to stdout only: %w", err)
+	}
+	file := slog.NewJSONHandler(f, &slog.HandlerOptions{Level: opts.FileLevel})
+
+	return slog.New(fanoutHandler{text, file}), f.Close, nil
+}
+
+// fanoutHandler sends each record to every handler enabled for its level, so
+// each destination keeps its own level and format.
+type fanoutHandler []slog.Handler
+
+func (h fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h fanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, handler := range h {
+		if handler.Enabled(ctx, r.Level) {
+			// Each handler gets its own copy; records must not be shared after Handle
+			if err := handler.Handle(ctx, r.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		out[i] = handler.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (h fanoutHandler) WithGroup(name string) slog.Handler {
+	out := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		out[i] = handler.WithGroup(name)
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"debug":   slog.LevelDebug,
+		"info":    slog.LevelInfo,
+		"WARN":    slog.LevelWarn,
+		"error":   slog.LevelError,
+		"verbose": slog.LevelInfo,
+	}
+	for input, want := range tests {
+		if got := ParseLevel(input); got != want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestKnownLevel(t *testing.T) {
+	for _, level := range append(Levels, "WARN", " debug ") {
+		if !KnownLevel(level) {
+			t.Errorf("KnownLevel(%q) = false, want true", level)
+		}
+	}
+	for _, level := range []string{"", "debugg", "verbose", "warning"} {
+		if KnownLevel(level) {
+			t.Errorf("KnownLevel(%q) = true, want false", level)
+		}
+	}
+}
+
+func TestNew_StdoutAndFile(t *testing.T) {
+	var stdout bytes.Buffer
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	logger, closeFile, err := New(Options{
+		Stdout:    &stdout,
+		Level:     slog.LevelWarn,
+		FilePath:  path,
+		FileLevel: slog.LevelDebug,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	logger.With("component", "test").Debug("debug detail", "n", 1)
+	logger.Warn("slow query", "ms", 250)
+	if err := closeFile(); err != nil {
+		t.Fatalf("Failed to close log file: %v", err)
+	}
+
+	// Text on stdout only at warn and above
+	if strings.Contains(stdout.String(), "debug detail") {
+		t.Errorf("Expected debug record to stay out of stdout, got: %s", stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "level=WARN msg=\"slow query\" ms=250") {
+		t.Errorf("Expected warn record as text on stdout, got: %s", stdout.String())
+	}
+
+	// JSON in the file from debug up, with attributes preserved
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 records in the file, got %d: %s", len(lines), data)
+	}
+	var first map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("Expected JSON record, got %q", lines[0])
+	}
+	if first["msg"] != "debug detail" || first["level"] != "DEBUG" || first["component"] != "test" {
+		t.Errorf("Unexpected debug record: %v", first)
+	}
+	if !strings.Contains(lines[1], `"msg":"slow query"`) {
+		t.Errorf("Expected warn record in the file, got %q", lines[1])
+	}
+}
+
+func TestNew_FileOpenError(t *testing.T) {
+	var stdout bytes.Buffer
+	path := filepath.Join(t.TempDir(), "missing", "dir", "app.log")
+
+	logger, closeFile, err := New(Options{Stdout: &stdout, Level: slog.LevelInfo, FilePath: path})
+	if err == nil {
+		t.Fatal("Expected an error for an unwritable log file")
+	}
+	if logger == nil || closeFile == nil {
+		t.Fatal("Expected a usable stdout logger despite the error")
+	}
+
+	logger.Info("still logging")
+	if !strings.Contains(stdout.String(), "still logging") {
+		t.Errorf("Expected fallback to stdout, got: %s", stdout.String())
+	}
+	if err := closeFile(); err != nil {
+		t.Errorf("Expected no-op close, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"guitar-specs/internal/timing"
+)
+
+// Brand mirrors public.brands. Optional columns are nil when unset.
+type Brand struct {
+	Slug         string
+	Name         string
+	About        *string
+	WebsiteURL   *string
+	WikipediaURL *string
+	LogoURL      *string
+	CountryCode  *string
+	FoundedYear  *int
+	Headquarters *string
+}
+
+// GetBrandWithGuitars returns a brand and its guitars ordered by model. The brand is
+// looked up first so an unknown slug returns ErrNotFound rather than an empty list;
+// a brand without guitars returns an empty slice.
+func (s GuitarStore) GetBrandWithGuitars(ctx context.Context, brandSlug string) (*Brand, []Guitar, error) {
+	if s.DB == nil {
+		return nil, nil, errors.New("nil DB")
+	}
+
+	b, err := s.getBrand(ctx, brandSlug)
+	if err != nil {
+		return nil, nil, err
+	}
+	guitars, err := s.listByBrand(ctx, brandSlug)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, guitars, nil
+}
+
+// getBrand returns a single brand by slug, or ErrNotFound.
+func (s GuitarStore) getBrand(ctx context.Context, slug string) (*Brand, error) {
+	ctx, cancel := withQueryTimeout(ctx, s.Timeouts.Get)
+	defer cancel()
+	defer timing.Track(ctx, "db")()
+
+	const q = `
+		select
+			b.slug::text,
+			b.name,
+			b.about,
+			b.website_url,
+			b.wikipedia_url,
+			b.logo_url,
+			b.country_code,
+			b.founded_year,
+			b.headquarters
+		from public.brands b
+		where b.slug = $1
+	`
+	var b Brand
+	if err := s.DB.QueryRow(ctx, q, slug).Scan(
+		&b.Slug, &b.Name, &b.About, &b.WebsiteURL, &b.WikipediaURL,
+		&b.LogoURL, &b.CountryCode, &b.FoundedYear, &b.Headquarters,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &b, nil
+}
+
+// listByBrand returns the guitars of one brand ordered by model.
+func (s GuitarStore) listByBrand(ctx context.Context, brandSlug string) ([]Guitar, error) {
+	ctx, cancel := withQueryTimeout(ctx, s.Timeouts.List)
+	defer cancel()
+	defer timing.Track(ctx, "db")()
+
+	const q = `
+		select 
+			g.id::text,
+			g.slug::text,
+			g.type::text,
+			g.model,
+			b.slug::text as brand_slug,
+			b.name        as brand_name,
+			s.slug::text  as shape_slug,
+			s.name        as shape_name
+		from public.guitars g
+		join public.brands b on b.slug = g.brand_slug
+		join public.shapes s on s.slug = g.shape_slug
+		where b.slug = $1
+		order by g.model
+	`
+	rows, err := s.DB.Query(ctx, q, brandSlug)
+	if err != nil {
+		return nil, err
+	}
+	return scanGuitars(rows)
+}
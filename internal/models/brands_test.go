@@ -0,0 +1,57 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestGuitarStore_GetBrandWithGuitars(t *testing.T) {
+	pool := testPool(t)
+	store := GuitarStore{DB: pool}
+	ctx := context.Background()
+
+	t.Run("unknown brand", func(t *testing.T) {
+		b, guitars, err := store.GetBrandWithGuitars(ctx, "no-such-brand")
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+		if b != nil || guitars != nil {
+			t.Errorf("Expected no results, got %+v and %d guitars", b, len(guitars))
+		}
+	})
+
+	t.Run("known brand", func(t *testing.T) {
+		all, err := store.List(ctx)
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if len(all) == 0 {
+			t.Skip("no guitars in database")
+		}
+		brandSlug := all[0].BrandSlug
+
+		b, guitars, err := store.GetBrandWithGuitars(ctx, brandSlug)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if b.Slug != brandSlug || b.Name != all[0].BrandName {
+			t.Errorf("Expected brand %s (%s), got %+v", brandSlug, all[0].BrandName, b)
+		}
+
+		want := 0
+		for _, g := range all {
+			if g.BrandSlug == brandSlug {
+				want++
+			}
+		}
+		if len(guitars) != want {
+			t.Errorf("Expected %d guitars for %s, got %d", want, brandSlug, len(guitars))
+		}
+		for _, g := range guitars {
+			if g.BrandSlug != brandSlug {
+				t.Errorf("Expected only %s guitars, got %+v", brandSlug, g)
+			}
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/sync/singleflight"
+)
+
+// GetDetailBySlug returns a guitar with its resolved features. Concurrent lookups of
+// the same slug share a single pair of queries, so a burst of traffic to one page
+// costs one DB round-trip. It returns ErrNotFound when no guitar has the slug.
+func (s GuitarStore) GetDetailBySlug(ctx context.Context, slug string) (*Guitar, error) {
+	load := func(ctx context.Context) (*Guitar, error) {
+		g, err := s.GetBySlug(ctx, slug)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrNotFound
+			}
+			return nil, err
+		}
+		feats, err := s.ListFeaturesBySlug(ctx, slug)
+		if err != nil {
+			return nil, err
+		}
+		g.Features = feats
+		return g, nil
+	}
+
+	if s.flight == nil {
+		return load(ctx)
+	}
+	return s.flight.Do(ctx, slug, load)
+}
+
+// detailFlight deduplicates concurrent guitar detail loads by key.
+// Nothing is cached: once a load finishes, errors included, the next call runs again.
+type detailFlight struct {
+	group singleflight.Group
+}
+
+// Do runs load once for all concurrent callers sharing key. The shared load is detached
+// from any one caller's cancellation (the store's query timeouts still bound it), and
+// each caller stops waiting when its own context is done. Callers get their own copy.
+// A panic in load is returned to every caller as an error: DoChan would re-panic it
+// on a fresh goroutine, where no recoverer can catch it.
+func (f *detailFlight) Do(ctx context.Context, key string, load func(context.Context) (*Guitar, error)) (*Guitar, error) {
+	ch := f.group.DoChan(key, func() (v any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("guitar detail load panicked: %v\n%s", p, debug.Stack())
+			}
+		}()
+		return load(context.WithoutCancel(ctx))
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		g := *res.Val.(*Guitar)
+		g.Features = append([]GuitarFeatureResolved(nil), g.Features...)
+		return &g, nil
+	}
+}
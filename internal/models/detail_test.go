@@ -0,0 +1,125 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDetailFlight_SharesConcurrentLoads(t *testing.T) {
+	var f detailFlight
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	load := func(ctx context.Context) (*Guitar, error) {
+		calls.Add(1)
+		<-release
+		return &Guitar{Slug: "stratocaster", Features: []GuitarFeatureResolved{{FeatureKey: "pickups"}}}, nil
+	}
+
+	const n = 20
+	var wg sync.WaitGroup
+	results := make([]*Guitar, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			g, err := f.Do(context.Background(), "stratocaster", load)
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+				return
+			}
+			results[i] = g
+		}(i)
+	}
+
+	// Give every goroutine time to join the in-flight load before it completes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Expected 1 underlying load, got %d", got)
+	}
+	if results[0] == results[1] {
+		t.Error("Expected each caller to get its own copy")
+	}
+}
+
+func TestDetailFlight_DoesNotCacheErrors(t *testing.T) {
+	var f detailFlight
+	var calls atomic.Int32
+	failure := errors.New("db down")
+
+	load := func(ctx context.Context) (*Guitar, error) {
+		if calls.Add(1) == 1 {
+			return nil, failure
+		}
+		return &Guitar{Slug: "stratocaster"}, nil
+	}
+
+	if _, err := f.Do(context.Background(), "stratocaster", load); !errors.Is(err, failure) {
+		t.Fatalf("Expected first call to fail, got %v", err)
+	}
+	g, err := f.Do(context.Background(), "stratocaster", load)
+	if err != nil {
+		t.Fatalf("Expected second call to succeed, got %v", err)
+	}
+	if g.Slug != "stratocaster" || calls.Load() != 2 {
+		t.Errorf("Expected a fresh load after the error, got %d calls", calls.Load())
+	}
+}
+
+func TestDetailFlight_CallerCancellation(t *testing.T) {
+	var f detailFlight
+	release := make(chan struct{})
+	defer close(release)
+
+	load := func(ctx context.Context) (*Guitar, error) {
+		<-release
+		return &Guitar{}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := f.Do(ctx, "stratocaster", load); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestDetailFlight_LoadPanics(t *testing.T) {
+	var f detailFlight
+	release := make(chan struct{})
+
+	load := func(ctx context.Context) (*Guitar, error) {
+		<-release
+		panic("boom")
+	}
+
+	const n = 5
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = f.Do(context.Background(), "stratocaster", load)
+		}(i)
+	}
+
+	// Let the callers join the load so the panic has waiters
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err == nil || !strings.Contains(err.Error(), "boom") {
+			t.Errorf("Expected caller %d to get the panic as an error, got %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"guitar-specs/internal/timing"
+)
+
+// GuitarFilter narrows the guitar catalogue. Empty fields don't filter.
+type GuitarFilter struct {
+	BrandSlug    string
+	ShapeSlug    string
+	Type         string
+	FeatureKey   string // Used together with FeatureValue
+	FeatureValue string
+}
+
+// FacetCount is the number of matching guitars for a single facet value.
+type FacetCount struct {
+	Value string // Slug or enum value used for filtering
+	Label string // Human-readable name
+	Count int
+}
+
+// Facets groups counts of guitars matching a filter by brand, shape and type.
+type Facets struct {
+	Brands []FacetCount
+	Shapes []FacetCount
+	Types  []FacetCount
+}
+
+// FacetCounts returns, for guitars matching filter, the counts grouped by brand, shape and type.
+// All three groupings are computed in one round-trip over a shared filtered CTE.
+func (s GuitarStore) FacetCounts(ctx context.Context, filter GuitarFilter) (Facets, error) {
+	if s.DB == nil {
+		return Facets{}, errors.New("nil DB")
+	}
+	ctx, cancel := withQueryTimeout(ctx, s.Timeouts.List)
+	defer cancel()
+	defer timing.Track(ctx, "db")()
+
+	where, args := filter.whereClause()
+	q := `
+		with filtered as (
+			select g.brand_slug, g.shape_slug, g.type
+			from public.guitars g
+			` + where + `
+		)
+		select 'brand' as facet, b.slug::text, b.name, count(*)
+		from filtered f join public.brands b on b.slug = f.brand_slug
+		group by b.slug, b.name
+		union all
+		select 'shape', s.slug::text, s.name, count(*)
+		from filtered f join public.shapes s on s.slug = f.shape_slug
+		group by s.slug, s.name
+		union all
+		select 'type', f.type::text, f.type::text, count(*)
+		from filtered f
+		group by f.type
+		order by 1, 3
+	`
+	rows, err := s.DB.Query(ctx, q, args...)
+	if err != nil {
+		return Facets{}, err
+	}
+	defer rows.Close()
+
+	var facets Facets
+	for rows.Next() {
+		var facet string
+		var fc FacetCount
+		if err := rows.Scan(&facet, &fc.Value, &fc.Label, &fc.Count); err != nil {
+			return Facets{}, err
+		}
+		switch facet {
+		case "brand":
+			facets.Brands = append(facets.Brands, fc)
+		case "shape":
+			facets.Shapes = append(facets.Shapes, fc)
+		case "type":
+			facets.Types = append(facets.Types, fc)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return Facets{}, err
+	}
+	return facets, nil
+}
+
+// whereClause renders the filter as a SQL WHERE clause over alias g with positional args.
+// It returns an empty clause when the filter is empty.
+func (f GuitarFilter) whereClause() (string, []any) {
+	var conds []string
+	var args []any
+	add := func(cond string, vals ...any) {
+		// Rewrite ? placeholders to $n in argument order
+		for _, v := range vals {
+			args = append(args, v)
+			cond = strings.Replace(cond, "?", fmt.Sprintf("$%d", len(args)), 1)
+		}
+		conds = append(conds, cond)
+	}
+
+	if f.BrandSlug != "" {
+		add("g.brand_slug = ?", f.BrandSlug)
+	}
+	if f.ShapeSlug != "" {
+		add("g.shape_slug = ?", f.ShapeSlug)
+	}
+	if f.Type != "" {
+		add("g.type::text = ?", f.Type)
+	}
+	if f.FeatureKey != "" && f.FeatureValue != "" {
+		num, boolean := featureValueArgs(f.FeatureValue)
+		add(`exists (
+				select 1
+				from public.guitar_features gf
+				join public.features ft on ft.id = gf.feature_id
+				left join public.feature_allowed_values fav on fav.id = gf.allowed_value_id
+				where gf.guitar_id = g.id
+				  and ft.key = ?
+				  and (
+				       (ft.kind = 'enum'    and fav.value = ?)
+				    or (ft.kind = 'text'    and gf.value_text = ?)
+				    or (ft.kind = 'number'  and gf.value_number = ?::numeric)
+				    or (ft.kind = 'boolean' and gf.value_boolean = ?::boolean)
+				  )
+			)`, f.FeatureKey, f.FeatureValue, f.FeatureValue, num, boolean)
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return "where " + strings.Join(conds, " and "), args
+}
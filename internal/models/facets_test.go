@@ -0,0 +1,88 @@
+package models
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGuitarFilter_WhereClause(t *testing.T) {
+	t.Run("empty filter", func(t *testing.T) {
+		where, args := GuitarFilter{}.whereClause()
+		if where != "" || len(args) != 0 {
+			t.Errorf("Expected empty clause, got %q with %d args", where, len(args))
+		}
+	})
+
+	t.Run("brand and type", func(t *testing.T) {
+		where, args := GuitarFilter{BrandSlug: "fender", Type: "electric"}.whereClause()
+		if where != "where g.brand_slug = $1 and g.type::text = $2" {
+			t.Errorf("Unexpected clause %q", where)
+		}
+		if len(args) != 2 || args[0] != "fender" || args[1] != "electric" {
+			t.Errorf("Unexpected args %v", args)
+		}
+	})
+
+	t.Run("feature placeholders are numbered after earlier conditions", func(t *testing.T) {
+		where, args := GuitarFilter{ShapeSlug: "stratocaster", FeatureKey: "frets", FeatureValue: "22"}.whereClause()
+		if len(args) != 6 {
+			t.Fatalf("Expected 6 args, got %d", len(args))
+		}
+		for _, placeholder := range []string{"$1", "$2", "$3", "$4", "$5", "$6"} {
+			if !strings.Contains(where, placeholder) {
+				t.Errorf("Expected clause to contain %s, got %q", placeholder, where)
+			}
+		}
+		if strings.Contains(where, "?") {
+			t.Errorf("Expected no unreplaced placeholders, got %q", where)
+		}
+	})
+
+	t.Run("feature key without value is ignored", func(t *testing.T) {
+		where, _ := GuitarFilter{FeatureKey: "frets"}.whereClause()
+		if where != "" {
+			t.Errorf("Expected empty clause, got %q", where)
+		}
+	})
+}
+
+func TestGuitarStore_FacetCounts(t *testing.T) {
+	pool := testPool(t)
+	store := GuitarStore{DB: pool}
+	ctx := context.Background()
+
+	all, err := store.FacetCounts(ctx, GuitarFilter{})
+	if err != nil {
+		t.Fatalf("FacetCounts failed: %v", err)
+	}
+	if len(all.Brands) == 0 {
+		t.Skip("no guitars in test database")
+	}
+
+	total := func(counts []FacetCount) int {
+		n := 0
+		for _, c := range counts {
+			n += c.Count
+		}
+		return n
+	}
+
+	// Each grouping partitions the same set of guitars
+	if total(all.Brands) != total(all.Shapes) || total(all.Brands) != total(all.Types) {
+		t.Errorf("Expected equal totals, got brands=%d shapes=%d types=%d",
+			total(all.Brands), total(all.Shapes), total(all.Types))
+	}
+
+	brand := all.Brands[0]
+	narrowed, err := store.FacetCounts(ctx, GuitarFilter{BrandSlug: brand.Value})
+	if err != nil {
+		t.Fatalf("FacetCounts with brand filter failed: %v", err)
+	}
+	if len(narrowed.Brands) != 1 || narrowed.Brands[0].Count != brand.Count {
+		t.Errorf("Expected single brand facet %+v, got %+v", brand, narrowed.Brands)
+	}
+	if total(narrowed.Shapes) != brand.Count {
+		t.Errorf("Expected shape counts to sum to %d, got %d", brand.Count, total(narrowed.Shapes))
+	}
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"guitar-specs/internal/timing"
+)
+
+// Feature describes one spec in the feature taxonomy, with the allowed values
+// of enum features. AllowedValues is empty for other kinds.
+type Feature struct {
+	Key           string
+	Label         string
+	Kind          string
+	Unit          *string
+	Description   *string
+	AllowedValues []AllowedValue
+}
+
+// AllowedValue is one permitted value of an enum feature.
+type AllowedValue struct {
+	Value       string
+	Description *string
+}
+
+// FeatureStore provides read operations over the feature taxonomy.
+type FeatureStore struct {
+	DB       *pgxpool.Pool
+	Timeouts QueryTimeouts
+}
+
+// featureRow is one row of the features/allowed values join. Value is nil for
+// features without allowed values.
+type featureRow struct {
+	Feature
+	Value            *string
+	ValueDescription *string
+}
+
+// List returns all features ordered by label, each with its allowed values ordered
+// by value. Features and values are fetched in one joined query and grouped in Go.
+func (s FeatureStore) List(ctx context.Context) ([]Feature, error) {
+	if s.DB == nil {
+		return nil, errors.New("nil DB")
+	}
+	ctx, cancel := withQueryTimeout(ctx, s.Timeouts.List)
+	defer cancel()
+	defer timing.Track(ctx, "db")()
+
+	const q = `
+		select
+			f.key,
+			f.label,
+			f.kind::text,
+			f.unit,
+			f.description,
+			fav.value,
+			fav.description
+		from public.features f
+		left join public.feature_allowed_values fav on fav.feature_id = f.id
+		order by f.label, f.key, fav.value
+	`
+	rows, err := s.DB.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []featureRow
+	for rows.Next() {
+		var r featureRow
+		if err := rows.Scan(
+			&r.Key, &r.Label, &r.Kind, &r.Unit, &r.Description, &r.Value, &r.ValueDescription,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return groupFeatures(out), nil
+}
+
+// groupFeatures folds consecutive rows of the same feature into one Feature.
+// Rows must be ordered so that all rows of a feature are adjacent.
+func groupFeatures(rows []featureRow) []Feature {
+	features := make([]Feature, 0, len(rows))
+	for _, r := range rows {
+		if n := len(features); n == 0 || features[n-1].Key != r.Key {
+			f := r.Feature
+			f.AllowedValues = nil
+			features = append(features, f)
+		}
+		if r.Value != nil {
+			last := &features[len(features)-1]
+			last.AllowedValues = append(last.AllowedValues, AllowedValue{Value: *r.Value, Description: r.ValueDescription})
+		}
+	}
+	return features
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGroupFeatures(t *testing.T) {
+	str := func(s string) *string { return &s }
+	rows := []featureRow{
+		{Feature: Feature{Key: "body_wood", Label: "Body wood", Kind: "enum"}, Value: str("alder"), ValueDescription: str("Light, balanced")},
+		{Feature: Feature{Key: "body_wood", Label: "Body wood", Kind: "enum"}, Value: str("ash")},
+		{Feature: Feature{Key: "body_wood", Label: "Body wood", Kind: "enum"}, Value: str("mahogany")},
+		{Feature: Feature{Key: "frets", Label: "Frets", Kind: "number"}},
+		{Feature: Feature{Key: "pickups", Label: "Pickups", Kind: "enum"}, Value: str("humbucker")},
+	}
+
+	features := groupFeatures(rows)
+
+	if len(features) != 3 {
+		t.Fatalf("Expected 3 features, got %d: %+v", len(features), features)
+	}
+
+	wood := features[0]
+	if wood.Key != "body_wood" || len(wood.AllowedValues) != 3 {
+		t.Fatalf("Expected body_wood with 3 allowed values, got %+v", wood)
+	}
+	for i, want := range []string{"alder", "ash", "mahogany"} {
+		if wood.AllowedValues[i].Value != want {
+			t.Errorf("Expected allowed value %d to be %s, got %s", i, want, wood.AllowedValues[i].Value)
+		}
+	}
+	if d := wood.AllowedValues[0].Description; d == nil || *d != "Light, balanced" {
+		t.Errorf("Expected value description to be kept, got %v", d)
+	}
+
+	if features[1].Key != "frets" || features[1].AllowedValues != nil {
+		t.Errorf("Expected frets without allowed values, got %+v", features[1])
+	}
+	if features[2].Key != "pickups" || len(features[2].AllowedValues) != 1 {
+		t.Errorf("Expected pickups with 1 allowed value, got %+v", features[2])
+	}
+}
+
+func TestFeatureStore_List(t *testing.T) {
+	if _, err := (FeatureStore{}).List(context.Background()); err == nil {
+		t.Error("Expected error from List with nil DB")
+	}
+
+	pool := testPool(t)
+	features, err := FeatureStore{DB: pool}.List(context.Background())
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	// Ordering is by database collation, so only the grouping is checked here
+	seen := make(map[string]bool)
+	for _, f := range features {
+		if seen[f.Key] {
+			t.Errorf("Expected each feature once, got %s again", f.Key)
+		}
+		seen[f.Key] = true
+	}
+}
@@ -3,11 +3,18 @@ package models
 import (
 	"context"
 	"errors"
-	"time"
+	"strconv"
+	"strings"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"guitar-specs/internal/timing"
 )
 
+// ErrNotFound is returned when a requested record does not exist.
+var ErrNotFound = errors.New("not found")
+
 // Guitar mirrors selected fields of public.guitars for application usage.
 type Guitar struct {
 	ID        string
@@ -23,21 +30,22 @@ type Guitar struct {
 
 // GuitarStore provides read operations over guitars.
 type GuitarStore struct {
-	DB *pgxpool.Pool
+	DB       *pgxpool.Pool
+	Timeouts QueryTimeouts
+	flight   *detailFlight // Deduplicates concurrent detail lookups; nil disables
+	stale    *staleList    // Last good list for ListOrStale; nil disables the fallback
 }
 
-// List returns guitars ordered by brand, model. Context has a safety timeout.
+// List returns guitars ordered by brand, model. Context has a safety timeout (Timeouts.List).
 func (s GuitarStore) List(ctx context.Context) ([]Guitar, error) {
 	if s.DB == nil {
 		return nil, errors.New("nil DB")
 	}
 
 	// Apply a short safety timeout to avoid lingering queries if caller forgot one.
-	var cancel func()
-	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
-		ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
-		defer cancel()
-	}
+	ctx, cancel := withQueryTimeout(ctx, s.Timeouts.List)
+	defer cancel()
+	defer timing.Track(ctx, "db")()
 
 	const q = `
 		select 
@@ -58,6 +66,142 @@ func (s GuitarStore) List(ctx context.Context) ([]Guitar, error) {
 	if err != nil {
 		return nil, err
 	}
+	return scanGuitars(rows)
+}
+
+// ExportBatchSize is the number of guitars Each reads per query.
+const ExportBatchSize = 500
+
+// Each calls fn for every guitar, in List order, reading batchSize rows at a time
+// (ExportBatchSize when not positive) so the whole catalogue is never held in
+// memory. Batches are paged by the last row seen rather than by offset, and each
+// gets its own Timeouts.List safety timeout. Iteration stops at the first error
+// from fn, which is returned as-is.
+func (s GuitarStore) Each(ctx context.Context, batchSize int, fn func(Guitar) error) error {
+	if s.DB == nil {
+		return errors.New("nil DB")
+	}
+	if batchSize <= 0 {
+		batchSize = ExportBatchSize
+	}
+
+	var last *Guitar
+	for {
+		batch, err := s.exportBatch(ctx, last, batchSize)
+		if err != nil {
+			return err
+		}
+		for i := range batch {
+			if err := fn(batch[i]); err != nil {
+				return err
+			}
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+		last = &batch[len(batch)-1]
+	}
+}
+
+// exportBatch reads up to limit guitars ordered after the cursor row (from the
+// start when after is nil). The id breaks ties between same-named models.
+func (s GuitarStore) exportBatch(ctx context.Context, after *Guitar, limit int) ([]Guitar, error) {
+	ctx, cancel := withQueryTimeout(ctx, s.Timeouts.List)
+	defer cancel()
+	defer timing.Track(ctx, "db")()
+
+	const q = `
+		select 
+			g.id::text,
+			g.slug::text,
+			g.type::text,
+			g.model,
+			b.slug::text as brand_slug,
+			b.name        as brand_name,
+			s.slug::text  as shape_slug,
+			s.name        as shape_name
+		from public.guitars g
+		join public.brands b on b.slug = g.brand_slug
+		join public.shapes s on s.slug = g.shape_slug
+		where $3::uuid is null or (b.name, g.model, g.id) > ($1, $2, $3::uuid)
+		order by b.name, g.model, g.id
+		limit $4
+	`
+	// A nil cursor leaves every argument NULL, which selects the first batch
+	var name, model, id any
+	if after != nil {
+		name, model, id = after.BrandName, after.Model, after.ID
+	}
+	rows, err := s.DB.Query(ctx, q, name, model, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanGuitars(rows)
+}
+
+// ListByFeature returns guitars having the given feature set to value, ordered by brand, model.
+// The value is matched against the column appropriate to the feature kind: the allowed
+// value for enums, the text for text features, and the parsed number or boolean for
+// numeric and boolean features (a value that doesn't parse simply matches nothing).
+func (s GuitarStore) ListByFeature(ctx context.Context, featureKey string, value string) ([]Guitar, error) {
+	if s.DB == nil {
+		return nil, errors.New("nil DB")
+	}
+	ctx, cancel := withQueryTimeout(ctx, s.Timeouts.List)
+	defer cancel()
+	defer timing.Track(ctx, "db")()
+
+	num, boolean := featureValueArgs(value)
+
+	const q = `
+		select 
+			g.id::text,
+			g.slug::text,
+			g.type::text,
+			g.model,
+			b.slug::text as brand_slug,
+			b.name        as brand_name,
+			s.slug::text  as shape_slug,
+			s.name        as shape_name
+		from public.guitars g
+		join public.brands b on b.slug = g.brand_slug
+		join public.shapes s on s.slug = g.shape_slug
+		join public.guitar_features gf on gf.guitar_id = g.id
+		join public.features f         on f.id = gf.feature_id
+		left join public.feature_allowed_values fav on fav.id = gf.allowed_value_id
+		where f.key = $1
+		  and (
+		       (f.kind = 'enum'    and fav.value = $2)
+		    or (f.kind = 'text'    and gf.value_text = $2)
+		    or (f.kind = 'number'  and gf.value_number = $3::numeric)
+		    or (f.kind = 'boolean' and gf.value_boolean = $4::boolean)
+		  )
+		order by b.name, g.model
+	`
+	rows, err := s.DB.Query(ctx, q, featureKey, value, num, boolean)
+	if err != nil {
+		return nil, err
+	}
+	return scanGuitars(rows)
+}
+
+// featureValueArgs parses a filter value into its numeric and boolean forms.
+// Either result is nil when the value doesn't parse as that type.
+func featureValueArgs(value string) (*float64, *bool) {
+	var num *float64
+	if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+		num = &f
+	}
+	var boolean *bool
+	if b, err := strconv.ParseBool(strings.TrimSpace(value)); err == nil {
+		boolean = &b
+	}
+	return num, boolean
+}
+
+// scanGuitars reads guitar rows selected with the standard brand/shape column list.
+// It closes rows before returning.
+func scanGuitars(rows pgx.Rows) ([]Guitar, error) {
 	defer rows.Close()
 
 	guitars := make([]Guitar, 0, 64)
@@ -83,6 +227,45 @@ func (s GuitarStore) List(ctx context.Context) ([]Guitar, error) {
 	return guitars, nil
 }
 
+// Random returns a single randomly chosen guitar with brand and shape names.
+// It returns ErrNotFound when the catalogue is empty.
+func (s GuitarStore) Random(ctx context.Context) (*Guitar, error) {
+	if s.DB == nil {
+		return nil, errors.New("nil DB")
+	}
+	ctx, cancel := withQueryTimeout(ctx, s.Timeouts.Get)
+	defer cancel()
+	defer timing.Track(ctx, "db")()
+
+	// ORDER BY random() scans the table, which is fine at catalogue scale
+	const q = `
+		select 
+			g.id::text,
+			g.slug::text,
+			g.type::text,
+			g.model,
+			b.slug::text as brand_slug,
+			b.name        as brand_name,
+			s.slug::text  as shape_slug,
+			s.name        as shape_name
+		from public.guitars g
+		join public.brands b on b.slug = g.brand_slug
+		join public.shapes s on s.slug = g.shape_slug
+		order by random()
+		limit 1
+	`
+	var g Guitar
+	if err := s.DB.QueryRow(ctx, q).Scan(
+		&g.ID, &g.Slug, &g.Type, &g.Model, &g.BrandSlug, &g.BrandName, &g.ShapeSlug, &g.ShapeName,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &g, nil
+}
+
 // GuitarFeatureResolved represents a resolved feature value for display.
 type GuitarFeatureResolved struct {
 	FeatureKey      string
@@ -97,16 +280,38 @@ type GuitarFeatureResolved struct {
 	Unit            *string
 }
 
+// DisplayOrDash returns the display value for the feature, or "—" when there is none.
+// ValueDisplay is computed in SQL and is nil when no value column is set; if it is
+// missing but a typed value is present, the same formatting is applied in Go.
+func (f GuitarFeatureResolved) DisplayOrDash() string {
+	if f.ValueDisplay != nil && strings.TrimSpace(*f.ValueDisplay) != "" {
+		return *f.ValueDisplay
+	}
+	switch {
+	case f.EnumValue != nil && *f.EnumValue != "":
+		return *f.EnumValue
+	case f.ValueText != nil && *f.ValueText != "":
+		return *f.ValueText
+	case f.ValueNumber != nil:
+		s := strconv.FormatFloat(*f.ValueNumber, 'f', -1, 64)
+		if f.Unit != nil && *f.Unit != "" {
+			s += " " + *f.Unit
+		}
+		return s
+	case f.ValueBoolean != nil:
+		return strconv.FormatBool(*f.ValueBoolean)
+	}
+	return "—"
+}
+
 // GetBySlug returns a single guitar by slug with brand and shape names.
 func (s GuitarStore) GetBySlug(ctx context.Context, slug string) (*Guitar, error) {
 	if s.DB == nil {
 		return nil, errors.New("nil DB")
 	}
-	var cancel func()
-	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
-		ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
-		defer cancel()
-	}
+	ctx, cancel := withQueryTimeout(ctx, s.Timeouts.Get)
+	defer cancel()
+	defer timing.Track(ctx, "db")()
 	const q = `
 		select 
 			g.id::text,
@@ -136,11 +341,9 @@ func (s GuitarStore) ListFeaturesBySlug(ctx context.Context, slug string) ([]Gui
 	if s.DB == nil {
 		return nil, errors.New("nil DB")
 	}
-	var cancel func()
-	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
-		ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
-		defer cancel()
-	}
+	ctx, cancel := withQueryTimeout(ctx, s.Timeouts.Features)
+	defer cancel()
+	defer timing.Track(ctx, "db")()
 	const fq = `
 SELECT
   f.key          AS feature_key,
@@ -0,0 +1,240 @@
+package models
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testPool connects to the database named by TEST_DATABASE_URL.
+// Tests needing a real PostgreSQL instance are skipped when it is unset.
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set; skipping database test")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		t.Fatalf("Failed to ping database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+func TestGuitarStore_NilDB(t *testing.T) {
+	store := GuitarStore{}
+	ctx := context.Background()
+
+	if _, err := store.List(ctx); err == nil {
+		t.Error("Expected error from List with nil DB")
+	}
+	if _, err := store.GetBySlug(ctx, "any"); err == nil {
+		t.Error("Expected error from GetBySlug with nil DB")
+	}
+	if _, err := store.Random(ctx); err == nil {
+		t.Error("Expected error from Random with nil DB")
+	}
+	if err := store.Each(ctx, 0, func(Guitar) error { return nil }); err == nil {
+		t.Error("Expected error from Each with nil DB")
+	}
+	if _, _, err := store.GetBrandWithGuitars(ctx, "any"); err == nil {
+		t.Error("Expected error from GetBrandWithGuitars with nil DB")
+	}
+	if _, _, err := store.GetShapeWithGuitars(ctx, "any"); err == nil {
+		t.Error("Expected error from GetShapeWithGuitars with nil DB")
+	}
+}
+
+func TestGuitarStore_Random(t *testing.T) {
+	pool := testPool(t)
+	store := GuitarStore{DB: pool}
+	ctx := context.Background()
+
+	all, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	g, err := store.Random(ctx)
+	if len(all) == 0 {
+		if err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound for empty table, got %v", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if g.Slug == "" || g.BrandName == "" || g.ShapeName == "" {
+		t.Errorf("Expected a fully populated guitar, got %+v", g)
+	}
+
+	found := false
+	for _, candidate := range all {
+		if candidate.Slug == g.Slug {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("Random returned slug %q not present in List", g.Slug)
+	}
+}
+
+func TestFeatureValueArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		wantNum  *float64
+		wantBool *bool
+	}{
+		{name: "integer", value: "22", wantNum: ptr(22.0)},
+		{name: "decimal with spaces", value: " 25.5 ", wantNum: ptr(25.5)},
+		{name: "boolean", value: "true", wantBool: ptr(true)},
+		{name: "numeric boolean", value: "1", wantNum: ptr(1.0), wantBool: ptr(true)},
+		{name: "enum text", value: "humbucker"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			num, boolean := featureValueArgs(tt.value)
+			if (num == nil) != (tt.wantNum == nil) || (num != nil && *num != *tt.wantNum) {
+				t.Errorf("featureValueArgs(%q) num = %v, want %v", tt.value, deref(num), deref(tt.wantNum))
+			}
+			if (boolean == nil) != (tt.wantBool == nil) || (boolean != nil && *boolean != *tt.wantBool) {
+				t.Errorf("featureValueArgs(%q) bool = %v, want %v", tt.value, deref(boolean), deref(tt.wantBool))
+			}
+		})
+	}
+}
+
+func TestGuitarStore_ListByFeature(t *testing.T) {
+	pool := testPool(t)
+	store := GuitarStore{DB: pool}
+	ctx := context.Background()
+
+	// Pick a real feature value of the given kind from the data set
+	sample := func(t *testing.T, kind string) (string, string) {
+		t.Helper()
+		var key, value string
+		err := pool.QueryRow(ctx, `
+			select f.key, coalesce(fav.value, gf.value_text, gf.value_number::text, gf.value_boolean::text)
+			from public.guitar_features gf
+			join public.features f on f.id = gf.feature_id
+			left join public.feature_allowed_values fav on fav.id = gf.allowed_value_id
+			where f.kind::text = $1
+			limit 1`, kind).Scan(&key, &value)
+		if err != nil {
+			t.Skipf("no %s feature values in test database: %v", kind, err)
+		}
+		return key, value
+	}
+
+	for _, kind := range []string{"enum", "number"} {
+		t.Run(kind+" feature", func(t *testing.T) {
+			key, value := sample(t, kind)
+
+			guitars, err := store.ListByFeature(ctx, key, value)
+			if err != nil {
+				t.Fatalf("ListByFeature(%q, %q) failed: %v", key, value, err)
+			}
+			if len(guitars) == 0 {
+				t.Fatalf("Expected at least one guitar with %s=%s", key, value)
+			}
+
+			// Every returned guitar must actually carry the feature
+			for _, g := range guitars {
+				feats, err := store.ListFeaturesBySlug(ctx, g.Slug)
+				if err != nil {
+					t.Fatalf("ListFeaturesBySlug(%q) failed: %v", g.Slug, err)
+				}
+				found := false
+				for _, f := range feats {
+					if f.FeatureKey == key {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("Guitar %q returned without feature %q", g.Slug, key)
+				}
+			}
+		})
+	}
+
+	t.Run("unknown value matches nothing", func(t *testing.T) {
+		guitars, err := store.ListByFeature(ctx, "no-such-feature", "no-such-value")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(guitars) != 0 {
+			t.Errorf("Expected no guitars, got %d", len(guitars))
+		}
+	})
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func deref[T any](p *T) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+func TestGuitarFeatureResolved_DisplayOrDash(t *testing.T) {
+	tests := []struct {
+		name    string
+		feature GuitarFeatureResolved
+		want    string
+	}{
+		{name: "all nil", feature: GuitarFeatureResolved{}, want: "—"},
+		{name: "empty display", feature: GuitarFeatureResolved{ValueDisplay: ptr("")}, want: "—"},
+		{name: "whitespace display", feature: GuitarFeatureResolved{ValueDisplay: ptr("  ")}, want: "—"},
+		{name: "display set", feature: GuitarFeatureResolved{ValueDisplay: ptr("25.5 in")}, want: "25.5 in"},
+		{
+			name:    "display wins over typed values",
+			feature: GuitarFeatureResolved{ValueDisplay: ptr("Maple"), ValueText: ptr("maple")},
+			want:    "Maple",
+		},
+		{name: "enum only", feature: GuitarFeatureResolved{EnumValue: ptr("humbucker")}, want: "humbucker"},
+		{name: "text only", feature: GuitarFeatureResolved{ValueText: ptr("Rosewood")}, want: "Rosewood"},
+		{name: "empty text", feature: GuitarFeatureResolved{ValueText: ptr("")}, want: "—"},
+		{name: "number without unit", feature: GuitarFeatureResolved{ValueNumber: ptr(22.0)}, want: "22"},
+		{
+			name:    "number with unit",
+			feature: GuitarFeatureResolved{ValueNumber: ptr(25.5), Unit: ptr("in")},
+			want:    "25.5 in",
+		},
+		{
+			name:    "number with empty unit",
+			feature: GuitarFeatureResolved{ValueNumber: ptr(6.0), Unit: ptr("")},
+			want:    "6",
+		},
+		{name: "unit without number", feature: GuitarFeatureResolved{Unit: ptr("mm")}, want: "—"},
+		{name: "boolean true", feature: GuitarFeatureResolved{ValueBoolean: ptr(true)}, want: "true"},
+		{name: "boolean false", feature: GuitarFeatureResolved{ValueBoolean: ptr(false)}, want: "false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.feature.DisplayOrDash(); got != tt.want {
+				t.Errorf("DisplayOrDash() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"guitar-specs/internal/timing"
+)
+
+// Shape mirrors public.shapes. Description is nil when unset.
+type Shape struct {
+	Slug        string
+	Name        string
+	Description *string
+}
+
+// GetShapeWithGuitars returns a body shape and its guitars ordered by brand, model.
+// As with GetBrandWithGuitars, an unknown slug returns ErrNotFound and a shape
+// without guitars returns an empty slice.
+func (s GuitarStore) GetShapeWithGuitars(ctx context.Context, shapeSlug string) (*Shape, []Guitar, error) {
+	if s.DB == nil {
+		return nil, nil, errors.New("nil DB")
+	}
+
+	shape, err := s.getShape(ctx, shapeSlug)
+	if err != nil {
+		return nil, nil, err
+	}
+	guitars, err := s.listByShape(ctx, shapeSlug)
+	if err != nil {
+		return nil, nil, err
+	}
+	return shape, guitars, nil
+}
+
+// getShape returns a single shape by slug, or ErrNotFound.
+func (s GuitarStore) getShape(ctx context.Context, slug string) (*Shape, error) {
+	ctx, cancel := withQueryTimeout(ctx, s.Timeouts.Get)
+	defer cancel()
+	defer timing.Track(ctx, "db")()
+
+	const q = `
+		select
+			s.slug::text,
+			s.name,
+			s.description
+		from public.shapes s
+		where s.slug = $1
+	`
+	var shape Shape
+	if err := s.DB.QueryRow(ctx, q, slug).Scan(&shape.Slug, &shape.Name, &shape.Description); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &shape, nil
+}
+
+// listByShape returns the guitars of one body shape ordered by brand, model.
+func (s GuitarStore) listByShape(ctx context.Context, shapeSlug string) ([]Guitar, error) {
+	ctx, cancel := withQueryTimeout(ctx, s.Timeouts.List)
+	defer cancel()
+	defer timing.Track(ctx, "db")()
+
+	const q = `
+		select 
+			g.id::text,
+			g.slug::text,
+			g.type::text,
+			g.model,
+			b.slug::text as brand_slug,
+			b.name        as brand_name,
+			s.slug::text  as shape_slug,
+			s.name        as shape_name
+		from public.guitars g
+		join public.brands b on b.slug = g.brand_slug
+		join public.shapes s on s.slug = g.shape_slug
+		where s.slug = $1
+		order by b.name, g.model
+	`
+	rows, err := s.DB.Query(ctx, q, shapeSlug)
+	if err != nil {
+		return nil, err
+	}
+	return scanGuitars(rows)
+}
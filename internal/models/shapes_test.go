@@ -0,0 +1,57 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestGuitarStore_GetShapeWithGuitars(t *testing.T) {
+	pool := testPool(t)
+	store := GuitarStore{DB: pool}
+	ctx := context.Background()
+
+	t.Run("unknown shape", func(t *testing.T) {
+		shape, guitars, err := store.GetShapeWithGuitars(ctx, "no-such-shape")
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+		if shape != nil || guitars != nil {
+			t.Errorf("Expected no results, got %+v and %d guitars", shape, len(guitars))
+		}
+	})
+
+	t.Run("shape with guitars", func(t *testing.T) {
+		all, err := store.List(ctx)
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if len(all) == 0 {
+			t.Skip("no guitars in database")
+		}
+		shapeSlug := all[0].ShapeSlug
+
+		shape, guitars, err := store.GetShapeWithGuitars(ctx, shapeSlug)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if shape.Slug != shapeSlug || shape.Name != all[0].ShapeName {
+			t.Errorf("Expected shape %s (%s), got %+v", shapeSlug, all[0].ShapeName, shape)
+		}
+
+		want := 0
+		for _, g := range all {
+			if g.ShapeSlug == shapeSlug {
+				want++
+			}
+		}
+		if len(guitars) != want {
+			t.Errorf("Expected %d guitars for %s, got %d", want, shapeSlug, len(guitars))
+		}
+		for _, g := range guitars {
+			if g.ShapeSlug != shapeSlug {
+				t.Errorf("Expected only %s guitars, got %+v", shapeSlug, g)
+			}
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// staleList remembers the last successful guitar list so it can be served when
+// the database is briefly unavailable. Only the unfiltered catalogue is kept:
+// it is the one read-only, cacheable listing every visitor shares.
+type staleList struct {
+	mu        sync.RWMutex
+	guitars   []Guitar
+	fetchedAt time.Time
+	logger    *slog.Logger
+}
+
+// EnableStaleFallback makes ListOrStale serve the last successful list when the
+// live query fails. Degradations are logged at warn level via logger.
+func (s *Store) EnableStaleFallback(logger *slog.Logger) {
+	s.Guitars.stale = &staleList{logger: logger}
+}
+
+// ListOrStale behaves like List, except that with stale fallback enabled a failed
+// query returns the last successful result and stale=true instead of the error.
+// Without a snapshot to fall back on, the error is returned as usual.
+func (s GuitarStore) ListOrStale(ctx context.Context) ([]Guitar, bool, error) {
+	if s.stale == nil {
+		list, err := s.List(ctx)
+		return list, false, err
+	}
+	return s.stale.get(ctx, s.List)
+}
+
+// get runs load, refreshing the snapshot on success and falling back to it on failure.
+func (c *staleList) get(ctx context.Context, load func(context.Context) ([]Guitar, error)) ([]Guitar, bool, error) {
+	list, err := load(ctx)
+	if err == nil {
+		c.mu.Lock()
+		c.guitars, c.fetchedAt = list, time.Now()
+		c.mu.Unlock()
+		return list, false, nil
+	}
+
+	c.mu.RLock()
+	snapshot, fetchedAt := c.guitars, c.fetchedAt
+	c.mu.RUnlock()
+
+	if snapshot == nil {
+		return nil, false, err
+	}
+
+	if c.logger != nil {
+		c.logger.Warn("serving stale guitar list", "error", err, "age_ms", time.Since(fetchedAt).Milliseconds())
+	}
+	return snapshot, true, nil
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestStaleList(t *testing.T) {
+	var buf bytes.Buffer
+	cache := &staleList{logger: slog.New(slog.NewTextHandler(&buf, nil))}
+	ctx := context.Background()
+	dbDown := errors.New("connection refused")
+
+	failing := func(ctx context.Context) ([]Guitar, error) { return nil, dbDown }
+	working := func(ctx context.Context) ([]Guitar, error) {
+		return []Guitar{{Slug: "stratocaster"}, {Slug: "les-paul"}}, nil
+	}
+
+	t.Run("error without snapshot is returned", func(t *testing.T) {
+		_, stale, err := cache.get(ctx, failing)
+		if !errors.Is(err, dbDown) {
+			t.Errorf("Expected error to be returned, got %v", err)
+		}
+		if stale {
+			t.Error("Expected stale to be false")
+		}
+	})
+
+	t.Run("successful load is fresh", func(t *testing.T) {
+		list, stale, err := cache.get(ctx, working)
+		if err != nil || stale || len(list) != 2 {
+			t.Errorf("Expected fresh list of 2, got %d (stale=%v, err=%v)", len(list), stale, err)
+		}
+	})
+
+	t.Run("store error serves stale data", func(t *testing.T) {
+		buf.Reset()
+		list, stale, err := cache.get(ctx, failing)
+		if err != nil {
+			t.Fatalf("Expected stale data instead of error, got %v", err)
+		}
+		if !stale {
+			t.Error("Expected stale to be true")
+		}
+		if len(list) != 2 || list[0].Slug != "stratocaster" {
+			t.Errorf("Expected last good list, got %+v", list)
+		}
+		if !strings.Contains(buf.String(), "serving stale guitar list") {
+			t.Errorf("Expected degradation to be logged, got: %s", buf.String())
+		}
+	})
+}
@@ -4,13 +4,16 @@ import "github.com/jackc/pgx/v5/pgxpool"
 
 // Store aggregates all model stores backed by a shared pgx connection pool.
 type Store struct {
-	DB      *pgxpool.Pool
-	Guitars GuitarStore
+	DB       *pgxpool.Pool
+	Guitars  GuitarStore
+	Features FeatureStore
 }
 
 // NewStore constructs a Store with initialised repositories.
-func NewStore(db *pgxpool.Pool) *Store {
+// Zero timeouts fall back to DefaultQueryTimeout.
+func NewStore(db *pgxpool.Pool, timeouts QueryTimeouts) *Store {
 	s := &Store{DB: db}
-	s.Guitars = GuitarStore{DB: db}
+	s.Guitars = GuitarStore{DB: db, Timeouts: timeouts, flight: &detailFlight{}}
+	s.Features = FeatureStore{DB: db, Timeouts: timeouts}
 	return s
 }
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultQueryTimeout is the safety timeout applied when none is configured.
+const DefaultQueryTimeout = 5 * time.Second
+
+// QueryTimeouts holds per-query-type safety timeouts.
+// They cap the caller's deadline rather than replace it: a query gets whichever
+// is sooner, so it never outlives the request. Zero values fall back to
+// DefaultQueryTimeout.
+type QueryTimeouts struct {
+	List     time.Duration // Listing queries (e.g. GuitarStore.List)
+	Get      time.Duration // Single-row lookups (e.g. GuitarStore.GetBySlug)
+	Features time.Duration // Feature resolution queries (e.g. ListFeaturesBySlug)
+}
+
+// withQueryTimeout bounds ctx by d (or the default). The effective deadline is
+// min(d, remaining request budget): a sooner caller deadline is kept as-is, a
+// later one is shortened to the safety timeout.
+func withQueryTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		d = DefaultQueryTimeout
+	}
+	if deadline, hasDeadline := ctx.Deadline(); hasDeadline && time.Until(deadline) <= d {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
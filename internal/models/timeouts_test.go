@@ -0,0 +1,109 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithQueryTimeout(t *testing.T) {
+	t.Run("applies configured timeout", func(t *testing.T) {
+		start := time.Now()
+		ctx, cancel := withQueryTimeout(context.Background(), 250*time.Millisecond)
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			t.Fatal("Expected deadline to be set")
+		}
+		if got := deadline.Sub(start); got < 250*time.Millisecond || got > 300*time.Millisecond {
+			t.Errorf("Expected deadline ~250ms away, got %v", got)
+		}
+	})
+
+	t.Run("defaults to DefaultQueryTimeout when unconfigured", func(t *testing.T) {
+		start := time.Now()
+		ctx, cancel := withQueryTimeout(context.Background(), 0)
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			t.Fatal("Expected deadline to be set")
+		}
+		if got := deadline.Sub(start); got < DefaultQueryTimeout-50*time.Millisecond || got > DefaultQueryTimeout+50*time.Millisecond {
+			t.Errorf("Expected deadline ~%v away, got %v", DefaultQueryTimeout, got)
+		}
+	})
+
+	t.Run("keeps sooner caller deadline", func(t *testing.T) {
+		parent, parentCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer parentCancel()
+		want, _ := parent.Deadline()
+
+		ctx, cancel := withQueryTimeout(parent, 250*time.Millisecond)
+		defer cancel()
+
+		got, _ := ctx.Deadline()
+		if !got.Equal(want) {
+			t.Errorf("Expected caller deadline %v to be preserved, got %v", want, got)
+		}
+	})
+
+	t.Run("caps later caller deadline", func(t *testing.T) {
+		parent, parentCancel := context.WithTimeout(context.Background(), time.Minute)
+		defer parentCancel()
+
+		start := time.Now()
+		ctx, cancel := withQueryTimeout(parent, 250*time.Millisecond)
+		defer cancel()
+
+		deadline, _ := ctx.Deadline()
+		if got := deadline.Sub(start); got < 250*time.Millisecond || got > 300*time.Millisecond {
+			t.Errorf("Expected deadline capped to ~250ms, got %v", got)
+		}
+	})
+
+	t.Run("short parent deadline cancels before safety timeout", func(t *testing.T) {
+		parent, parentCancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer parentCancel()
+
+		ctx, cancel := withQueryTimeout(parent, 0)
+		defer cancel()
+
+		select {
+		case <-ctx.Done():
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				t.Errorf("Expected deadline exceeded, got %v", ctx.Err())
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected the parent deadline to cancel the query context")
+		}
+	})
+}
+
+func TestGuitarStore_ParentDeadline(t *testing.T) {
+	pool := testPool(t)
+	store := GuitarStore{DB: pool, Timeouts: QueryTimeouts{List: DefaultQueryTimeout}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	time.Sleep(2 * time.Millisecond)
+
+	start := time.Now()
+	if _, err := store.List(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded from List, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected List to stop with the parent deadline, took %v", elapsed)
+	}
+}
+
+func TestNewStore_QueryTimeouts(t *testing.T) {
+	timeouts := QueryTimeouts{List: time.Second, Get: 2 * time.Second, Features: 3 * time.Second}
+	store := NewStore(nil, timeouts)
+
+	if store.Guitars.Timeouts != timeouts {
+		t.Errorf("Expected guitar store timeouts %+v, got %+v", timeouts, store.Guitars.Timeouts)
+	}
+}
@@ -0,0 +1,131 @@
+// Package permissionspolicy parses and renders the Permissions-Policy header.
+// It is shared by configuration validation and the security middleware, so
+// both accept exactly the same policies.
+package permissionspolicy
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Default disables browser APIs the site does not use.
+const Default = "geolocation=(), microphone=(), camera=()"
+
+// Header turns a PERMISSIONS_POLICY value into a header value; see Parse and
+// Build. An empty value gives Default.
+func Header(value string) (string, error) {
+	policy, err := Parse(value)
+	if err != nil {
+		return "", err
+	}
+	return Build(policy)
+}
+
+// Parse reads semicolon-separated "feature=sources" entries with
+// space-separated sources, e.g. "camera=self https://scanner.example.com;geolocation=",
+// into a feature→allowlist map. An entry with no sources disables the feature.
+// An empty value gives a nil map.
+func Parse(value string) (map[string][]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	policy := make(map[string][]string)
+	for _, entry := range strings.Split(value, ";") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		feature, sources, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("entry %q must be feature=sources", entry)
+		}
+		feature = strings.TrimSpace(feature)
+		if _, dup := policy[feature]; dup {
+			return nil, fmt.Errorf("feature %q is listed twice", feature)
+		}
+		policy[feature] = strings.Fields(sources)
+	}
+	return policy, nil
+}
+
+// Build renders a feature→allowlist map as a header value, with features
+// sorted so the header is stable. Allowlist entries are "self", "*" or origins
+// such as "https://example.com". It returns an error for malformed feature
+// names or allowlist entries, and Default for an empty map.
+func Build(policy map[string][]string) (string, error) {
+	if len(policy) == 0 {
+		return Default, nil
+	}
+
+	features := make([]string, 0, len(policy))
+	for feature := range policy {
+		if !validFeature(feature) {
+			return "", fmt.Errorf("invalid Permissions-Policy feature %q", feature)
+		}
+		features = append(features, feature)
+	}
+	slices.Sort(features)
+
+	directives := make([]string, 0, len(features))
+	for _, feature := range features {
+		allowlist, err := renderAllowlist(policy[feature])
+		if err != nil {
+			return "", fmt.Errorf("invalid Permissions-Policy allowlist for %q: %w", feature, err)
+		}
+		directives = append(directives, feature+"="+allowlist)
+	}
+	return strings.Join(directives, ", "), nil
+}
+
+// validFeature reports whether name is a lowercase feature token such as
+// "camera" or "publickey-credentials-get".
+func validFeature(name string) bool {
+	if name == "" || name[0] < 'a' || name[0] > 'z' {
+		return false
+	}
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// renderAllowlist renders one feature's allowlist in structured-field syntax:
+// * alone, or an inner list of self and quoted origins.
+func renderAllowlist(sources []string) (string, error) {
+	if slices.Contains(sources, "*") {
+		if len(sources) != 1 {
+			return "", fmt.Errorf("* cannot be combined with other sources")
+		}
+		return "*", nil
+	}
+
+	parts := make([]string, 0, len(sources))
+	for _, src := range sources {
+		switch {
+		case src == "self":
+			parts = append(parts, src)
+		case validOrigin(src):
+			parts = append(parts, `"`+src+`"`)
+		default:
+			return "", fmt.Errorf("source %q is not self, * or an origin", src)
+		}
+	}
+	return "(" + strings.Join(parts, " ") + ")", nil
+}
+
+// validOrigin reports whether src is a bare http(s) origin, with no path,
+// query or credentials.
+func validOrigin(src string) bool {
+	if strings.ContainsAny(src, "\" \t") {
+		return false
+	}
+	u, err := url.Parse(src)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "https" || u.Scheme == "http") && u.Host != "" &&
+		u.User == nil && u.Path == "" && u.RawQuery == "" && u.Fragment == ""
+}
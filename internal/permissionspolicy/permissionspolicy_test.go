@@ -0,0 +1,69 @@
+package permissionspolicy
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	policy, err := Parse(" geolocation= ; camera=self  https://scanner.example.com;fullscreen=*;")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := map[string][]string{
+		"geolocation": {},
+		"camera":      {"self", "https://scanner.example.com"},
+		"fullscreen":  {"*"},
+	}
+	if len(policy) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, policy)
+	}
+	for feature, sources := range want {
+		if !slices.Equal(policy[feature], sources) {
+			t.Errorf("Expected %s=%v, got %v", feature, sources, policy[feature])
+		}
+	}
+
+	if policy, err := Parse(""); policy != nil || err != nil {
+		t.Errorf("Expected nil policy for an empty value, got %v, %v", policy, err)
+	}
+}
+
+func TestHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "unset", value: "", want: Default},
+		{name: "valid", value: "geolocation=;camera=self https://scanner.example.com;fullscreen=*", want: `camera=(self "https://scanner.example.com"), fullscreen=*, geolocation=()`},
+		{name: "missing equals", value: "camera", wantErr: true},
+		{name: "duplicate feature", value: "camera=;camera=self", wantErr: true},
+		{name: "uppercase feature", value: "Camera=", wantErr: true},
+		{name: "feature with separator", value: "camera()=", wantErr: true},
+		{name: "wildcard with origins", value: "camera=* self", wantErr: true},
+		{name: "quoted keyword", value: "camera='self'", wantErr: true},
+		{name: "origin with path", value: "camera=https://example.com/scan", wantErr: true},
+		{name: "non-http origin", value: "camera=javascript:alert(1)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Header(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected '%s', got '%s'", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBuild_RejectsQuotedOrigin(t *testing.T) {
+	// Parse splits on spaces, so only a map can smuggle a quote into an origin
+	if _, err := Build(map[string][]string{"camera": {`https://a.com"), x=*`}}); err == nil {
+		t.Error("Expected an error for an origin containing a quote")
+	}
+}
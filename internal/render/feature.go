@@ -0,0 +1,50 @@
+package render
+
+import (
+	"html/template"
+	"strconv"
+
+	"guitar-specs/internal/models"
+)
+
+// FeatureValue renders a resolved feature's value as HTML suited to its kind:
+// a check mark or cross for booleans, the number with its unit for numbers, and
+// the value with its description as a tooltip for enums. Text features, unknown
+// kinds and features whose typed value is nil fall back to DisplayOrDash. A unit
+// follows any value except a dash. Every value is escaped. It is available to templates as featureValue.
+func FeatureValue(f models.GuitarFeatureResolved) template.HTML {
+	switch f.FeatureKind {
+	case "boolean":
+		if f.ValueBoolean != nil {
+			if *f.ValueBoolean {
+				return `<span class="text-green-600" title="Yes">✓<span class="sr-only">Yes</span></span>`
+			}
+			return `<span class="text-red-600" title="No">✗<span class="sr-only">No</span></span>`
+		}
+	case "number":
+		if f.ValueNumber != nil {
+			return template.HTML(`<span>` + template.HTMLEscapeString(strconv.FormatFloat(*f.ValueNumber, 'f', -1, 64)) + `</span>` + unitHTML(f))
+		}
+		// Without a typed number, a SQL-formatted display value already carries the unit
+		return template.HTML(template.HTMLEscapeString(f.DisplayOrDash()))
+	case "enum":
+		if f.EnumValue != nil && *f.EnumValue != "" && f.EnumDescription != nil && *f.EnumDescription != "" {
+			return template.HTML(`<span class="underline decoration-dotted cursor-help" title="` +
+				template.HTMLEscapeString(*f.EnumDescription) + `">` +
+				template.HTMLEscapeString(f.DisplayOrDash()) + `</span>` + unitHTML(f))
+		}
+	}
+	value := f.DisplayOrDash()
+	if value == "—" {
+		return "—"
+	}
+	return template.HTML(template.HTMLEscapeString(value) + unitHTML(f))
+}
+
+// unitHTML renders the feature's unit after its value, or nothing without one.
+func unitHTML(f models.GuitarFeatureResolved) string {
+	if f.Unit == nil || *f.Unit == "" {
+		return ""
+	}
+	return ` <span class="text-xs text-gray-400">` + template.HTMLEscapeString(*f.Unit) + `</span>`
+}
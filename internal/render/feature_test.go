@@ -0,0 +1,103 @@
+package render
+
+import (
+	"testing"
+
+	"guitar-specs/internal/models"
+)
+
+func TestFeatureValue(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(n float64) *float64 { return &n }
+	boolean := func(b bool) *bool { return &b }
+
+	tests := []struct {
+		name    string
+		feature models.GuitarFeatureResolved
+		want    string
+	}{
+		{
+			name:    "boolean true",
+			feature: models.GuitarFeatureResolved{FeatureKind: "boolean", ValueBoolean: boolean(true)},
+			want:    `<span class="text-green-600" title="Yes">✓<span class="sr-only">Yes</span></span>`,
+		},
+		{
+			name:    "boolean false",
+			feature: models.GuitarFeatureResolved{FeatureKind: "boolean", ValueBoolean: boolean(false)},
+			want:    `<span class="text-red-600" title="No">✗<span class="sr-only">No</span></span>`,
+		},
+		{
+			name:    "boolean nil",
+			feature: models.GuitarFeatureResolved{FeatureKind: "boolean"},
+			want:    "—",
+		},
+		{
+			name:    "number with unit",
+			feature: models.GuitarFeatureResolved{FeatureKind: "number", ValueNumber: num(25.5), Unit: str("in")},
+			want:    `<span>25.5</span> <span class="text-xs text-gray-400">in</span>`,
+		},
+		{
+			name:    "number without unit",
+			feature: models.GuitarFeatureResolved{FeatureKind: "number", ValueNumber: num(22)},
+			want:    `<span>22</span>`,
+		},
+		{
+			name:    "number nil",
+			feature: models.GuitarFeatureResolved{FeatureKind: "number", Unit: str("in")},
+			want:    "—",
+		},
+		{
+			name:    "enum with description",
+			feature: models.GuitarFeatureResolved{FeatureKind: "enum", EnumValue: str("humbucker"), EnumDescription: str(`Dual-coil "hum-cancelling"`)},
+			want:    `<span class="underline decoration-dotted cursor-help" title="Dual-coil &#34;hum-cancelling&#34;">humbucker</span>`,
+		},
+		{
+			name:    "enum without description",
+			feature: models.GuitarFeatureResolved{FeatureKind: "enum", EnumValue: str("humbucker")},
+			want:    "humbucker",
+		},
+		{
+			name:    "enum nil",
+			feature: models.GuitarFeatureResolved{FeatureKind: "enum", EnumDescription: str("unused")},
+			want:    "—",
+		},
+		{
+			name:    "text is escaped",
+			feature: models.GuitarFeatureResolved{FeatureKind: "text", ValueText: str("<b>Maple</b>")},
+			want:    "&lt;b&gt;Maple&lt;/b&gt;",
+		},
+		{
+			name:    "text with unit",
+			feature: models.GuitarFeatureResolved{FeatureKind: "text", ValueText: str("12-16"), Unit: str("in")},
+			want:    `12-16 <span class="text-xs text-gray-400">in</span>`,
+		},
+		{
+			name:    "enum with unit",
+			feature: models.GuitarFeatureResolved{FeatureKind: "enum", EnumValue: str("medium-jumbo"), Unit: str("frets")},
+			want:    `medium-jumbo <span class="text-xs text-gray-400">frets</span>`,
+		},
+		{
+			name:    "text nil with unit",
+			feature: models.GuitarFeatureResolved{FeatureKind: "text", Unit: str("in")},
+			want:    "—",
+		},
+		{
+			name:    "number display without typed value",
+			feature: models.GuitarFeatureResolved{FeatureKind: "number", ValueDisplay: str("25.5 in"), Unit: str("in")},
+			want:    "25.5 in",
+		},
+		{
+			name:    "text nil",
+			feature: models.GuitarFeatureResolved{FeatureKind: "text"},
+			want:    "—",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(FeatureValue(tt.feature)); got != tt.want {
+				t.Errorf("FeatureValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Layouts used by the date and time template helpers. There is no locale
+// support yet, so every page shares these English, day-first formats.
+const (
+	DateLayout = "2 Jan 2006"
+	TimeLayout = "2 Jan 2006, 15:04 MST"
+)
+
+// FormatDate formats a time.Time or *time.Time as DateLayout. Nil, zero and
+// unsupported values render as an empty string, so templates need no guards.
+// It is available to templates as formatDate.
+func FormatDate(v any) string {
+	return formatTimeValue(v, DateLayout)
+}
+
+// FormatTime formats a time.Time or *time.Time as TimeLayout, like FormatDate.
+// It is available to templates as formatTime.
+func FormatTime(v any) string {
+	return formatTimeValue(v, TimeLayout)
+}
+
+// formatTimeValue formats v with layout, or returns "" when v has no time.
+func formatTimeValue(v any, layout string) string {
+	var t time.Time
+	switch x := v.(type) {
+	case time.Time:
+		t = x
+	case *time.Time:
+		if x == nil {
+			return ""
+		}
+		t = *x
+	default:
+		return ""
+	}
+
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+// HumanizeDuration renders a time.Duration or *time.Duration with its two
+// largest units, e.g. "2d 3h", "1h 5m", "45s" or "250ms". Nil, zero and
+// unsupported values render as an empty string. It is available to templates
+// as humanizeDuration.
+func HumanizeDuration(v any) string {
+	var d time.Duration
+	switch x := v.(type) {
+	case time.Duration:
+		d = x
+	case *time.Duration:
+		if x == nil {
+			return ""
+		}
+		d = *x
+	default:
+		return ""
+	}
+
+	if d == 0 {
+		return ""
+	}
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	if d < time.Second {
+		return sign + fmt.Sprintf("%dms", d.Milliseconds())
+	}
+
+	units := []struct {
+		size   time.Duration
+		suffix string
+	}{
+		{24 * time.Hour, "d"},
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+		{time.Second, "s"},
+	}
+
+	var parts []string
+	for _, u := range units {
+		if n := d / u.size; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d%s", n, u.suffix))
+			d -= n * u.size
+		} else if len(parts) > 0 {
+			// Stop at a gap, so 1h0m30s reads "1h" rather than "1h 30s"
+			break
+		}
+		if len(parts) == 2 {
+			break
+		}
+	}
+	return sign + strings.Join(parts, " ")
+}
@@ -0,0 +1,98 @@
+package render
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestFormatDateAndTime(t *testing.T) {
+	ts := time.Date(2024, time.March, 5, 14, 7, 0, 0, time.UTC)
+	var nilTime *time.Time
+
+	tests := []struct {
+		name     string
+		input    any
+		wantDate string
+		wantTime string
+	}{
+		{name: "time value", input: ts, wantDate: "5 Mar 2024", wantTime: "5 Mar 2024, 14:07 UTC"},
+		{name: "time pointer", input: &ts, wantDate: "5 Mar 2024", wantTime: "5 Mar 2024, 14:07 UTC"},
+		{name: "nil pointer", input: nilTime},
+		{name: "zero time", input: time.Time{}},
+		{name: "nil", input: nil},
+		{name: "unsupported type", input: "2024-03-05"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatDate(tt.input); got != tt.wantDate {
+				t.Errorf("FormatDate() = %q, want %q", got, tt.wantDate)
+			}
+			if got := FormatTime(tt.input); got != tt.wantTime {
+				t.Errorf("FormatTime() = %q, want %q", got, tt.wantTime)
+			}
+		})
+	}
+}
+
+func TestHumanizeDuration(t *testing.T) {
+	d := 90 * time.Minute
+	var nilDuration *time.Duration
+
+	tests := []struct {
+		name  string
+		input any
+		want  string
+	}{
+		{name: "milliseconds", input: 250 * time.Millisecond, want: "250ms"},
+		{name: "seconds", input: 45 * time.Second, want: "45s"},
+		{name: "minutes and seconds", input: 5*time.Minute + 30*time.Second, want: "5m 30s"},
+		{name: "pointer", input: &d, want: "1h 30m"},
+		{name: "two largest units only", input: 2*24*time.Hour + 3*time.Hour + 15*time.Minute, want: "2d 3h"},
+		{name: "gap after largest unit", input: time.Hour + 30*time.Second, want: "1h"},
+		{name: "negative", input: -2 * time.Minute, want: "-2m"},
+		{name: "zero", input: time.Duration(0), want: ""},
+		{name: "nil pointer", input: nilDuration, want: ""},
+		{name: "nil", input: nil, want: ""},
+		{name: "unsupported type", input: 5, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HumanizeDuration(tt.input); got != tt.want {
+				t.Errorf("HumanizeDuration() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatFuncs_Template(t *testing.T) {
+	mockAssets := &MockAssetProvider{
+		assetURLs: make(map[string]string),
+		assetSRIs: make(map[string]string),
+	}
+	mockFS := fstest.MapFS{
+		"templates/pages/app.tmpl.html": &fstest.MapFile{
+			Data: []byte(`{{ formatDate .Page.added }}|{{ formatTime .Page.missing }}|{{ humanizeDuration .Page.age }}`),
+		},
+	}
+
+	renderer, err := New(mockFS, mockAssets, "test", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	out, err := renderer.RenderString("app", map[string]interface{}{
+		"added": time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC),
+		"age":   3 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "5 Mar 2024||3h"
+	if out != expected {
+		t.Errorf("Expected %s, got %s", expected, out)
+	}
+}
@@ -0,0 +1,49 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	mw "guitar-specs/internal/http/middleware"
+	"guitar-specs/internal/timing"
+)
+
+// ErrTemplateNotFound is returned when rendering a template name that was never registered.
+var ErrTemplateNotFound = errors.New("template not found")
+
+// HTML renders templateName into a buffer and writes it as an HTML response.
+// Buffering means a template that fails halfway never leaks a partial page: any
+// rendering error, including an unregistered template, results in a clean 500.
+// Deciding that a resource doesn't exist (404) remains the handler's job.
+func HTML(w http.ResponseWriter, r *http.Request, renderer Renderer, templateName string, data interface{}) {
+	HTMLStatus(w, r, renderer, templateName, http.StatusOK, data)
+}
+
+// HTMLStatus is HTML with an explicit status code, for rendered error pages
+// such as 404. A rendering failure still results in a 500, using the embedded
+// error page for browsers. The failure is logged with the template name and
+// request ID through the renderer's logger, or slog.Default if it has none.
+func HTMLStatus(w http.ResponseWriter, r *http.Request, renderer Renderer, templateName string, status int, data interface{}) {
+	var buf bytes.Buffer
+	stop := timing.Track(r.Context(), "render")
+	err := renderer.RenderWithRequest(&buf, templateName, r, data)
+	stop()
+	if err != nil {
+		logger := slog.Default()
+		if l, ok := renderer.(interface{ Logger() *slog.Logger }); ok && l.Logger() != nil {
+			logger = l.Logger()
+		}
+		requestID, _ := mw.RequestIDFromContext(r.Context())
+		logger.Error("template render failed", "template", templateName, "request_id", requestID, "path", r.URL.Path, "error", err)
+
+		// Browsers get the embedded error page, which needs no templates
+		mw.RespondInternalError(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = buf.WriteTo(w)
+}
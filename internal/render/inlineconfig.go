@@ -0,0 +1,33 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+)
+
+// InlineConfigGlobal is the window property InlineConfigScript assigns.
+const InlineConfigGlobal = "window.__CONFIG__"
+
+// InlineConfigScript returns a <script> element assigning data, encoded as JSON,
+// to window.__CONFIG__, carrying nonce so it runs under the strict CSP. It is
+// available to templates as inlineConfigScript:
+//
+//	{{ inlineConfigScript .Common.CSPNonce .Page.config }}
+//
+// encoding/json escapes <, > and & as \u003c, \u003e and \u0026, so a value such
+// as "</script>" can never close the element early, and U+2028/U+2029 are
+// escaped too, so the result is always valid JavaScript. An empty nonce omits
+// the attribute.
+func InlineConfigScript(nonce string, data any) (template.HTML, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode inline config: %w", err)
+	}
+
+	nonceAttr := ""
+	if nonce != "" {
+		nonceAttr = ` nonce="` + template.HTMLEscapeString(nonce) + `"`
+	}
+	return template.HTML("<script" + nonceAttr + ">" + InlineConfigGlobal + "=" + string(payload) + ";</script>"), nil
+}
@@ -0,0 +1,119 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// inlineConfigPayload extracts the JSON assigned by an inline config script.
+func inlineConfigPayload(t *testing.T, script string) string {
+	t.Helper()
+
+	_, rest, ok := strings.Cut(script, InlineConfigGlobal+"=")
+	if !ok {
+		t.Fatalf("Expected assignment to %s, got '%s'", InlineConfigGlobal, script)
+	}
+	payload, ok := strings.CutSuffix(rest, ";</script>")
+	if !ok {
+		t.Fatalf("Expected script to end with ';</script>', got '%s'", script)
+	}
+	return payload
+}
+
+func TestInlineConfigScript_EscapesPayload(t *testing.T) {
+	data := map[string]string{
+		"title":     "</script><script>alert(1)</script>",
+		"comment":   "<!-- x",
+		"query":     "a=1&b=2",
+		"separator": "line\u2028break\u2029end",
+	}
+
+	out, err := InlineConfigScript("", data)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	script := string(out)
+
+	if n := strings.Count(strings.ToLower(script), "</script"); n != 1 {
+		t.Errorf("Expected only the closing </script> tag, found %d in '%s'", n, script)
+	}
+	for _, raw := range []string{"<!--", "&", "\u2028", "\u2029"} {
+		if strings.Contains(script, raw) {
+			t.Errorf("Expected %q to be escaped, got '%s'", raw, script)
+		}
+	}
+
+	var decoded map[string]string
+	if err := json.Unmarshal([]byte(inlineConfigPayload(t, script)), &decoded); err != nil {
+		t.Fatalf("Expected valid JSON payload, got %v", err)
+	}
+	for k, v := range data {
+		if decoded[k] != v {
+			t.Errorf("Expected %s to round-trip as %q, got %q", k, v, decoded[k])
+		}
+	}
+}
+
+func TestInlineConfigScript_Nonce(t *testing.T) {
+	tests := []struct {
+		name       string
+		nonce      string
+		wantPrefix string
+	}{
+		{name: "nonce included", nonce: "abc123", wantPrefix: `<script nonce="abc123">`},
+		{name: "nonce escaped", nonce: `a"b`, wantPrefix: `<script nonce="a&#34;b">`},
+		{name: "empty nonce omitted", nonce: "", wantPrefix: `<script>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := InlineConfigScript(tt.nonce, map[string]int{"page": 1})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !strings.HasPrefix(string(out), tt.wantPrefix) {
+				t.Errorf("Expected prefix %s, got %s", tt.wantPrefix, out)
+			}
+		})
+	}
+}
+
+func TestInlineConfigScript_EncodeError(t *testing.T) {
+	if _, err := InlineConfigScript("abc", make(chan int)); err == nil {
+		t.Error("Expected an error for data that cannot be encoded")
+	}
+}
+
+func TestInlineConfigScript_Template(t *testing.T) {
+	mockAssets := &MockAssetProvider{
+		assetURLs: make(map[string]string),
+		assetSRIs: make(map[string]string),
+	}
+	mockFS := fstest.MapFS{
+		"templates/pages/app.tmpl.html": &fstest.MapFile{
+			Data: []byte(`<body>{{ inlineConfigScript .Common.CSPNonce .Page.config }}</body>`),
+		},
+	}
+
+	renderer, err := New(mockFS, mockAssets, "test", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	data := TemplateData{
+		Page:   map[string]interface{}{"config": map[string]string{"api": "/api</script>"}},
+		Common: CommonData{CSPNonce: "n0nce"},
+	}
+	if err := renderer.Render(&buf, "app", data); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := `<body><script nonce="n0nce">window.__CONFIG__={"api":"/api\u003c/script\u003e"};</script></body>`
+	if buf.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, buf.String())
+	}
+}
@@ -52,6 +52,12 @@ type CommonData struct {
 	// CSP nonce for security
 	CSPNonce string
 
+	// Public absolute URL of the site without a trailing slash, for absolute links
+	BaseURL string
+
+	// Absolute canonical URL of the current page (base URL + path, no query string)
+	CanonicalURL string
+
 	// Other common data can be added here
 	Version   string
 	BuildTime string
@@ -8,11 +8,13 @@ import (
 	"io/fs"
 	"log/slog"
 	"net/http"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
 
 	"guitar-specs/internal/assets"
+	mw "guitar-specs/internal/http/middleware"
 )
 
 // TemplateRenderer manages HTML template rendering with asset helper functions.
@@ -21,17 +23,38 @@ type TemplateRenderer struct {
 	templates map[string]*template.Template
 	funcs     template.FuncMap
 	env       string
+	delims    Delims
 	logger    *slog.Logger
 	mu        sync.RWMutex
 }
 
+// Delims are the action delimiters used when parsing templates. Empty fields
+// keep the html/template defaults "{{" and "}}".
+type Delims struct {
+	Left  string
+	Right string
+}
+
 // New creates a new template renderer instance.
 // It parses all templates from the filesystem and sets up helper functions.
 func New(templatesFS fs.FS, assetProvider assets.AssetProvider, env string, logger *slog.Logger) (Renderer, error) {
+	return NewWithDelims(templatesFS, assetProvider, env, Delims{}, logger)
+}
+
+// NewWithDelims creates a renderer whose templates use the given delimiters,
+// e.g. Delims{"[[", "]]"}, leaving "{{ }}" as literal text for client-side
+// frameworks such as Vue or Angular. The delimiters apply to every template.
+func NewWithDelims(templatesFS fs.FS, assetProvider assets.AssetProvider, env string, delims Delims, logger *slog.Logger) (Renderer, error) {
 	// Create template function map with asset helpers
 	funcs := template.FuncMap{
-		"asset": assetProvider.AssetURL,
-		"sri":   assetProvider.AssetSRI,
+		"asset":              assetProvider.AssetURL,
+		"assetq":             assetProvider.AssetQueryURL,
+		"sri":                assetProvider.AssetSRI,
+		"inlineConfigScript": InlineConfigScript,
+		"formatDate":         FormatDate,
+		"formatTime":         FormatTime,
+		"humanizeDuration":   HumanizeDuration,
+		"featureValue":       FeatureValue,
 	}
 
 	if logger != nil {
@@ -42,6 +65,7 @@ func New(templatesFS fs.FS, assetProvider assets.AssetProvider, env string, logg
 		templates: make(map[string]*template.Template),
 		funcs:     funcs,
 		env:       env,
+		delims:    delims,
 		logger:    logger,
 	}
 
@@ -73,7 +97,7 @@ func (r *TemplateRenderer) Render(w io.Writer, templateName string, data interfa
 	}
 
 	if !exists {
-		return fmt.Errorf("template '%s' not found", templateName)
+		return r.templateNotFound(templateName)
 	}
 
 	// Prepare template data with common functions
@@ -87,6 +111,11 @@ func (r *TemplateRenderer) Render(w io.Writer, templateName string, data interfa
 	return nil
 }
 
+// Logger returns the logger the renderer was created with, which may be nil.
+func (r *TemplateRenderer) Logger() *slog.Logger {
+	return r.logger
+}
+
 // RenderWithRequest renders a template with request context for CSP nonce.
 func (r *TemplateRenderer) RenderWithRequest(w io.Writer, templateName string, req *http.Request, data interface{}) error {
 	r.mu.RLock()
@@ -98,12 +127,17 @@ func (r *TemplateRenderer) RenderWithRequest(w io.Writer, templateName string, r
 	}
 
 	if !exists {
-		return fmt.Errorf("template '%s' not found", templateName)
+		return r.templateNotFound(templateName)
 	}
 
 	// Prepare template data with common functions and request context
 	templateData := r.prepareTemplateDataWithRequest(data, req)
 
+	// Without a nonce, inline scripts in a nonce-aware template are blocked by the CSP
+	if r.logger != nil && templateData.Common.CSPNonce == "" && usesCSPNonce(tmpl) {
+		r.logger.Warn("template uses a CSP nonce but none is set on the request", "name", templateName, "path", req.URL.Path)
+	}
+
 	// Execute template
 	if err := tmpl.Execute(w, templateData); err != nil {
 		return fmt.Errorf("failed to execute template '%s': %w", templateName, err)
@@ -181,6 +215,15 @@ func (r *TemplateRenderer) HasTemplate(name string) bool {
 	return exists
 }
 
+// templateNotFound logs and returns an error for a template that was never registered.
+// A handler naming an unknown template is a programming error, not a missing resource.
+func (r *TemplateRenderer) templateNotFound(templateName string) error {
+	if r.logger != nil {
+		r.logger.Error("template not registered", "name", templateName, "available_templates", r.getTemplateNames())
+	}
+	return fmt.Errorf("%w: '%s'", ErrTemplateNotFound, templateName)
+}
+
 // getTemplateNames returns a list of available template names for debugging.
 func (r *TemplateRenderer) getTemplateNames() []string {
 	r.mu.RLock()
@@ -225,15 +268,19 @@ func (r *TemplateRenderer) parseTemplates(templatesFS fs.FS) error {
 		shortName := strings.TrimSuffix(name, ".tmpl.html")
 
 		// Create new template with helper functions FIRST
-		tmpl := template.New(name).Funcs(r.funcs)
+		tmpl := template.New(name).Funcs(r.funcs).Delims(r.delims.Left, r.delims.Right)
 
-		// Parse layouts first
+		// Parse layouts first; a broken layout is reported with the page it was parsed for
 		for _, layout := range layouts {
-			tmpl = template.Must(tmpl.ParseFS(templatesFS, layout))
+			if _, err := tmpl.ParseFS(templatesFS, layout); err != nil {
+				return fmt.Errorf("failed to parse layout %s for page %s: %w", layout, page, err)
+			}
 		}
 
 		// Parse page content
-		tmpl = template.Must(tmpl.ParseFS(templatesFS, page))
+		if _, err := tmpl.ParseFS(templatesFS, page); err != nil {
+			return fmt.Errorf("failed to parse page template %s: %w", page, err)
+		}
 
 		// Store with both full name and short name
 		r.templates[name] = tmpl
@@ -244,15 +291,38 @@ func (r *TemplateRenderer) parseTemplates(templatesFS fs.FS) error {
 		}
 	}
 
+	if r.logger != nil {
+		r.logger.Info("parsed templates", "pages", len(pages), "layouts", len(layouts))
+	}
+
 	return nil
 }
 
+// normalizeData replaces nil data or a nil map with an empty map so that
+// templates referencing .Page.X render empty values instead of failing.
+func (r *TemplateRenderer) normalizeData(data interface{}) interface{} {
+	if m, ok := data.(map[string]interface{}); data != nil && (!ok || m != nil) {
+		return data
+	}
+
+	if r.logger != nil {
+		r.logger.Debug("template data is nil, using empty map")
+	}
+	return map[string]interface{}{}
+}
+
 // prepareTemplateData prepares template data with common functions and environment info.
-func (r *TemplateRenderer) prepareTemplateData(data interface{}) interface{} {
+func (r *TemplateRenderer) prepareTemplateData(data interface{}) TemplateData {
+	data = r.normalizeData(data)
+
 	// If data is already TemplateData, return as is
 	if td, ok := data.(TemplateData); ok {
 		return td
 	}
+	// A pointer is copied, so request data never modifies the caller's value
+	if td, ok := data.(*TemplateData); ok && td != nil {
+		return *td
+	}
 
 	// If data is map, wrap it in TemplateData structure
 	if m, ok := data.(map[string]interface{}); ok {
@@ -273,46 +343,43 @@ func (r *TemplateRenderer) prepareTemplateData(data interface{}) interface{} {
 	}
 }
 
-// prepareTemplateDataWithRequest prepares template data with request context for CSP nonce.
-func (r *TemplateRenderer) prepareTemplateDataWithRequest(data interface{}, req *http.Request) interface{} {
-	// If data is already TemplateData, return as is
-	if td, ok := data.(TemplateData); ok {
-		// Add CSP nonce if available
-		if nonce, ok := req.Context().Value("cspNonce").(string); ok {
-			td.Common.CSPNonce = nonce
-		}
-		return td
-	}
-
-	// If data is map, wrap it in TemplateData structure
-	if m, ok := data.(map[string]interface{}); ok {
-		common := CommonData{
-			Environment: r.env,
-		}
-
-		// Add CSP nonce if available
-		if nonce, ok := req.Context().Value("cspNonce").(string); ok {
-			common.CSPNonce = nonce
-		}
+// prepareTemplateDataWithRequest prepares template data with request context.
+// Request-scoped values such as the CSP nonce always land in Common, whatever
+// the shape of data, so templates can rely on .Common.CSPNonce.
+func (r *TemplateRenderer) prepareTemplateDataWithRequest(data interface{}, req *http.Request) TemplateData {
+	td := r.prepareTemplateData(data)
+	applyRequestData(&td.Common, req)
+	return td
+}
 
-		return TemplateData{
-			Page:   m,
-			Common: common,
-		}
+// applyRequestData copies request-scoped values (CSP nonce, public base URL, canonical URL) into common data.
+func applyRequestData(common *CommonData, req *http.Request) {
+	// Add CSP nonce if available
+	if nonce, ok := mw.CSPNonceFromContext(req.Context()); ok {
+		common.CSPNonce = nonce
 	}
 
-	// Create new TemplateData with common info
-	common := CommonData{
-		Environment: r.env,
+	if baseURL, ok := mw.PublicBaseURLFromContext(req.Context()); ok {
+		common.BaseURL = baseURL
+		common.CanonicalURL = baseURL + canonicalPath(req.URL.Path)
 	}
+}
 
-	// Add CSP nonce if available
-	if nonce, ok := req.Context().Value("cspNonce").(string); ok {
-		common.CSPNonce = nonce
+// usesCSPNonce reports whether any template in tmpl's set references .Common.CSPNonce.
+func usesCSPNonce(tmpl *template.Template) bool {
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil && t.Tree.Root != nil && strings.Contains(t.Tree.Root.String(), ".CSPNonce") {
+			return true
+		}
 	}
+	return false
+}
 
-	return TemplateData{
-		Page:   data,
-		Common: common,
+// canonicalPath cleans a request path for use in a canonical URL: duplicate and
+// trailing slashes are removed, so "/guitars/" and "/guitars" share one canonical.
+func canonicalPath(p string) string {
+	if p == "" {
+		return "/"
 	}
+	return path.Clean("/" + p)
 }
@@ -2,13 +2,17 @@ package render
 
 import (
 	"bytes"
+	"errors"
 	"html/template"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"testing/fstest"
 
 	"guitar-specs/internal/assets"
+	mw "guitar-specs/internal/http/middleware"
 )
 
 // MockAssetProvider implements assets.AssetProvider for testing
@@ -24,6 +28,10 @@ func (m *MockAssetProvider) AssetURL(path string) string {
 	return path
 }
 
+func (m *MockAssetProvider) AssetQueryURL(path string) string {
+	return path + "?v=test"
+}
+
 func (m *MockAssetProvider) AssetSRI(path string) string {
 	if sri, exists := m.assetSRIs[path]; exists {
 		return sri
@@ -119,6 +127,53 @@ func TestNewWithInvalidTemplates(t *testing.T) {
 	}
 }
 
+func TestNewWithMalformedTemplates(t *testing.T) {
+	mockAssets := &MockAssetProvider{
+		assetURLs: make(map[string]string),
+		assetSRIs: make(map[string]string),
+	}
+
+	tests := []struct {
+		name     string
+		fs       fstest.MapFS
+		wantFile string
+	}{
+		{
+			name: "malformed page",
+			fs: fstest.MapFS{
+				"templates/pages/broken.tmpl.html": &fstest.MapFile{Data: []byte(`{{ if .Page }}unclosed`)},
+			},
+			wantFile: "templates/pages/broken.tmpl.html",
+		},
+		{
+			name: "malformed layout",
+			fs: fstest.MapFS{
+				"templates/layouts/base.tmpl.html": &fstest.MapFile{Data: []byte(`{{ define "base" }}{{ .Missing`)},
+				"templates/pages/page.tmpl.html":   &fstest.MapFile{Data: []byte(`ok`)},
+			},
+			wantFile: "templates/layouts/base.tmpl.html",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if p := recover(); p != nil {
+					t.Fatalf("Expected an error, got a panic: %v", p)
+				}
+			}()
+
+			_, err := New(tt.fs, mockAssets, "development", nil)
+			if err == nil {
+				t.Fatal("Expected an error for a malformed template, got nil")
+			}
+			if !contains(err.Error(), tt.wantFile) {
+				t.Errorf("Expected the error to name %s, got: %v", tt.wantFile, err)
+			}
+		})
+	}
+}
+
 func TestTemplateRenderer_Render(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
 	
@@ -400,3 +455,385 @@ func containsSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestHTML(t *testing.T) {
+	var logOutput bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logOutput, &slog.HandlerOptions{}))
+
+	mockAssets := &MockAssetProvider{
+		assetURLs: make(map[string]string),
+		assetSRIs: make(map[string]string),
+	}
+
+	mockFS := fstest.MapFS{
+		"templates/pages/page.tmpl.html": &fstest.MapFile{
+			Data: []byte(`<h1>{{.Page.Title}}</h1>`),
+		},
+		"templates/pages/broken.tmpl.html": &fstest.MapFile{
+			Data: []byte(`<h1>{{.Page.Title.Missing}}</h1>`),
+		},
+	}
+
+	renderer, err := New(mockFS, mockAssets, "development", logger)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("renders known template", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+
+		HTML(w, req, renderer, "page", map[string]interface{}{"Title": "Hello"})
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+			t.Errorf("Expected HTML content type, got '%s'", ct)
+		}
+		if !contains(w.Body.String(), "<h1>Hello</h1>") {
+			t.Errorf("Expected rendered body, got: %s", w.Body.String())
+		}
+	})
+
+	t.Run("unknown template is a 500, not a 404", func(t *testing.T) {
+		logOutput.Reset()
+		req := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+
+		HTML(w, req, renderer, "missing", nil)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+		if contains(w.Header().Get("Content-Type"), "text/html") {
+			t.Errorf("Expected plain error response, got content type '%s'", w.Header().Get("Content-Type"))
+		}
+		if !contains(logOutput.String(), "template not registered") || !contains(logOutput.String(), "missing") {
+			t.Errorf("Expected missing template to be logged, got: %s", logOutput.String())
+		}
+	})
+
+	t.Run("execution failure is logged with template and request ID", func(t *testing.T) {
+		logOutput.Reset()
+		req := httptest.NewRequest("GET", "/", nil)
+		req = req.WithContext(mw.WithRequestID(req.Context(), "req-123"))
+		w := httptest.NewRecorder()
+
+		HTML(w, req, renderer, "broken", map[string]interface{}{"Title": "Hello"})
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+		for _, want := range []string{"template render failed", "template=broken", "request_id=req-123", "Missing"} {
+			if !contains(logOutput.String(), want) {
+				t.Errorf("Expected log to contain '%s', got: %s", want, logOutput.String())
+			}
+		}
+	})
+
+	t.Run("render failure falls back to the embedded error page for browsers", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+		w := httptest.NewRecorder()
+
+		HTML(w, req, renderer, "missing", nil)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+			t.Errorf("Expected HTML content type, got '%s'", ct)
+		}
+		if !contains(w.Body.String(), "Something went wrong") {
+			t.Errorf("Expected the embedded error page, got: %s", w.Body.String())
+		}
+	})
+
+	t.Run("renderer reports ErrTemplateNotFound", func(t *testing.T) {
+		err := renderer.Render(&bytes.Buffer{}, "missing", nil)
+		if !errors.Is(err, ErrTemplateNotFound) {
+			t.Errorf("Expected ErrTemplateNotFound, got %v", err)
+		}
+	})
+}
+
+func TestTemplateRenderer_NilData(t *testing.T) {
+	var logOutput bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logOutput, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	mockAssets := &MockAssetProvider{
+		assetURLs: make(map[string]string),
+		assetSRIs: make(map[string]string),
+	}
+
+	mockFS := fstest.MapFS{
+		"templates/pages/page.tmpl.html": &fstest.MapFile{
+			Data: []byte(`<h1>{{.Page.Title}}</h1>`),
+		},
+	}
+
+	renderer, err := New(mockFS, mockAssets, "development", logger)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var nilMap map[string]interface{}
+	cases := map[string]interface{}{
+		"nil data": nil,
+		"nil map":  nilMap,
+	}
+
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			logOutput.Reset()
+
+			var buf bytes.Buffer
+			if err := renderer.Render(&buf, "page", data); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if buf.String() != "<h1></h1>" {
+				t.Errorf("Expected empty title, got: %s", buf.String())
+			}
+
+			req := httptest.NewRequest("GET", "/", nil)
+			buf.Reset()
+			if err := renderer.RenderWithRequest(&buf, "page", req, data); err != nil {
+				t.Fatalf("Expected no error with request, got %v", err)
+			}
+			if buf.String() != "<h1></h1>" {
+				t.Errorf("Expected empty title with request, got: %s", buf.String())
+			}
+
+			if !contains(logOutput.String(), "template data is nil") {
+				t.Errorf("Expected nil data to be logged, got: %s", logOutput.String())
+			}
+		})
+	}
+}
+
+func TestTemplateRenderer_BaseURL(t *testing.T) {
+	mockAssets := &MockAssetProvider{
+		assetURLs: make(map[string]string),
+		assetSRIs: make(map[string]string),
+	}
+
+	mockFS := fstest.MapFS{
+		"templates/pages/page.tmpl.html": &fstest.MapFile{
+			Data: []byte(`<a href="{{.Common.BaseURL}}/guitars">Guitars</a>`),
+		},
+	}
+
+	renderer, err := New(mockFS, mockAssets, "development", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	handler := mw.PublicBaseURL("https://guitar-specs.example.com/")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		HTML(w, r, renderer, "page", nil)
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	expected := `<a href="https://guitar-specs.example.com/guitars">Guitars</a>`
+	if w.Body.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, w.Body.String())
+	}
+}
+
+func TestTemplateRenderer_CanonicalURL(t *testing.T) {
+	mockAssets := &MockAssetProvider{
+		assetURLs: make(map[string]string),
+		assetSRIs: make(map[string]string),
+	}
+
+	mockFS := fstest.MapFS{
+		"templates/pages/page.tmpl.html": &fstest.MapFile{
+			Data: []byte(`{{ with .Common.CanonicalURL }}<link rel="canonical" href="{{ . }}">{{ end }}`),
+		},
+	}
+
+	renderer, err := New(mockFS, mockAssets, "development", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		target   string
+		expected string
+	}{
+		{"/", `<link rel="canonical" href="https://guitar-specs.example.com/">`},
+		{"/guitars?feature=pickups&value=hh", `<link rel="canonical" href="https://guitar-specs.example.com/guitars">`},
+		{"/guitars/", `<link rel="canonical" href="https://guitar-specs.example.com/guitars">`},
+		{"/guitar/fender-stratocaster", `<link rel="canonical" href="https://guitar-specs.example.com/guitar/fender-stratocaster">`},
+		{"//guitar//fender-stratocaster/", `<link rel="canonical" href="https://guitar-specs.example.com/guitar/fender-stratocaster">`},
+	}
+
+	handler := mw.PublicBaseURL("https://guitar-specs.example.com")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		HTML(w, r, renderer, "page", nil)
+	}))
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Body.String() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("no base URL omits the tag", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/guitars", nil)
+		w := httptest.NewRecorder()
+		HTML(w, req, renderer, "page", nil)
+
+		if w.Body.String() != "" {
+			t.Errorf("Expected no canonical tag, got %s", w.Body.String())
+		}
+	})
+}
+
+func TestTemplateRenderer_CSPNonce(t *testing.T) {
+	mockAssets := &MockAssetProvider{
+		assetURLs: make(map[string]string),
+		assetSRIs: make(map[string]string),
+	}
+
+	mockFS := fstest.MapFS{
+		"templates/pages/script.tmpl.html": &fstest.MapFile{
+			Data: []byte(`<script nonce="{{ .Common.CSPNonce }}">{{ .Page.Name }}</script>`),
+		},
+		"templates/pages/plain.tmpl.html": &fstest.MapFile{
+			Data: []byte(`<p>plain</p>`),
+		},
+	}
+
+	var logOutput bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logOutput, nil))
+	renderer, err := New(mockFS, mockAssets, "development", logger)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type pageData struct{ Name string }
+	tests := []struct {
+		name string
+		data interface{}
+	}{
+		{"map data", map[string]interface{}{"Name": "strat"}},
+		{"struct data", pageData{Name: "strat"}},
+		{"struct pointer data", &pageData{Name: "strat"}},
+		{"template data", TemplateData{Page: pageData{Name: "strat"}}},
+		{"template data pointer", &TemplateData{Page: pageData{Name: "strat"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req = req.WithContext(mw.WithCSPNonce(req.Context(), "abc123"))
+			w := httptest.NewRecorder()
+			HTML(w, req, renderer, "script", tt.data)
+
+			expected := `<script nonce="abc123">"strat"</script>`
+			if w.Body.String() != expected {
+				t.Errorf("Expected %s, got %s", expected, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("pointer data is not modified", func(t *testing.T) {
+		td := &TemplateData{Page: pageData{Name: "strat"}}
+		req := httptest.NewRequest("GET", "/", nil)
+		req = req.WithContext(mw.WithCSPNonce(req.Context(), "abc123"))
+		HTML(httptest.NewRecorder(), req, renderer, "script", td)
+
+		if td.Common.CSPNonce != "" {
+			t.Errorf("Expected the caller's data to be left alone, got nonce %s", td.Common.CSPNonce)
+		}
+	})
+
+	t.Run("missing nonce is logged for nonce-aware templates", func(t *testing.T) {
+		logOutput.Reset()
+		HTML(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), renderer, "script", nil)
+
+		if !contains(logOutput.String(), "none is set on the request") {
+			t.Errorf("Expected a missing nonce warning, got: %s", logOutput.String())
+		}
+	})
+
+	t.Run("templates without a nonce are not flagged", func(t *testing.T) {
+		logOutput.Reset()
+		HTML(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), renderer, "plain", nil)
+
+		if contains(logOutput.String(), "none is set on the request") {
+			t.Errorf("Expected no warning, got: %s", logOutput.String())
+		}
+	})
+}
+
+func TestNewWithDelims(t *testing.T) {
+	mockAssets := &MockAssetProvider{
+		assetURLs: make(map[string]string),
+		assetSRIs: make(map[string]string),
+	}
+
+	mockFS := fstest.MapFS{
+		"templates/layouts/base.tmpl.html": &fstest.MapFile{
+			Data: []byte(`[[define "base"]]<main>[[template "content" .]]</main>[[end]]`),
+		},
+		"templates/pages/app.tmpl.html": &fstest.MapFile{
+			Data: []byte(`[[define "content"]]<h1>[[.Page.Title]]</h1><p>{{ message }}</p>[[end]][[template "base" .]]`),
+		},
+	}
+
+	renderer, err := NewWithDelims(mockFS, mockAssets, "development", Delims{Left: "[[", Right: "]]"}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, "app", map[string]interface{}{"Title": "Vue page"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := `<main><h1>Vue page</h1><p>{{ message }}</p></main>`
+	if buf.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, buf.String())
+	}
+}
+
+func TestAssetQueryFunc(t *testing.T) {
+	assetProvider, err := assets.New(fstest.MapFS{
+		"static/dist/js/manifest.json": &fstest.MapFile{
+			Data: []byte(`{"files": {"static/css/main.css": {"path": "/static/css/main.abc123.css", "hash": "abc123"}}}`),
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mockFS := fstest.MapFS{
+		"templates/pages/app.tmpl.html": &fstest.MapFile{
+			Data: []byte(`{{ assetq "/static/css/main.css" }} {{ asset "/static/css/main.css" }}`),
+		},
+	}
+	renderer, err := New(mockFS, assetProvider, "test", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	out, err := renderer.RenderString("app", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := `/static/css/main.css?v=abc123 /static/css/main.abc123.css`
+	if out != expected {
+		t.Errorf("Expected %s, got %s", expected, out)
+	}
+}
@@ -0,0 +1,63 @@
+package render
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// inlineScriptPattern matches a script element, capturing its attributes and body.
+var inlineScriptPattern = regexp.MustCompile(`(?is)<script\b([^>]*)>(.*?)</script>`)
+
+// srcAttrPattern detects an external script (one with a src attribute).
+var srcAttrPattern = regexp.MustCompile(`(?i)\bsrc\s*=`)
+
+// InlineScriptHashes scans the page and layout templates for inline <script>
+// blocks and returns their CSP hash sources ('sha256-...'), sorted and deduplicated.
+// External scripts and empty blocks are ignored. Blocks containing template actions
+// are skipped too: their rendered content varies, so a static hash would never match.
+func InlineScriptHashes(templatesFS fs.FS) ([]string, error) {
+	var files []string
+	for _, pattern := range []string{"templates/layouts/*.tmpl.html", "templates/pages/*.tmpl.html"} {
+		matches, err := fs.Glob(templatesFS, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover templates: %w", err)
+		}
+		files = append(files, matches...)
+	}
+
+	seen := make(map[string]bool)
+	hashes := make([]string, 0)
+	for _, file := range files {
+		content, err := fs.ReadFile(templatesFS, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %s: %w", file, err)
+		}
+
+		for _, m := range inlineScriptPattern.FindAllSubmatch(content, -1) {
+			attrs, body := m[1], m[2]
+			if srcAttrPattern.Match(attrs) || strings.TrimSpace(string(body)) == "" || strings.Contains(string(body), "{{") {
+				continue
+			}
+			hash := scriptHashSource(body)
+			if !seen[hash] {
+				seen[hash] = true
+				hashes = append(hashes, hash)
+			}
+		}
+	}
+
+	sort.Strings(hashes)
+	return hashes, nil
+}
+
+// scriptHashSource returns the CSP hash source for an inline script body.
+// The hash covers the exact bytes between the tags, as browsers compute it.
+func scriptHashSource(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'"
+}
@@ -0,0 +1,39 @@
+package render
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestInlineScriptHashes(t *testing.T) {
+	mockFS := fstest.MapFS{
+		"templates/layouts/base.tmpl.html": &fstest.MapFile{
+			Data: []byte(`<html><head>
+<script src="/static/js/app.js"></script>
+<script>alert('Hello, world.');</script>
+</head><body>{{template "content" .}}</body></html>`),
+		},
+		"templates/pages/home.tmpl.html": &fstest.MapFile{
+			Data: []byte(`{{define "content"}}
+<script type="module">alert('Hello, world.');</script>
+<script>var title = "{{.Page.Title}}";</script>
+<script></script>
+{{end}}`),
+		},
+	}
+
+	hashes, err := InlineScriptHashes(mockFS)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Reference value from the CSP Level 3 specification's hash-source example
+	expected := "'sha256-qznLcsROx4GACP2dm0UCKCzCG+HiZ1guq6ZZDob/Tng='"
+
+	if len(hashes) != 1 {
+		t.Fatalf("Expected 1 hash, got %d: %v", len(hashes), hashes)
+	}
+	if hashes[0] != expected {
+		t.Errorf("Expected %s, got %s", expected, hashes[0])
+	}
+}
@@ -0,0 +1,47 @@
+package render
+
+import (
+	"html/template"
+	"io"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// WarmUp executes every registered template once against empty data so lazy
+// initialisation (escaper analysis, func lookups) happens at startup rather than
+// on the first visitor's request. Templates that need real data may fail; those
+// errors are logged and otherwise ignored. It returns how many templates were
+// executed and how many of them failed.
+func WarmUp(renderer Renderer, logger *slog.Logger) (warmed, failed int) {
+	templates := renderer.GetTemplates()
+
+	// Templates are registered under both full and short names; run each once
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	seen := make(map[*template.Template]bool, len(templates))
+
+	start := time.Now()
+	for _, name := range names {
+		if seen[templates[name]] {
+			continue
+		}
+		seen[templates[name]] = true
+
+		warmed++
+		if err := renderer.Render(io.Discard, name, nil); err != nil {
+			failed++
+			if logger != nil {
+				logger.Debug("template warm-up failed", "name", name, "error", err)
+			}
+		}
+	}
+
+	if logger != nil {
+		logger.Info("template warm-up completed", "templates", warmed, "failed", failed, "duration_ms", time.Since(start).Milliseconds())
+	}
+	return warmed, failed
+}
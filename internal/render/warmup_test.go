@@ -0,0 +1,75 @@
+package render
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"guitar-specs/web"
+)
+
+func TestWarmUp(t *testing.T) {
+	mockAssets := &MockAssetProvider{
+		assetURLs: make(map[string]string),
+		assetSRIs: make(map[string]string),
+	}
+
+	mockFS := fstest.MapFS{
+		"templates/pages/plain.tmpl.html": &fstest.MapFile{
+			Data: []byte(`<h1>{{.Page.Title}}</h1>`),
+		},
+		"templates/pages/strict.tmpl.html": &fstest.MapFile{
+			Data: []byte(`<h1>{{index .Page.Guitars 0}}</h1>`),
+		},
+	}
+
+	renderer, err := New(mockFS, mockAssets, "development", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var logOutput bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logOutput, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	warmed, failed := WarmUp(renderer, logger)
+
+	// Each template is registered under two names but executed once
+	if warmed != 2 {
+		t.Errorf("Expected 2 templates warmed, got %d", warmed)
+	}
+	// Missing nested data fails on empty input, which must not be fatal
+	if failed != 1 {
+		t.Errorf("Expected 1 failed template, got %d", failed)
+	}
+	if !strings.Contains(logOutput.String(), "template warm-up failed") || !strings.Contains(logOutput.String(), "name=strict") {
+		t.Errorf("Expected failure to be logged, got: %s", logOutput.String())
+	}
+	if !strings.Contains(logOutput.String(), "template warm-up completed") {
+		t.Errorf("Expected completion log, got: %s", logOutput.String())
+	}
+}
+
+func BenchmarkRender(b *testing.B) {
+	mockAssets := &MockAssetProvider{
+		assetURLs: make(map[string]string),
+		assetSRIs: make(map[string]string),
+	}
+
+	renderer, err := New(web.TemplatesFS, mockAssets, "production", nil)
+	if err != nil {
+		b.Fatalf("Expected no error, got %v", err)
+	}
+	data := map[string]any{"Title": "Home"}
+
+	var buf bytes.Buffer
+	b.ReportAllocs()
+	for b.Loop() {
+		buf.Reset()
+		if err := renderer.Render(&buf, "home", data); err != nil {
+			b.Fatalf("Render failed: %v", err)
+		}
+	}
+	b.SetBytes(int64(buf.Len()))
+}
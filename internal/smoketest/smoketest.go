@@ -0,0 +1,147 @@
+// Package smoketest runs a quick post-deploy check of the main routes, either
+// against a running instance or in-process against the router from app.New.
+package smoketest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"guitar-specs/internal/app"
+	"guitar-specs/internal/assets"
+	"guitar-specs/internal/config"
+	"guitar-specs/internal/db"
+	"guitar-specs/internal/render"
+	"guitar-specs/web"
+)
+
+// Check is a single route expected to answer with WantStatus.
+type Check struct {
+	Path       string
+	WantStatus int
+	NeedsDB    bool // Skipped when running without a database
+}
+
+// DefaultChecks covers liveness, a static page and a database-backed page.
+var DefaultChecks = []Check{
+	{Path: "/healthz", WantStatus: http.StatusOK},
+	{Path: "/", WantStatus: http.StatusOK},
+	{Path: "/guitars", WantStatus: http.StatusOK, NeedsDB: true},
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Check
+	Status   int
+	Duration time.Duration
+	Err      error
+	Skipped  bool
+}
+
+// OK reports whether the check passed or was skipped.
+func (r Result) OK() bool {
+	return r.Skipped || (r.Err == nil && r.Status == r.WantStatus)
+}
+
+// String renders the result as one report line.
+func (r Result) String() string {
+	switch {
+	case r.Skipped:
+		return fmt.Sprintf("SKIP %s (no database)", r.Path)
+	case r.Err != nil:
+		return fmt.Sprintf("FAIL %s error: %v", r.Path, r.Err)
+	case !r.OK():
+		return fmt.Sprintf("FAIL %s status %d, want %d (%v)", r.Path, r.Status, r.WantStatus, r.Duration.Round(time.Millisecond))
+	default:
+		return fmt.Sprintf("PASS %s status %d (%v)", r.Path, r.Status, r.Duration.Round(time.Millisecond))
+	}
+}
+
+// Run requests each check against baseURL and returns the results in order.
+// Redirects are not followed, so a check sees the status the route returns.
+// With withDB false, checks that need the database are reported as skipped.
+func Run(ctx context.Context, client *http.Client, baseURL string, checks []Check, withDB bool) []Result {
+	noRedirects := *client
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	results := make([]Result, 0, len(checks))
+	for _, c := range checks {
+		res := Result{Check: c}
+		if c.NeedsDB && !withDB {
+			res.Skipped = true
+			results = append(results, res)
+			continue
+		}
+
+		start := time.Now()
+		res.Status, res.Err = get(ctx, &noRedirects, baseURL+c.Path)
+		res.Duration = time.Since(start)
+		results = append(results, res)
+	}
+	return results
+}
+
+// Passed reports whether every result is OK.
+func Passed(results []Result) bool {
+	for _, r := range results {
+		if !r.OK() {
+			return false
+		}
+	}
+	return true
+}
+
+func get(ctx context.Context, client *http.Client, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// InProcessHandler builds the application router with default configuration,
+// the embedded templates and content-hashed assets, and no database. It exercises
+// the same wiring as cmd/web without needing PostgreSQL or TLS certificates.
+func InProcessHandler(logger *slog.Logger) (http.Handler, error) {
+	assetProvider, err := assets.NewHashAssetProvider(web.StaticFS, nil, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build asset versions: %w", err)
+	}
+	renderer, err := render.New(web.TemplatesFS, assetProvider, "smoketest", logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create renderer: %w", err)
+	}
+	a := app.New(&config.AppConfig{}, logger, offlineDatabase{}, renderer, assetProvider)
+	return a.Router, nil
+}
+
+// offlineDatabase implements db.DatabaseProvider without a pool, so
+// database-backed pages fail fast rather than trying to connect.
+type offlineDatabase struct{}
+
+func (offlineDatabase) Connect(ctx context.Context) error { return nil }
+func (offlineDatabase) Close()                            {}
+func (offlineDatabase) GetPool() *pgxpool.Pool            { return nil }
+func (offlineDatabase) Ping(ctx context.Context) error    { return nil }
+func (offlineDatabase) IsConnected() bool                 { return false }
+
+func (offlineDatabase) GetConnectionInfo() db.ConnectionInfo {
+	return db.ConnectionInfo{}
+}
+
+var _ db.DatabaseProvider = offlineDatabase{}
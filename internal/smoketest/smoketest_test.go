@@ -0,0 +1,86 @@
+package smoketest
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRun_InProcess(t *testing.T) {
+	handler, err := InProcessHandler(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("Failed to build in-process handler: %v", err)
+	}
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	results := Run(context.Background(), srv.Client(), srv.URL, DefaultChecks, false)
+
+	if len(results) != len(DefaultChecks) {
+		t.Fatalf("Expected %d results, got %d", len(DefaultChecks), len(results))
+	}
+	for _, r := range results {
+		if !r.OK() {
+			t.Errorf("Expected check to pass, got %s", r)
+		}
+		if r.NeedsDB && !r.Skipped {
+			t.Errorf("Expected %s to be skipped without a database", r.Path)
+		}
+	}
+	if !Passed(results) {
+		t.Error("Expected the smoke test to pass")
+	}
+}
+
+func TestRun_Failures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthz":
+			w.WriteHeader(http.StatusOK)
+		case "/moved":
+			http.Redirect(w, r, "/healthz", http.StatusMovedPermanently)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	checks := []Check{
+		{Path: "/healthz", WantStatus: http.StatusOK},
+		{Path: "/guitars", WantStatus: http.StatusOK, NeedsDB: true},
+		{Path: "/moved", WantStatus: http.StatusOK},
+	}
+	results := Run(context.Background(), srv.Client(), srv.URL+"/", checks, true)
+
+	if !results[0].OK() {
+		t.Errorf("Expected /healthz to pass, got %s", results[0])
+	}
+	if results[1].OK() || results[1].Status != http.StatusInternalServerError {
+		t.Errorf("Expected /guitars to fail with 500, got %s", results[1])
+	}
+	if results[2].Status != http.StatusMovedPermanently {
+		t.Errorf("Expected redirects not to be followed, got %s", results[2])
+	}
+	if !strings.HasPrefix(results[1].String(), "FAIL /guitars status 500, want 200") {
+		t.Errorf("Unexpected report line: %s", results[1])
+	}
+	if Passed(results) {
+		t.Error("Expected the smoke test to fail")
+	}
+}
+
+func TestRun_Unreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	results := Run(context.Background(), http.DefaultClient, url, DefaultChecks[:1], true)
+
+	if results[0].Err == nil || results[0].OK() {
+		t.Errorf("Expected a connection error, got %s", results[0])
+	}
+}
@@ -0,0 +1,75 @@
+// Package timing accumulates per-request phase durations for the Server-Timing header.
+package timing
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recorder accumulates named phase durations for one request.
+// Repeated names are summed, so several queries add up to a single "db" entry.
+type Recorder struct {
+	mu      sync.Mutex
+	names   []string
+	entries map[string]time.Duration
+}
+
+// recorderKey is an unexported type to avoid context key collisions.
+type recorderKey struct{}
+
+// NewContext returns a context carrying a fresh Recorder.
+func NewContext(ctx context.Context) (context.Context, *Recorder) {
+	rec := &Recorder{entries: make(map[string]time.Duration)}
+	return context.WithValue(ctx, recorderKey{}, rec), rec
+}
+
+// ServerTiming records dur under name in the context's Recorder.
+// It is a no-op when the context has none, i.e. when Server-Timing is disabled.
+func ServerTiming(ctx context.Context, name string, dur time.Duration) {
+	rec, ok := ctx.Value(recorderKey{}).(*Recorder)
+	if !ok {
+		return
+	}
+	rec.Add(name, dur)
+}
+
+// Track starts timing a phase and returns a func that records it, for use with defer:
+//
+//	defer timing.Track(ctx, "db")()
+func Track(ctx context.Context, name string) func() {
+	start := time.Now()
+	return func() { ServerTiming(ctx, name, time.Since(start)) }
+}
+
+// Add records dur under name.
+func (r *Recorder) Add(name string, dur time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, seen := r.entries[name]; !seen {
+		r.names = append(r.names, name)
+	}
+	r.entries[name] += dur
+}
+
+// Header formats the recorded phases as a Server-Timing header value in
+// recording order, e.g. "db;dur=12.3, render;dur=3.1". Durations are milliseconds.
+func (r *Recorder) Header() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	parts := make([]string, 0, len(r.names))
+	for _, name := range r.names {
+		parts = append(parts, FormatEntry(name, r.entries[name]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FormatEntry formats a single Server-Timing metric with its duration in milliseconds.
+func FormatEntry(name string, dur time.Duration) string {
+	ms := float64(dur) / float64(time.Millisecond)
+	return name + ";dur=" + strconv.FormatFloat(ms, 'f', 1, 64)
+}
@@ -0,0 +1,26 @@
+package timing
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRecorder_Header(t *testing.T) {
+	ctx, rec := NewContext(context.Background())
+
+	ServerTiming(ctx, "db", 10*time.Millisecond)
+	ServerTiming(ctx, "render", 3*time.Millisecond+100*time.Microsecond)
+	ServerTiming(ctx, "db", 2500*time.Microsecond)
+
+	expected := "db;dur=12.5, render;dur=3.1"
+	if got := rec.Header(); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestServerTiming_WithoutRecorder(t *testing.T) {
+	// Must not panic when Server-Timing is disabled
+	ServerTiming(context.Background(), "db", time.Millisecond)
+	Track(context.Background(), "db")()
+}